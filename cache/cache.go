@@ -0,0 +1,23 @@
+// Package cache keeps recently resolved slugs close at hand, so that
+// redirects don't have to go to the store every time.
+package cache
+
+import (
+	"time"
+	"url-shortener/models"
+)
+
+// Entry is what a slug resolved to. A missing entry remembers that the slug
+// didn't resolve to anything, so that repeated 404s are cheap as well.
+type Entry struct {
+	ShortUrl models.ShortUrl
+	Missing  bool
+}
+
+type SlugCache interface {
+	// Get returns the entry for slug, and whether there is one.
+	Get(slug string) (Entry, bool, error)
+	// Set caches entry for slug until ttl has passed.
+	Set(slug string, entry Entry, ttl time.Duration) error
+	Delete(slugs ...string) error
+}
@@ -0,0 +1,147 @@
+package cache
+
+import (
+	"errors"
+	"log"
+	"time"
+	"url-shortener/models"
+	"url-shortener/repositories"
+)
+
+// invalidatingStore removes the slugs of short URLs that are created,
+// changed, deleted, or restored from a SlugCache. Slugs changed in a
+// transaction are only removed once it commits, since until then the cache
+// could be filled again with what the transaction is replacing.
+type invalidatingStore struct {
+	repositories.Store
+	cache SlugCache
+	// pending collects the slugs to remove when the transaction commits, or
+	// is nil outside of one.
+	pending *[]string
+}
+
+// NewInvalidatingStore wraps store so that writes through it keep cache up
+// to date. Every write to the short URLs that cache holds should go through
+// a store wrapped like this.
+func NewInvalidatingStore(store repositories.Store, cache SlugCache) repositories.Store {
+	return &invalidatingStore{Store: store, cache: cache}
+}
+
+func (s *invalidatingStore) ShortUrls() repositories.ShortUrlRepository {
+	return &invalidatingShortUrlRepository{
+		ShortUrlRepository: s.Store.ShortUrls(),
+		store:              s,
+	}
+}
+
+func (s *invalidatingStore) Transaction(fn func(tx repositories.Store) error) error {
+	var slugs []string
+
+	err := s.Store.Transaction(func(tx repositories.Store) error {
+		return fn(&invalidatingStore{Store: tx, cache: s.cache, pending: &slugs})
+	})
+
+	if err == nil {
+		s.invalidate(slugs...)
+	}
+
+	return err
+}
+
+// invalidate doesn't fail the write that it follows, which has already been
+// made, if the cache can't be reached. Entries expire after their TTL
+// regardless.
+func (s *invalidatingStore) invalidate(slugs ...string) {
+	if s.pending != nil {
+		*s.pending = append(*s.pending, slugs...)
+		return
+	}
+
+	if err := s.cache.Delete(slugs...); err != nil {
+		log.Printf("encountered error invalidating cached slugs %v: %v", slugs, err)
+	}
+}
+
+type invalidatingShortUrlRepository struct {
+	repositories.ShortUrlRepository
+	store *invalidatingStore
+}
+
+// Create invalidates a cached 404 for the slug.
+func (r *invalidatingShortUrlRepository) Create(shortUrl *models.ShortUrl) error {
+	err := r.ShortUrlRepository.Create(shortUrl)
+
+	if err == nil {
+		r.store.invalidate(shortUrl.Slug)
+	}
+
+	return err
+}
+
+// Update invalidates both the old slug and the new one.
+func (r *invalidatingShortUrlRepository) Update(shortUrl *models.ShortUrl) error {
+	previous, err := r.ShortUrlRepository.FindById(shortUrl.Id)
+
+	if err != nil && !errors.Is(err, repositories.ErrNotFound) {
+		return err
+	}
+
+	err = r.ShortUrlRepository.Update(shortUrl)
+
+	if err == nil {
+		r.store.invalidate(previous.Slug, shortUrl.Slug)
+	}
+
+	return err
+}
+
+func (r *invalidatingShortUrlRepository) Delete(shortUrl models.ShortUrl) error {
+	err := r.ShortUrlRepository.Delete(shortUrl)
+
+	if err == nil {
+		r.store.invalidate(shortUrl.Slug)
+	}
+
+	return err
+}
+
+// Restore invalidates a cached 404 for the slug.
+func (r *invalidatingShortUrlRepository) Restore(shortUrl *models.ShortUrl) error {
+	err := r.ShortUrlRepository.Restore(shortUrl)
+
+	if err == nil {
+		r.store.invalidate(shortUrl.Slug)
+	}
+
+	return err
+}
+
+func (r *invalidatingShortUrlRepository) Purge(cutoff time.Time) ([]models.ShortUrl, error) {
+	shortUrls, err := r.ShortUrlRepository.Purge(cutoff)
+
+	if err == nil {
+		r.store.invalidate(slugsOf(shortUrls)...)
+	}
+
+	return shortUrls, err
+}
+
+func (r *invalidatingShortUrlRepository) DeleteExpired(now time.Time) ([]models.ShortUrl, error) {
+	shortUrls, err := r.ShortUrlRepository.DeleteExpired(now)
+
+	if err == nil {
+		r.store.invalidate(slugsOf(shortUrls)...)
+	}
+
+	return shortUrls, err
+}
+
+func slugsOf(shortUrls []models.ShortUrl) []string {
+	slugs := make([]string, len(shortUrls))
+
+	for i, shortUrl := range shortUrls {
+		slugs[i] = shortUrl.Slug
+	}
+
+	return slugs
+}
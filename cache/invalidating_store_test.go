@@ -0,0 +1,108 @@
+package cache
+
+import (
+	"errors"
+	"testing"
+	"time"
+	"url-shortener/models"
+	"url-shortener/repositories"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/guregu/null.v4"
+)
+
+func newShortUrl(slug string) *models.ShortUrl {
+	shortUrl := &models.ShortUrl{}
+	shortUrl.Slug = slug
+	shortUrl.LongUrl = "https://" + slug + ".example.com"
+
+	return shortUrl
+}
+
+func isCached(lru *Lru, slug string) bool {
+	_, ok, _ := lru.Get(slug)
+
+	return ok
+}
+
+func TestInvalidatingStoreInvalidatesWrites(t *testing.T) {
+	lru := NewLru(10)
+	store := NewInvalidatingStore(repositories.NewMemoryStore(), lru)
+
+	// A cached 404 goes away once the slug is created.
+	assert.Nil(t, lru.Set("a", Entry{Missing: true}, time.Hour))
+
+	shortUrl := newShortUrl("a")
+	assert.Nil(t, store.ShortUrls().Create(shortUrl))
+	assert.False(t, isCached(lru, "a"))
+
+	// Changing the slug invalidates both the old and the new one.
+	assert.Nil(t, lru.Set("a", Entry{ShortUrl: *shortUrl}, time.Hour))
+	assert.Nil(t, lru.Set("b", Entry{Missing: true}, time.Hour))
+
+	shortUrl.Slug = "b"
+	assert.Nil(t, store.ShortUrls().Update(shortUrl))
+	assert.False(t, isCached(lru, "a"))
+	assert.False(t, isCached(lru, "b"))
+
+	assert.Nil(t, lru.Set("b", Entry{ShortUrl: *shortUrl}, time.Hour))
+	assert.Nil(t, store.ShortUrls().Delete(*shortUrl))
+	assert.False(t, isCached(lru, "b"))
+
+	// So does restoring it from the trash.
+	assert.Nil(t, lru.Set("b", Entry{Missing: true}, time.Hour))
+	assert.Nil(t, store.ShortUrls().Restore(shortUrl))
+	assert.False(t, isCached(lru, "b"))
+}
+
+func TestInvalidatingStoreInvalidatesExpiredShortUrls(t *testing.T) {
+	lru := NewLru(10)
+	store := NewInvalidatingStore(repositories.NewMemoryStore(), lru)
+	now := time.Date(2022, 5, 10, 12, 0, 0, 0, time.UTC)
+
+	expired := newShortUrl("expired")
+	expired.ExpiresOn = null.TimeFrom(now)
+
+	assert.Nil(t, store.ShortUrls().Create(expired))
+	assert.Nil(t, store.ShortUrls().Create(newShortUrl("current")))
+
+	assert.Nil(t, lru.Set("expired", Entry{ShortUrl: *expired}, time.Hour))
+	assert.Nil(t, lru.Set("current", Entry{Missing: true}, time.Hour))
+
+	deleted, err := store.ShortUrls().DeleteExpired(now)
+
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"expired"}, slugsOf(deleted))
+	assert.False(t, isCached(lru, "expired"))
+	assert.True(t, isCached(lru, "current"))
+}
+
+func TestInvalidatingStoreInvalidatesTransactionsOnCommit(t *testing.T) {
+	lru := NewLru(10)
+	store := NewInvalidatingStore(repositories.NewMemoryStore(), lru)
+	rollback := errors.New("rollback")
+
+	assert.Nil(t, lru.Set("a", Entry{Missing: true}, time.Hour))
+	assert.Nil(t, lru.Set("b", Entry{Missing: true}, time.Hour))
+
+	err := store.Transaction(func(tx repositories.Store) error {
+		assert.Nil(t, tx.ShortUrls().Create(newShortUrl("a")))
+
+		// Nothing is invalidated until the transaction commits.
+		assert.True(t, isCached(lru, "a"))
+
+		err := tx.Transaction(func(tx repositories.Store) error {
+			assert.Nil(t, tx.ShortUrls().Create(newShortUrl("b")))
+
+			return rollback
+		})
+
+		assert.ErrorIs(t, err, rollback)
+
+		return nil
+	})
+
+	assert.Nil(t, err)
+	assert.False(t, isCached(lru, "a"))
+	assert.True(t, isCached(lru, "b"))
+}
@@ -0,0 +1,95 @@
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// Lru caches slugs in memory, forgetting the least recently used ones once
+// it holds capacity of them. Every instance of the application has its own,
+// so an entry can be stale on other instances until its TTL passes.
+type Lru struct {
+	capacity int
+	now      func() time.Time
+
+	lock    sync.Mutex
+	order   *list.List
+	entries map[string]*list.Element
+}
+
+type lruItem struct {
+	slug      string
+	entry     Entry
+	expiresAt time.Time
+}
+
+func NewLru(capacity int) *Lru {
+	return &Lru{
+		capacity: capacity,
+		now:      time.Now,
+		order:    list.New(),
+		entries:  map[string]*list.Element{},
+	}
+}
+
+func (c *Lru) Get(slug string) (Entry, bool, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	element, ok := c.entries[slug]
+
+	if !ok {
+		return Entry{}, false, nil
+	}
+
+	item := element.Value.(*lruItem)
+
+	if !c.now().Before(item.expiresAt) {
+		c.remove(element)
+		return Entry{}, false, nil
+	}
+
+	c.order.MoveToFront(element)
+
+	return item.entry, true, nil
+}
+
+func (c *Lru) Set(slug string, entry Entry, ttl time.Duration) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	item := &lruItem{slug: slug, entry: entry, expiresAt: c.now().Add(ttl)}
+
+	if element, ok := c.entries[slug]; ok {
+		element.Value = item
+		c.order.MoveToFront(element)
+		return nil
+	}
+
+	c.entries[slug] = c.order.PushFront(item)
+
+	for c.order.Len() > c.capacity {
+		c.remove(c.order.Back())
+	}
+
+	return nil
+}
+
+func (c *Lru) Delete(slugs ...string) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	for _, slug := range slugs {
+		if element, ok := c.entries[slug]; ok {
+			c.remove(element)
+		}
+	}
+
+	return nil
+}
+
+func (c *Lru) remove(element *list.Element) {
+	c.order.Remove(element)
+	delete(c.entries, element.Value.(*lruItem).slug)
+}
@@ -0,0 +1,63 @@
+package cache
+
+import (
+	"testing"
+	"time"
+	"url-shortener/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func entryFor(slug string) Entry {
+	shortUrl := models.ShortUrl{Id: 1}
+	shortUrl.Slug = slug
+	shortUrl.LongUrl = "https://" + slug + ".example.com"
+
+	return Entry{ShortUrl: shortUrl}
+}
+
+func TestLruEvictsLeastRecentlyUsed(t *testing.T) {
+	lru := NewLru(2)
+
+	assert.Nil(t, lru.Set("a", entryFor("a"), time.Hour))
+	assert.Nil(t, lru.Set("b", entryFor("b"), time.Hour))
+
+	// Reading a makes b the least recently used.
+	_, ok, _ := lru.Get("a")
+	assert.True(t, ok)
+
+	assert.Nil(t, lru.Set("c", entryFor("c"), time.Hour))
+
+	_, ok, _ = lru.Get("b")
+	assert.False(t, ok)
+
+	entry, ok, err := lru.Get("a")
+
+	assert.Nil(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, entryFor("a"), entry)
+}
+
+func TestLruExpiresEntries(t *testing.T) {
+	now := time.Date(2022, 5, 10, 12, 0, 0, 0, time.UTC)
+
+	lru := NewLru(10)
+	lru.now = func() time.Time { return now }
+
+	assert.Nil(t, lru.Set("a", entryFor("a"), time.Minute))
+	assert.Nil(t, lru.Set("missing", Entry{Missing: true}, 2*time.Minute))
+
+	now = now.Add(time.Minute)
+
+	_, ok, _ := lru.Get("a")
+	assert.False(t, ok)
+
+	entry, ok, _ := lru.Get("missing")
+	assert.True(t, ok)
+	assert.True(t, entry.Missing)
+
+	assert.Nil(t, lru.Delete("missing", "unknown"))
+
+	_, ok, _ = lru.Get("missing")
+	assert.False(t, ok)
+}
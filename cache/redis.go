@@ -0,0 +1,69 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"errors"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// Redis caches slugs in Redis, which every instance of the application
+// shares, so invalidating a slug on one invalidates it on all of them.
+// Entries are gob encoded, since the JSON encoding of a short URL leaves out
+// its id.
+type Redis struct {
+	client *redis.Client
+	// prefix namespaces the keys, in case the Redis database is shared.
+	prefix string
+}
+
+func NewRedis(client *redis.Client, prefix string) *Redis {
+	return &Redis{client: client, prefix: prefix}
+}
+
+func (c *Redis) Get(slug string) (Entry, bool, error) {
+	value, err := c.client.Get(context.Background(), c.prefix+slug).Bytes()
+
+	if errors.Is(err, redis.Nil) {
+		return Entry{}, false, nil
+	}
+
+	if err != nil {
+		return Entry{}, false, err
+	}
+
+	var entry Entry
+
+	if err := gob.NewDecoder(bytes.NewReader(value)).Decode(&entry); err != nil {
+		return Entry{}, false, err
+	}
+
+	return entry, true, nil
+}
+
+func (c *Redis) Set(slug string, entry Entry, ttl time.Duration) error {
+	var value bytes.Buffer
+
+	if err := gob.NewEncoder(&value).Encode(entry); err != nil {
+		return err
+	}
+
+	return c.client.Set(context.Background(), c.prefix+slug, value.Bytes(), ttl).Err()
+}
+
+func (c *Redis) Delete(slugs ...string) error {
+	if len(slugs) == 0 {
+		return nil
+	}
+
+	keys := make([]string, len(slugs))
+
+	for i, slug := range slugs {
+		keys[i] = c.prefix + slug
+	}
+
+	return c.client.Del(context.Background(), keys...).Err()
+}
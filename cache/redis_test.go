@@ -0,0 +1,79 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/guregu/null.v4"
+)
+
+func newRedis(t *testing.T) (*Redis, *miniredis.Miniredis) {
+	server := miniredis.RunT(t)
+
+	return NewRedis(redis.NewClient(&redis.Options{Addr: server.Addr()}), "slug:"), server
+}
+
+func TestRedisRoundTripsEntries(t *testing.T) {
+	cache, server := newRedis(t)
+
+	entry := entryFor("a")
+	entry.ShortUrl.OwnerId = "platform-team"
+	entry.ShortUrl.ExpiresOn = null.TimeFrom(time.Date(2023, 1, 1, 16, 30, 0, 0, time.UTC))
+	entry.ShortUrl.CreatedAt = time.Date(2022, 5, 11, 11, 30, 0, 0, time.UTC)
+
+	assert.Nil(t, cache.Set("a", entry, time.Minute))
+	assert.True(t, server.Exists("slug:a"))
+
+	cached, ok, err := cache.Get("a")
+
+	assert.Nil(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, int64(1), cached.ShortUrl.Id)
+	assert.Equal(t, "platform-team", cached.ShortUrl.OwnerId)
+	assert.True(t, entry.ShortUrl.ExpiresOn.Time.Equal(cached.ShortUrl.ExpiresOn.Time))
+	assert.True(t, entry.ShortUrl.CreatedAt.Equal(cached.ShortUrl.CreatedAt))
+
+	_, ok, err = cache.Get("b")
+
+	assert.Nil(t, err)
+	assert.False(t, ok)
+}
+
+func TestRedisExpiresAndDeletesEntries(t *testing.T) {
+	cache, server := newRedis(t)
+
+	assert.Nil(t, cache.Set("a", entryFor("a"), time.Minute))
+	assert.Nil(t, cache.Set("b", Entry{Missing: true}, 2*time.Minute))
+	assert.Nil(t, cache.Set("c", entryFor("c"), 2*time.Minute))
+
+	server.FastForward(time.Minute)
+
+	_, ok, _ := cache.Get("a")
+	assert.False(t, ok)
+
+	entry, ok, _ := cache.Get("b")
+	assert.True(t, ok)
+	assert.True(t, entry.Missing)
+
+	assert.Nil(t, cache.Delete("b", "c"))
+	assert.Nil(t, cache.Delete())
+
+	_, ok, _ = cache.Get("b")
+	assert.False(t, ok)
+
+	_, ok, _ = cache.Get("c")
+	assert.False(t, ok)
+}
+
+func TestRedisReportsUnreachableServer(t *testing.T) {
+	cache, server := newRedis(t)
+	server.Close()
+
+	_, ok, err := cache.Get("a")
+
+	assert.NotNil(t, err)
+	assert.False(t, ok)
+}
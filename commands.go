@@ -0,0 +1,55 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"url-shortener/models"
+	"url-shortener/repositories"
+	"url-shortener/services"
+)
+
+const usage = `usage:
+  url-shortener                     run the web server
+  url-shortener apikeys create      create an API key (see -h for flags)`
+
+// runCommand runs the administrative command given on the command line
+// instead of the web server.
+func runCommand(store repositories.Store, args []string) {
+	if len(args) >= 2 && args[0] == "apikeys" && args[1] == "create" {
+		createApiKey(store, args[2:])
+		return
+	}
+
+	fmt.Fprintln(os.Stderr, usage)
+	os.Exit(2)
+}
+
+func createApiKey(store repositories.Store, args []string) {
+	flags := flag.NewFlagSet("apikeys create", flag.ExitOnError)
+	name := flags.String("name", "", "description of what the key is for (required)")
+	owner := flags.String("owner", "", "id of the owner the key acts on behalf of (required)")
+	admin := flags.Bool("admin", false, "allow the key to modify any short url and create other keys and namespaces")
+
+	flags.Parse(args)
+
+	if *name == "" || *owner == "" {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	apiKeyService := services.ApiKeyService{Store: store}
+
+	result := apiKeyService.Create(models.ApiKeyCreateFields{
+		Name:    *name,
+		OwnerId: *owner,
+		Admin:   *admin,
+	})
+
+	if result.Error != nil {
+		fmt.Fprintf(os.Stderr, "error creating api key: %v\n", result.Error)
+		os.Exit(1)
+	}
+
+	fmt.Printf("Created api key %d for %s. It won't be shown again:\n\n%s\n", result.Record.Id, *owner, result.Key)
+}
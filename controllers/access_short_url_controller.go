@@ -2,47 +2,147 @@ package controllers
 
 import (
 	"errors"
+	"fmt"
+	"html"
+	"log"
 	"net/http"
+	"strconv"
 	"url-shortener/models"
+	"url-shortener/repositories"
+	"url-shortener/services"
 
 	"github.com/gin-gonic/gin"
-	"gorm.io/gorm"
 )
 
 type AccessShortUrlController struct {
-	DB *gorm.DB
+	SlugResolver     *services.SlugResolver
+	IpAnonymizer     services.IpAnonymizer
+	ClickRecorder    services.ClickRecorder
+	RedirectDefaults RedirectDefaults
+	Clock            services.Clock
+}
+
+// goneBody sends browsers on to an expired short URL's fallback URL, since
+// they don't follow the Location of a 410.
+const goneBody = `<!DOCTYPE html>
+<html>
+<head><meta http-equiv="refresh" content="0; url=%[1]s"></head>
+<body>This link has expired. <a href="%[1]s">Continue to %[1]s</a></body>
+</html>
+`
+
+// RedirectDefaults apply to the short URLs that don't set their own.
+type RedirectDefaults struct {
+	RedirectType int
+	CacheControl string
 }
 
 func (controller *AccessShortUrlController) HandleRequest(c *gin.Context) {
-	slug := c.Param("slug")
+	// Slugs can contain slashes, so the path is matched against the longest
+	// slug it starts with. rest is whatever follows it, and a trailing slash
+	// alone doesn't count.
+	shortUrl, rest, err := controller.SlugResolver.ResolvePath(c.Param("slug") + c.Param("rest"))
+
+	if rest == "/" {
+		rest = ""
+	}
 
-	var shortUrl models.ShortUrl
+	// Short URLs that don't pass their path through, or fill a template with
+	// it, only exist at /:slug.
+	if err == nil && rest != "" && !acceptsPath(shortUrl) {
+		err = repositories.ErrNotFound
+	}
 
-	whereClause := models.ShortUrl{}
-	whereClause.Slug = slug
+	// Expired short URLs are kept around for a while so that they can say
+	// they're gone.
+	if err == nil && services.IsExpired(shortUrl, controller.Clock.Now()) {
+		controller.gone(c, shortUrl)
+		return
+	}
 
-	err := controller.DB.
-		Where(&whereClause).
-		First(&shortUrl).Error
+	var location string
 
 	if err == nil {
-		controller.DB.Model(&shortUrl).Association("Clicks").Append(&models.Click{})
+		location, err = services.RedirectLocation(shortUrl, rest, c.Request.URL.Query())
+	}
+
+	if err == nil {
+		err = controller.ClickRecorder.Record(models.Click{
+			ShortUrlId:     shortUrl.Id,
+			Referrer:       c.Request.Referer(),
+			UserAgent:      c.Request.UserAgent(),
+			IpAddress:      controller.IpAnonymizer.Anonymize(c.ClientIP()),
+			AcceptLanguage: c.GetHeader("Accept-Language"),
+			UtmCampaign:    services.UtmCampaign(location),
+			UtmSource:      services.UtmSource(c.Request.URL.Query(), location),
+		})
+
+		// Failing to record a click shouldn't stop the redirect from
+		// happening. Dropped clicks are already counted and logged by the
+		// recorder, so they aren't logged again one by one while it's
+		// overloaded.
+		if err != nil && !errors.Is(err, services.ErrClickBufferFull) {
+			log.Printf("encountered error recording click on %s: %v", shortUrl.Slug, err)
+		}
 
-		c.Writer.Header().Set("Location", shortUrl.LongUrl)
-		c.Writer.Header().Set("Cache-Control", "no-cache")
-		c.Writer.WriteHeader(http.StatusMovedPermanently)
+		redirectType := controller.RedirectDefaults.RedirectType
+
+		if shortUrl.RedirectType != nil {
+			redirectType = *shortUrl.RedirectType
+		}
+
+		c.Writer.Header().Set("Location", location)
+		c.Writer.Header().Set("Cache-Control", controller.cacheControl(shortUrl))
+		c.Writer.WriteHeader(redirectType)
 		return
 	}
 
 	status := http.StatusInternalServerError
 
-	if errors.Is(err, gorm.ErrRecordNotFound) {
+	if errors.Is(err, repositories.ErrNotFound) || errors.Is(err, services.ErrTemplateMismatch) {
 		status = http.StatusNotFound
 	}
 
 	c.Writer.WriteHeader(status)
 }
 
+// gone answers a request for an expired short URL with 410 Gone, pointing
+// to its fallback URL if it has one. Expiration dates can be changed, so the
+// answer isn't cached.
+func (controller *AccessShortUrlController) gone(c *gin.Context, shortUrl models.ShortUrl) {
+	c.Writer.Header().Set("Cache-Control", "no-cache")
+
+	if shortUrl.FallbackUrl == nil {
+		c.Writer.WriteHeader(http.StatusGone)
+		return
+	}
+
+	c.Writer.Header().Set("Location", *shortUrl.FallbackUrl)
+	body := fmt.Sprintf(goneBody, html.EscapeString(*shortUrl.FallbackUrl))
+	c.Data(http.StatusGone, "text/html; charset=utf-8", []byte(body))
+}
+
+func acceptsPath(shortUrl models.ShortUrl) bool {
+	pathPassthrough := shortUrl.PathPassthrough != nil && *shortUrl.PathPassthrough
+
+	return pathPassthrough || services.IsTemplate(shortUrl.ShortUrlRedirectFields)
+}
+
+func (controller *AccessShortUrlController) cacheControl(shortUrl models.ShortUrl) string {
+	cacheControl := controller.RedirectDefaults.CacheControl
+
+	if shortUrl.CacheControl != nil {
+		cacheControl = *shortUrl.CacheControl
+	}
+
+	if shortUrl.CacheMaxAge != nil {
+		cacheControl += ", max-age=" + strconv.Itoa(*shortUrl.CacheMaxAge)
+	}
+
+	return cacheControl
+}
+
 func (controller *AccessShortUrlController) Register(r *gin.Engine) {
 	r.GET("/:slug", controller.HandleRequest)
+	r.GET("/:slug/*rest", controller.HandleRequest)
 }
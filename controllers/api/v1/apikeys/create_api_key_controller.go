@@ -0,0 +1,72 @@
+package apikeys
+
+import (
+	"net/http"
+	"url-shortener/e"
+	"url-shortener/middleware"
+	"url-shortener/models"
+	"url-shortener/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CreateApiKeyController struct {
+	ApiKeyService *services.ApiKeyService
+}
+
+type CreateApiKeyResponse struct {
+	// Key is only ever returned here.
+	Key string `json:"key" example:"usk_4bXh1n0xkTqz8M1dKqzUu2Yf3CnqfXH1ZlU6mKQm9pY"`
+	models.ApiKey
+}
+
+// CreateApiKey godoc
+// @Summary      Create a new API key
+// @Description  Create a new API key for an owner. Only admins may create API keys. The key is returned in the response and can't be retrieved again.
+// @Tags         apikeys
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        apikey  body      models.ApiKeyCreateFields  true  "New API key parameters"
+// @Success      201     {object}  CreateApiKeyResponse
+// @Failure      400     {object}  e.ErrorResponse
+// @Failure      401     {object}  e.ErrorResponse
+// @Failure      403     {object}  e.ErrorResponse
+// @Failure      500
+// @Router       /apikeys [post]
+func (controller *CreateApiKeyController) HandleRequest(c *gin.Context, request models.ApiKeyCreateFields) {
+	principal := middleware.GetPrincipal(c)
+
+	if principal == nil {
+		middleware.AbortUnauthorized(c, "required")
+		return
+	}
+
+	if !principal.Admin {
+		c.JSON(http.StatusForbidden, e.ErrorResponse{
+			Errors: []e.ValidationError{
+				{
+					Field:  "Authorization",
+					Reason: "must be an admin api key",
+				},
+			},
+		})
+		return
+	}
+
+	result := controller.ApiKeyService.Create(request)
+
+	if result.Error != nil {
+		c.Writer.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusCreated, CreateApiKeyResponse{
+		Key:    result.Key,
+		ApiKey: *result.Record,
+	})
+}
+
+func (controller *CreateApiKeyController) Register(r *gin.Engine) {
+	r.POST("/api/v1/apikeys", middleware.ModelBindingWrapper[models.ApiKeyCreateFields](controller))
+}
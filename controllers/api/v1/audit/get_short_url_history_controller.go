@@ -0,0 +1,52 @@
+package audit
+
+import (
+	"url-shortener/middleware"
+	"url-shortener/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type GetShortUrlHistoryController struct {
+	AuditService *services.AuditService
+}
+
+// GetShortUrlHistory  godoc
+// @Summary      Get the history of a short URL
+// @Description  List the audit events of a short URL, newest first, a page at a time, including the ones from before its slug was changed. Short URLs in the trash have a history too. Only the owner of the short URL or an admin may read its history, so short URLs created without an API key only have a history for admins.
+// @Tags         audit
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        slug            path      string  true   "slug of short URL to retrieve the history of"
+// @Param        actor           query     string  false  "only list events by this owner"
+// @Param        action          query     string  false  "only list events with this action"                         Enums(create, update, delete, restore, expire, purge)
+// @Param        request_id      query     string  false  "only list events made by the request with this X-Request-Id"
+// @Param        created_after   query     string  false  "only list events after this time, in RFC 3339 format"      format(dateTime)
+// @Param        created_before  query     string  false  "only list events before this time, in RFC 3339 format"     format(dateTime)
+// @Param        limit           query     int     false  "maximum number of events to return"                        minimum(1) maximum(100) default(50)
+// @Param        cursor          query     string  false  "next_cursor from the previous page"
+// @Success      200             {object}  ListAuditEventsResponse
+// @Failure      400             {object}  e.ErrorResponse
+// @Failure      401             {object}  e.ErrorResponse
+// @Failure      403             {object}  e.ErrorResponse
+// @Failure      404             {object}  e.ErrorResponse
+// @Failure      500
+// @Router       /shorturls/{slug}/history [get]
+func (controller *GetShortUrlHistoryController) HandleRequest(c *gin.Context, request ListAuditEventsRequest) {
+	principal := middleware.GetPrincipal(c)
+
+	if principal == nil {
+		middleware.AbortUnauthorized(c, "required")
+		return
+	}
+
+	slug := c.Param("slug")
+	result := controller.AuditService.History(principal, slug, request.query())
+
+	respond(c, result)
+}
+
+func (controller *GetShortUrlHistoryController) Register(r *gin.Engine) {
+	r.GET("/api/v1/shorturls/:slug/history", middleware.ModelBindingWrapper[ListAuditEventsRequest](controller))
+}
@@ -0,0 +1,143 @@
+package audit
+
+import (
+	"net/http"
+	"url-shortener/e"
+	"url-shortener/enums"
+	"url-shortener/middleware"
+	"url-shortener/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+const defaultListLimit = 50
+
+type ListAuditEventsController struct {
+	AuditService *services.AuditService
+}
+
+type ListAllAuditEventsRequest struct {
+	ListAuditEventsRequest
+	Slug string `form:"slug"`
+}
+
+// ListAuditEvents  godoc
+// @Summary      List audit events
+// @Description  List the changes made to short URLs, newest first, a page at a time. Every creation, update, deletion, and restoration is recorded with who made it, the ID and source IP address of the request, and snapshots of the short URL before and after, as are the short URLs that expire or are purged from the trash. Only admins may read the audit log. Pass the next_cursor from a response as the cursor of the next request (with the same filters) to get the following page; next_cursor is null on the last page.
+// @Tags         audit
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        slug            query     string  false  "only list events about short URLs with this slug at the time"
+// @Param        actor           query     string  false  "only list events by this owner"
+// @Param        action          query     string  false  "only list events with this action"                         Enums(create, update, delete, restore, expire, purge)
+// @Param        request_id      query     string  false  "only list events made by the request with this X-Request-Id"
+// @Param        created_after   query     string  false  "only list events after this time, in RFC 3339 format"      format(dateTime)
+// @Param        created_before  query     string  false  "only list events before this time, in RFC 3339 format"     format(dateTime)
+// @Param        limit           query     int     false  "maximum number of events to return"                        minimum(1) maximum(100) default(50)
+// @Param        cursor          query     string  false  "next_cursor from the previous page"
+// @Success      200             {object}  ListAuditEventsResponse
+// @Failure      400             {object}  e.ErrorResponse
+// @Failure      401             {object}  e.ErrorResponse
+// @Failure      403             {object}  e.ErrorResponse
+// @Failure      500
+// @Router       /audit [get]
+func (controller *ListAuditEventsController) HandleRequest(c *gin.Context, request ListAllAuditEventsRequest) {
+	principal := middleware.GetPrincipal(c)
+
+	if principal == nil {
+		middleware.AbortUnauthorized(c, "required")
+		return
+	}
+
+	query := request.query()
+	query.Slug = request.Slug
+
+	respond(c, controller.AuditService.List(principal, query))
+}
+
+func (controller *ListAuditEventsController) Register(r *gin.Engine) {
+	r.GET("/api/v1/audit", middleware.ModelBindingWrapper[ListAllAuditEventsRequest](controller))
+}
+
+func (request ListAuditEventsRequest) query() services.AuditQuery {
+	query := services.AuditQuery{
+		Actor:         request.Actor,
+		Action:        request.Action,
+		RequestId:     request.RequestId,
+		CreatedAfter:  request.CreatedAfter,
+		CreatedBefore: request.CreatedBefore,
+		Limit:         request.Limit,
+		Cursor:        request.Cursor,
+	}
+
+	if query.Limit == 0 {
+		query.Limit = defaultListLimit
+	}
+
+	return query
+}
+
+// respond answers either audit route with result.
+func respond(c *gin.Context, result services.AuditResult) {
+	if result.Error != nil {
+		c.Writer.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	var status int
+	var body interface{}
+
+	switch result.Status {
+	case enums.AuditResultSuccessful:
+		response := ListAuditEventsResponse{
+			Events: []AuditEventResponse{},
+		}
+
+		for _, event := range result.Records {
+			response.Events = append(response.Events, auditEventResponse(event))
+		}
+
+		if result.NextCursor != "" {
+			response.NextCursor = &result.NextCursor
+		}
+
+		status = http.StatusOK
+		body = response
+	case enums.AuditResultNotFound:
+		status = http.StatusNotFound
+		body = e.ErrorResponse{
+			Errors: []e.ValidationError{
+				{
+					Field:  "Slug",
+					Reason: "not found",
+				},
+			},
+		}
+	case enums.AuditResultForbidden:
+		status = http.StatusForbidden
+		body = e.ErrorResponse{
+			Errors: []e.ValidationError{
+				{
+					Field:  "Authorization",
+					Reason: "not allowed to read this audit log",
+				},
+			},
+		}
+	case enums.AuditResultInvalidCursor:
+		status = http.StatusBadRequest
+		body = e.ErrorResponse{
+			Errors: []e.ValidationError{
+				{
+					Field:  "Cursor",
+					Reason: "invalid",
+				},
+			},
+		}
+	default:
+		c.Writer.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(status, body)
+}
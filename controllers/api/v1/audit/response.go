@@ -0,0 +1,64 @@
+package audit
+
+import (
+	"encoding/json"
+	"time"
+	"url-shortener/models"
+)
+
+type AuditEventResponse struct {
+	Id         int64     `json:"id"          example:"42"`
+	CreatedAt  time.Time `json:"created_at"  format:"dateTime" example:"2022-05-11T11:30:00Z"`
+	ActorType  string    `json:"actor_type"  enums:"user,anonymous,system" example:"user"`
+	Actor      string    `json:"actor,omitempty" example:"platform-team"`
+	Action     string    `json:"action"      enums:"create,update,delete,restore,expire,purge" example:"update"`
+	ShortUrlId int64     `json:"short_url_id" example:"7"`
+	Slug       string    `json:"slug"        example:"myslug"`
+	// Before and After are snapshots of the short URL, or null if it didn't
+	// exist before the change or doesn't after it.
+	Before    json.RawMessage `json:"before" swaggertype:"object"`
+	After     json.RawMessage `json:"after"  swaggertype:"object"`
+	RequestId string          `json:"request_id,omitempty" example:"V1StGXR8_Z5jdHi6B-myT"`
+	IpAddress string          `json:"ip_address,omitempty" example:"192.0.2.1"`
+}
+
+type ListAuditEventsResponse struct {
+	Events []AuditEventResponse `json:"events"`
+	// NextCursor is null on the last page.
+	NextCursor *string `json:"next_cursor" example:"eyJpZCI6NDJ9"`
+}
+
+// ListAuditEventsRequest holds the filters that both audit routes take.
+type ListAuditEventsRequest struct {
+	Limit         int       `form:"limit"          binding:"omitempty,min=1,max=100"`
+	Cursor        string    `form:"cursor"`
+	Actor         *string   `form:"actor"`
+	Action        string    `form:"action"         binding:"omitempty,oneof=create update delete restore expire purge"`
+	RequestId     string    `form:"request_id"`
+	CreatedAfter  time.Time `form:"created_after"`
+	CreatedBefore time.Time `form:"created_before"`
+}
+
+func auditEventResponse(event models.AuditEvent) AuditEventResponse {
+	return AuditEventResponse{
+		Id:         event.Id,
+		CreatedAt:  event.CreatedAt,
+		ActorType:  event.ActorType,
+		Actor:      event.Actor,
+		Action:     event.Action,
+		ShortUrlId: event.ShortUrlId,
+		Slug:       event.Slug,
+		Before:     rawSnapshot(event.Before.Ptr()),
+		After:      rawSnapshot(event.After.Ptr()),
+		RequestId:  event.RequestId,
+		IpAddress:  event.IpAddress,
+	}
+}
+
+func rawSnapshot(snapshot *string) json.RawMessage {
+	if snapshot == nil {
+		return json.RawMessage("null")
+	}
+
+	return json.RawMessage(*snapshot)
+}
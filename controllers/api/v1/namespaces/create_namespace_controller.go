@@ -0,0 +1,83 @@
+package namespaces
+
+import (
+	"net/http"
+	"url-shortener/e"
+	"url-shortener/enums"
+	"url-shortener/middleware"
+	"url-shortener/models"
+	"url-shortener/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CreateNamespaceController struct {
+	NamespaceService *services.NamespaceService
+}
+
+// CreateNamespace godoc
+// @Summary      Create a new namespace
+// @Description  Reserve the short URLs under a prefix, such as eng/oncall under eng, for an owner. Only admins may create namespaces. Prefixes follow the same rules as slugs, and can themselves contain slashes.
+// @Tags         namespaces
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        namespace  body      models.NamespaceCreateFields  true  "New namespace parameters"
+// @Success      201        {object}  models.Namespace
+// @Failure      400        {object}  e.ErrorResponse
+// @Failure      401        {object}  e.ErrorResponse
+// @Failure      403        {object}  e.ErrorResponse
+// @Failure      409        {object}  e.ErrorResponse
+// @Failure      500
+// @Router       /namespaces [post]
+func (controller *CreateNamespaceController) HandleRequest(c *gin.Context, request models.NamespaceCreateFields) {
+	principal := middleware.GetPrincipal(c)
+
+	if principal == nil {
+		middleware.AbortUnauthorized(c, "required")
+		return
+	}
+
+	if !principal.Admin {
+		c.JSON(http.StatusForbidden, e.ErrorResponse{
+			Errors: []e.ValidationError{
+				{
+					Field:  "Authorization",
+					Reason: "must be an admin api key",
+				},
+			},
+		})
+		return
+	}
+
+	result := controller.NamespaceService.Create(request)
+
+	if result.Error != nil {
+		c.Writer.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	switch result.Status {
+	case enums.NamespaceResultSuccessful:
+		c.JSON(http.StatusCreated, result.Record)
+	case enums.NamespaceResultDuplicatePrefix:
+		c.JSON(http.StatusConflict, e.ErrorResponse{
+			Errors: []e.ValidationError{
+				{
+					Field:  "Prefix",
+					Reason: "must be unique",
+				},
+			},
+		})
+	case enums.NamespaceResultInvalidPrefix:
+		c.JSON(http.StatusBadRequest, e.ErrorResponse{
+			Errors: result.ValidationErrors,
+		})
+	default:
+		c.Writer.WriteHeader(http.StatusInternalServerError)
+	}
+}
+
+func (controller *CreateNamespaceController) Register(r *gin.Engine) {
+	r.POST("/api/v1/namespaces", middleware.ModelBindingWrapper[models.NamespaceCreateFields](controller))
+}
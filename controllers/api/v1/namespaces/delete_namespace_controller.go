@@ -0,0 +1,49 @@
+package namespaces
+
+import (
+	"net/http"
+	"url-shortener/enums"
+	"url-shortener/middleware"
+	"url-shortener/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type DeleteNamespaceController struct {
+	NamespaceService *services.NamespaceService
+}
+
+// DeleteNamespace godoc
+// @Summary      Delete an existing namespace
+// @Description  Delete a namespace. Only its owner or an admin may. The short URLs in it keep working, but no more can be created in it until it's created again. Slashes in the prefix must be escaped as %2F.
+// @Tags         namespaces
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        prefix  path  string  true  "prefix of namespace to delete"
+// @Success      204
+// @Failure      401  {object}  e.ErrorResponse
+// @Failure      403  {object}  e.ErrorResponse
+// @Failure      404  {object}  e.ErrorResponse
+// @Failure      500
+// @Router       /namespaces/{prefix} [delete]
+func (controller *DeleteNamespaceController) HandleRequest(c *gin.Context) {
+	prefix := c.Param("prefix")
+	result := controller.NamespaceService.Delete(middleware.GetPrincipal(c), prefix)
+
+	if result.Error != nil {
+		c.Writer.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	switch result.Status {
+	case enums.NamespaceResultSuccessful:
+		c.Writer.WriteHeader(http.StatusNoContent)
+	default:
+		abortWithNamespaceError(c, result.Status)
+	}
+}
+
+func (controller *DeleteNamespaceController) Register(r *gin.Engine) {
+	r.DELETE("/api/v1/namespaces/:prefix", controller.HandleRequest)
+}
@@ -0,0 +1,41 @@
+package namespaces
+
+import (
+	"net/http"
+	"url-shortener/models"
+	"url-shortener/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ListNamespacesController struct {
+	NamespaceService *services.NamespaceService
+}
+
+type ListNamespacesResponse struct {
+	Namespaces []models.Namespace `json:"namespaces"`
+}
+
+// ListNamespaces godoc
+// @Summary      List namespaces
+// @Description  List every namespace in order of prefix.
+// @Tags         namespaces
+// @Accept       json
+// @Produce      json
+// @Success      200  {object}  ListNamespacesResponse
+// @Failure      500
+// @Router       /namespaces [get]
+func (controller *ListNamespacesController) HandleRequest(c *gin.Context) {
+	namespaces, err := controller.NamespaceService.List()
+
+	if err != nil {
+		c.Writer.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, ListNamespacesResponse{Namespaces: namespaces})
+}
+
+func (controller *ListNamespacesController) Register(r *gin.Engine) {
+	r.GET("/api/v1/namespaces", controller.HandleRequest)
+}
@@ -0,0 +1,80 @@
+package namespaces
+
+import (
+	"net/http"
+	"url-shortener/e"
+	"url-shortener/enums"
+	"url-shortener/middleware"
+	"url-shortener/models"
+	"url-shortener/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type UpdateNamespaceController struct {
+	NamespaceService *services.NamespaceService
+}
+
+// UpdateNamespace godoc
+// @Summary      Update an existing namespace
+// @Description  Hand a namespace over to a new owner. Only its owner or an admin may. Slashes in the prefix must be escaped as %2F.
+// @Tags         namespaces
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        prefix     path      string                        true  "prefix of namespace to update"
+// @Param        namespace  body      models.NamespaceUpdateFields  true  "Namespace fields to update"
+// @Success      200        {object}  models.Namespace
+// @Failure      400        {object}  e.ErrorResponse
+// @Failure      401        {object}  e.ErrorResponse
+// @Failure      403        {object}  e.ErrorResponse
+// @Failure      404        {object}  e.ErrorResponse
+// @Failure      500
+// @Router       /namespaces/{prefix} [patch]
+func (controller *UpdateNamespaceController) HandleRequest(c *gin.Context, request models.NamespaceUpdateFields) {
+	prefix := c.Param("prefix")
+	result := controller.NamespaceService.Update(middleware.GetPrincipal(c), prefix, request)
+
+	if result.Error != nil {
+		c.Writer.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	switch result.Status {
+	case enums.NamespaceResultSuccessful:
+		c.JSON(http.StatusOK, result.Record)
+	default:
+		abortWithNamespaceError(c, result.Status)
+	}
+}
+
+func (controller *UpdateNamespaceController) Register(r *gin.Engine) {
+	r.PATCH("/api/v1/namespaces/:prefix", middleware.ModelBindingWrapper[models.NamespaceUpdateFields](controller))
+}
+
+// abortWithNamespaceError responds to a request for a namespace that
+// couldn't be found or that the caller may not modify.
+func abortWithNamespaceError(c *gin.Context, status enums.NamespaceStatus) {
+	switch status {
+	case enums.NamespaceResultNotFound:
+		c.JSON(http.StatusNotFound, e.ErrorResponse{
+			Errors: []e.ValidationError{
+				{
+					Field:  "Prefix",
+					Reason: "not found",
+				},
+			},
+		})
+	case enums.NamespaceResultForbidden:
+		c.JSON(http.StatusForbidden, e.ErrorResponse{
+			Errors: []e.ValidationError{
+				{
+					Field:  "Authorization",
+					Reason: "not allowed to modify this namespace",
+				},
+			},
+		})
+	default:
+		c.Writer.WriteHeader(http.StatusInternalServerError)
+	}
+}
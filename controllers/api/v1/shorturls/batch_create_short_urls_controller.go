@@ -0,0 +1,256 @@
+package shorturls
+
+import (
+	"errors"
+	"net/http"
+	"time"
+	"url-shortener/e"
+	"url-shortener/enums"
+	"url-shortener/middleware"
+	"url-shortener/models"
+	"url-shortener/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// batchPath is where both batch routes live. gin has no way to escape a colon
+// in a route, so registering it makes ":batch" a parameter that matches any
+// "/api/v1/shorturls:<anything>", and every route on it is guarded with
+// middleware.ExactPath to turn the paths that aren't really batchPath into a
+// 404.
+const batchPath = "/api/v1/shorturls:batch"
+
+type BatchCreateShortUrlsController struct {
+	BatchShortUrlService *services.BatchShortUrlService
+	Clock                services.Clock
+}
+
+type BatchCreateShortUrlsRequest struct {
+	// Items are validated one at a time, so that an invalid item is reported
+	// in its result rather than failing the whole request.
+	ShortUrls []models.ShortUrlCreateFields `json:"short_urls" binding:"required,min=1,max=1000"`
+	Mode      string                        `json:"mode"       binding:"omitempty,oneof=atomic best_effort" enums:"atomic,best_effort" default:"atomic"`
+}
+
+type BatchCreateShortUrlsItemResponse struct {
+	Status   string              `json:"status" enums:"created,already_exists,duplicate_slug,invalid,forbidden,error,aborted"`
+	ShortUrl *ShortUrlResponse   `json:"short_url,omitempty"`
+	Errors   []e.ValidationError `json:"errors,omitempty"`
+}
+
+type BatchCreateShortUrlsResponse struct {
+	Committed bool                               `json:"committed"`
+	Results   []BatchCreateShortUrlsItemResponse `json:"results"`
+}
+
+// BatchCreateShortUrls godoc
+// @Summary      Create many short urls
+// @Description  Create up to 1000 short urls in one request. Each item is handled like a single create and gets its own result, in the same order as the request: created, already_exists, duplicate_slug, invalid, forbidden, or error. In atomic mode (the default), nothing is kept unless every item is created or already exists; items that would have succeeded are reported as aborted and the response status is 422. In best_effort mode, every item that can be created is.
+// @Tags         shorturls
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        batch  body      BatchCreateShortUrlsRequest  true  "Short URLs to create"
+// @Success      200    {object}  BatchCreateShortUrlsResponse
+// @Failure      400    {object}  e.ErrorResponse
+// @Failure      401    {object}  e.ErrorResponse
+// @Failure      422    {object}  BatchCreateShortUrlsResponse
+// @Failure      500
+// @Router       /shorturls:batch [post]
+func (controller *BatchCreateShortUrlsController) HandleRequest(c *gin.Context, request BatchCreateShortUrlsRequest) {
+	mode := parseBatchMode(request.Mode)
+	principal := middleware.GetPrincipal(c)
+
+	response := BatchCreateShortUrlsResponse{
+		Results: make([]BatchCreateShortUrlsItemResponse, len(request.ShortUrls)),
+	}
+
+	// Indexes into the request of the items that passed validation.
+	var validIndexes []int
+	var shortUrls []models.ShortUrl
+
+	for i, fields := range request.ShortUrls {
+		if errs := validateBatchItem(fields); errs != nil {
+			response.Results[i] = BatchCreateShortUrlsItemResponse{
+				Status: "invalid",
+				Errors: errs,
+			}
+			continue
+		}
+
+		shortUrl := models.ShortUrl{}
+		shortUrl.ShortUrlCreateFields = fields
+
+		if principal != nil {
+			shortUrl.OwnerId = principal.OwnerId
+		}
+
+		validIndexes = append(validIndexes, i)
+		shortUrls = append(shortUrls, shortUrl)
+	}
+
+	// An atomic batch with an invalid item can't succeed, so there's no need
+	// to touch the database.
+	if mode == enums.BatchModeAtomic && len(shortUrls) < len(request.ShortUrls) {
+		for _, i := range validIndexes {
+			response.Results[i] = BatchCreateShortUrlsItemResponse{Status: "aborted"}
+		}
+
+		c.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	result := controller.BatchShortUrlService.Create(principal, middleware.GetOrigin(c), shortUrls, mode)
+
+	if result.Error != nil {
+		c.Writer.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	response.Committed = result.Committed
+
+	now := controller.Clock.Now()
+
+	for j, creationResult := range result.Results {
+		response.Results[validIndexes[j]] = batchCreateItemResponse(c, creationResult, result.Committed, now)
+	}
+
+	status := http.StatusOK
+
+	if !result.Committed {
+		status = http.StatusUnprocessableEntity
+	}
+
+	c.JSON(status, response)
+}
+
+func (controller *BatchCreateShortUrlsController) Register(r *gin.Engine) {
+	r.POST(
+		batchPath,
+		middleware.ExactPath(batchPath),
+		middleware.ModelBindingWrapper[BatchCreateShortUrlsRequest](controller),
+	)
+}
+
+func validateBatchItem(fields models.ShortUrlCreateFields) []e.ValidationError {
+	err := binding.Validator.ValidateStruct(fields)
+
+	if err == nil {
+		return nil
+	}
+
+	var verr validator.ValidationErrors
+
+	if errors.As(err, &verr) {
+		return e.FormatErrors(verr)
+	}
+
+	return []e.ValidationError{{Reason: err.Error()}}
+}
+
+func batchCreateItemResponse(c *gin.Context, result services.CreationResult, committed bool, now time.Time) BatchCreateShortUrlsItemResponse {
+	if result.Error != nil {
+		return BatchCreateShortUrlsItemResponse{Status: "error"}
+	}
+
+	switch result.Status {
+	case enums.CreationResultCreated, enums.CreationResultAlreadyExists:
+		if !committed {
+			return BatchCreateShortUrlsItemResponse{Status: "aborted"}
+		}
+
+		status := "created"
+
+		if result.Status == enums.CreationResultAlreadyExists {
+			status = "already_exists"
+		}
+
+		shortUrl := shortUrlResponseHelper{
+			Host:     c.Request.Host,
+			Now:      now,
+			ShortUrl: *result.Record,
+		}.response()
+
+		return BatchCreateShortUrlsItemResponse{
+			Status:   status,
+			ShortUrl: &shortUrl,
+		}
+	case enums.CreationResultDuplicateSlug:
+		return BatchCreateShortUrlsItemResponse{
+			Status: "duplicate_slug",
+			Errors: []e.ValidationError{
+				{
+					Field:  "Slug",
+					Reason: "must be unique",
+				},
+			},
+		}
+	case enums.CreationResultInvalidLongUrl:
+		return BatchCreateShortUrlsItemResponse{
+			Status: "invalid",
+			Errors: []e.ValidationError{
+				{
+					Field:  "LongUrl",
+					Reason: "only http and https are supported",
+				},
+			},
+		}
+	case enums.CreationResultInvalidTemplate:
+		return BatchCreateShortUrlsItemResponse{
+			Status: "invalid",
+			Errors: []e.ValidationError{
+				{
+					Field:  "LongUrl",
+					Reason: "must be an http or https url with placeholders only in its path, query string, or fragment",
+				},
+			},
+		}
+	case enums.CreationResultInvalidFallbackUrl:
+		return BatchCreateShortUrlsItemResponse{
+			Status: "invalid",
+			Errors: []e.ValidationError{
+				{
+					Field:  "FallbackUrl",
+					Reason: "only http and https are supported",
+				},
+			},
+		}
+	case enums.CreationResultInvalidSlug:
+		return BatchCreateShortUrlsItemResponse{
+			Status: "invalid",
+			Errors: result.ValidationErrors,
+		}
+	case enums.CreationResultSlugOutsideNamespace:
+		return BatchCreateShortUrlsItemResponse{
+			Status: "invalid",
+			Errors: []e.ValidationError{
+				{
+					Field:  "Slug",
+					Reason: "must be in a namespace to contain a slash",
+				},
+			},
+		}
+	case enums.CreationResultForbiddenSlug:
+		return BatchCreateShortUrlsItemResponse{
+			Status: "forbidden",
+			Errors: []e.ValidationError{
+				{
+					Field:  "Authorization",
+					Reason: "not allowed to use this namespace",
+				},
+			},
+		}
+	}
+
+	return BatchCreateShortUrlsItemResponse{Status: "error"}
+}
+
+func parseBatchMode(mode string) enums.BatchMode {
+	if mode == "best_effort" {
+		return enums.BatchModeBestEffort
+	}
+
+	return enums.BatchModeAtomic
+}
@@ -0,0 +1,102 @@
+package shorturls
+
+import (
+	"net/http"
+	"url-shortener/enums"
+	"url-shortener/middleware"
+	"url-shortener/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type BatchDeleteShortUrlsController struct {
+	BatchShortUrlService *services.BatchShortUrlService
+}
+
+type BatchDeleteShortUrlsRequest struct {
+	Slugs []string `json:"slugs" binding:"required,min=1,max=1000"`
+	Mode  string   `json:"mode"  binding:"omitempty,oneof=atomic best_effort" enums:"atomic,best_effort" default:"atomic"`
+}
+
+type BatchDeleteShortUrlsItemResponse struct {
+	Slug   string `json:"slug"`
+	Status string `json:"status" enums:"deleted,not_found,forbidden,error,aborted"`
+}
+
+type BatchDeleteShortUrlsResponse struct {
+	Committed bool                               `json:"committed"`
+	Results   []BatchDeleteShortUrlsItemResponse `json:"results"`
+}
+
+// BatchDeleteShortUrls godoc
+// @Summary      Delete many short urls
+// @Description  Delete up to 1000 short urls by slug in one request. Each slug gets its own result, in the same order as the request: deleted, not_found, forbidden, or error. In atomic mode (the default), nothing is deleted unless every short url can be; slugs that would have been deleted are reported as aborted and the response status is 422. In best_effort mode, every short url that can be deleted is.
+// @Tags         shorturls
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        batch  body      BatchDeleteShortUrlsRequest  true  "Slugs of short URLs to delete"
+// @Success      200    {object}  BatchDeleteShortUrlsResponse
+// @Failure      400    {object}  e.ErrorResponse
+// @Failure      401    {object}  e.ErrorResponse
+// @Failure      422    {object}  BatchDeleteShortUrlsResponse
+// @Failure      500
+// @Router       /shorturls:batch [delete]
+func (controller *BatchDeleteShortUrlsController) HandleRequest(c *gin.Context, request BatchDeleteShortUrlsRequest) {
+	result := controller.BatchShortUrlService.Delete(
+		middleware.GetPrincipal(c),
+		middleware.GetOrigin(c),
+		request.Slugs,
+		parseBatchMode(request.Mode),
+	)
+
+	if result.Error != nil {
+		c.Writer.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	response := BatchDeleteShortUrlsResponse{
+		Committed: result.Committed,
+		Results:   make([]BatchDeleteShortUrlsItemResponse, len(request.Slugs)),
+	}
+
+	for i, deleteResult := range result.Results {
+		var status string
+
+		switch {
+		case deleteResult.Error != nil:
+			status = "error"
+		case deleteResult.Status == enums.DeleteResultSuccessful && !result.Committed:
+			status = "aborted"
+		case deleteResult.Status == enums.DeleteResultSuccessful:
+			status = "deleted"
+		case deleteResult.Status == enums.DeleteResultNotFound:
+			status = "not_found"
+		case deleteResult.Status == enums.DeleteResultForbidden:
+			status = "forbidden"
+		default:
+			status = "error"
+		}
+
+		response.Results[i] = BatchDeleteShortUrlsItemResponse{
+			Slug:   request.Slugs[i],
+			Status: status,
+		}
+	}
+
+	status := http.StatusOK
+
+	if !result.Committed {
+		status = http.StatusUnprocessableEntity
+	}
+
+	c.JSON(status, response)
+}
+
+func (controller *BatchDeleteShortUrlsController) Register(r *gin.Engine) {
+	r.DELETE(
+		batchPath,
+		middleware.ExactPath(batchPath),
+		middleware.ModelBindingWrapper[BatchDeleteShortUrlsRequest](controller),
+	)
+}
@@ -0,0 +1,99 @@
+package clicks
+
+import (
+	"fmt"
+	"net/http"
+	"url-shortener/e"
+	"url-shortener/enums"
+	"url-shortener/middleware"
+	"url-shortener/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetShortUrlClickBreakdownController serves one breakdown route per
+// dimension, so it is registered once for each of referrers, user agents,
+// languages, campaigns and sources.
+type GetShortUrlClickBreakdownController struct {
+	GetClicksService *services.GetClicksService
+	Dimension        enums.ClickDimension
+}
+
+type GetShortUrlClickBreakdownResponse struct {
+	TimePeriod string                         `json:"time_period,omitempty"`
+	Breakdown  []services.ClickBreakdownEntry `json:"breakdown"`
+}
+
+// GetShortUrlClickBreakdown  godoc
+// @Summary      Get a breakdown of clicks for a short URL
+// @Description  Get the number of clicks for a short URL grouped by the referrer, user agent, Accept-Language header, UTM campaign, or UTM source of each visit, ordered from most to least common. Clicks are selected the same way as when counting them: with either a named time period or an explicit from/to range.
+// @Tags         shorturls
+// @Accept       json
+// @Produce      json
+// @Param        slug         path      string  true   "slug of short URL to retrieve statistics for"
+// @Param        time_period  query     string  false  "time period to retrieve statistics for"                        Enums(24_HOURS, 1_WEEK, ALL_TIME, TODAY, YESTERDAY, THIS_WEEK, THIS_MONTH, LAST_MONTH, LAST_7_DAYS, LAST_30_DAYS)
+// @Param        from         query     string  false  "count clicks at or after this time, in RFC 3339 format"        format(dateTime)
+// @Param        to           query     string  false  "count clicks before this time, in RFC 3339 format"             format(dateTime)
+// @Param        tz           query     string  false  "IANA time zone that calendar time periods are evaluated in"  default(UTC)
+// @Success      200          {object}  GetShortUrlClickBreakdownResponse
+// @Failure      400          {object}  e.ErrorResponse
+// @Failure      404          {object}  e.ErrorResponse
+// @Failure      500
+// @Router       /shorturls/{slug}/clicks/referrers [get]
+// @Router       /shorturls/{slug}/clicks/user-agents [get]
+// @Router       /shorturls/{slug}/clicks/languages [get]
+// @Router       /shorturls/{slug}/clicks/campaigns [get]
+// @Router       /shorturls/{slug}/clicks/sources [get]
+func (controller *GetShortUrlClickBreakdownController) HandleRequest(c *gin.Context, request GetShortUrlClicksRequest) {
+	slug := c.Param("slug")
+
+	result := controller.GetClicksService.GetClickBreakdown(
+		slug,
+		controller.Dimension,
+		resolveTimeRange(controller.GetClicksService, request),
+	)
+
+	var status int
+	var body interface{}
+
+	if result.Error != nil {
+		c.Writer.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	switch result.Status {
+	case enums.GetClicksResultSuccessful:
+		status = http.StatusOK
+		body = GetShortUrlClickBreakdownResponse{
+			TimePeriod: request.TimePeriod,
+			Breakdown:  result.Breakdown,
+		}
+	case enums.GetClicksResultNotFound:
+		status = http.StatusNotFound
+		body = e.ErrorResponse{
+			Errors: []e.ValidationError{
+				{
+					Field:  "Slug",
+					Reason: "not found",
+				},
+			},
+		}
+	}
+
+	c.JSON(status, body)
+}
+
+func (controller *GetShortUrlClickBreakdownController) Register(r *gin.Engine) {
+	path := map[enums.ClickDimension]string{
+		enums.ClickDimensionReferrer:  "referrers",
+		enums.ClickDimensionUserAgent: "user-agents",
+		enums.ClickDimensionLanguage:  "languages",
+		enums.ClickDimensionCampaign:  "campaigns",
+		enums.ClickDimensionSource:    "sources",
+	}[controller.Dimension]
+
+	r.GET(
+		fmt.Sprintf("/api/v1/shorturls/:slug/clicks/%s", path),
+		middleware.ModelBindingWrapper[GetShortUrlClicksRequest](controller),
+	)
+}
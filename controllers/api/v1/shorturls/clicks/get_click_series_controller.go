@@ -0,0 +1,114 @@
+package clicks
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+	"url-shortener/e"
+	"url-shortener/enums"
+	"url-shortener/middleware"
+	"url-shortener/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type GetShortUrlClickSeriesController struct {
+	GetClicksService *services.GetClicksService
+}
+
+type GetShortUrlClickSeriesRequest struct {
+	From     time.Time `form:"from"     binding:"required"`
+	To       time.Time `form:"to"       binding:"required,gtfield=From"`
+	Interval string    `form:"interval" binding:"oneof=hour day week,required"`
+	TimeZone string    `form:"tz"       binding:"omitempty,timezone"`
+}
+
+type GetShortUrlClickSeriesResponse struct {
+	Interval string                       `json:"interval"`
+	TimeZone string                       `json:"tz"`
+	Buckets  []services.ClickSeriesBucket `json:"buckets"`
+}
+
+// GetShortUrlClickSeries  godoc
+// @Summary      Get a time series of clicks for a short URL
+// @Description  Get the number of clicks for a short URL in each hour, day, or week between two points in time. Buckets are aligned to the given IANA time zone (UTC by default) and buckets without any clicks are included with a count of 0. The series is widened to whole buckets: the buckets that from and to fall in are counted in full.
+// @Tags         shorturls
+// @Accept       json
+// @Produce      json
+// @Param        slug      path      string  true   "slug of short URL to retrieve statistics for"
+// @Param        from      query     string  true   "start of the series, in RFC 3339 format; the bucket it falls in is counted in full"  format(dateTime)
+// @Param        to        query     string  true   "end of the series (exclusive), in RFC 3339 format; the bucket it falls in is counted in full"  format(dateTime)
+// @Param        interval  query     string  true   "size of each bucket"                                  Enums(hour, day, week)
+// @Param        tz        query     string  false  "IANA time zone that buckets are aligned to"           default(UTC)
+// @Success      200       {object}  GetShortUrlClickSeriesResponse
+// @Failure      400       {object}  e.ErrorResponse
+// @Failure      404       {object}  e.ErrorResponse
+// @Failure      500
+// @Router       /shorturls/{slug}/clicks/series [get]
+func (controller *GetShortUrlClickSeriesController) HandleRequest(c *gin.Context, request GetShortUrlClickSeriesRequest) {
+	slug := c.Param("slug")
+
+	if request.TimeZone == "" {
+		request.TimeZone = "UTC"
+	}
+
+	// The time zone has already been validated during binding.
+	location, _ := time.LoadLocation(request.TimeZone)
+
+	var interval enums.ClickSeriesInterval
+
+	switch request.Interval {
+	case "hour":
+		interval = enums.ClickSeriesIntervalHour
+	case "day":
+		interval = enums.ClickSeriesIntervalDay
+	case "week":
+		interval = enums.ClickSeriesIntervalWeek
+	}
+
+	result := controller.GetClicksService.GetClickSeries(slug, request.From, request.To, interval, location)
+
+	var status int
+	var body interface{}
+
+	if result.Error != nil {
+		c.Writer.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	switch result.Status {
+	case enums.GetClicksResultSuccessful:
+		status = http.StatusOK
+		body = GetShortUrlClickSeriesResponse{
+			Interval: request.Interval,
+			TimeZone: request.TimeZone,
+			Buckets:  result.Buckets,
+		}
+	case enums.GetClicksResultRangeTooLarge:
+		status = http.StatusBadRequest
+		body = e.ErrorResponse{
+			Errors: []e.ValidationError{
+				{
+					Field:  "Interval",
+					Reason: fmt.Sprintf("range must contain at most %d intervals", services.MaxClickSeriesBuckets),
+				},
+			},
+		}
+	case enums.GetClicksResultNotFound:
+		status = http.StatusNotFound
+		body = e.ErrorResponse{
+			Errors: []e.ValidationError{
+				{
+					Field:  "Slug",
+					Reason: "not found",
+				},
+			},
+		}
+	}
+
+	c.JSON(status, body)
+}
+
+func (controller *GetShortUrlClickSeriesController) Register(r *gin.Engine) {
+	r.GET("/api/v1/shorturls/:slug/clicks/series", middleware.ModelBindingWrapper[GetShortUrlClickSeriesRequest](controller))
+}
@@ -2,6 +2,7 @@ package clicks
 
 import (
 	"net/http"
+	"time"
 	"url-shortener/e"
 	"url-shortener/enums"
 	"url-shortener/middleware"
@@ -14,42 +15,44 @@ type GetShortUrlClicksController struct {
 	GetClicksService *services.GetClicksService
 }
 
+// GetShortUrlClicksRequest selects the clicks to count, either with a named
+// time period or with explicit from/to bounds (but not both).
 type GetShortUrlClicksRequest struct {
-	TimePeriod string `form:"time_period" binding:"oneof=24_HOURS 1_WEEK ALL_TIME,required"`
+	TimePeriod string    `form:"time_period" binding:"required_without_all=From To,excluded_with=From To,omitempty,oneof=24_HOURS 1_WEEK ALL_TIME TODAY YESTERDAY THIS_WEEK THIS_MONTH LAST_MONTH LAST_7_DAYS LAST_30_DAYS"`
+	From       time.Time `form:"from"`
+	To         time.Time `form:"to"          binding:"omitempty,gtfield=From"`
+	TimeZone   string    `form:"tz"          binding:"omitempty,timezone"`
 }
 
 type GetShortUrlClicksResponse struct {
-	Count      int64  `json:"count"`
-	TimePeriod string `json:"time_period"`
+	Count      int64      `json:"count"`
+	TimePeriod string     `json:"time_period,omitempty"`
+	From       *time.Time `json:"from,omitempty"`
+	To         *time.Time `json:"to,omitempty"`
+	TimeZone   string     `json:"tz,omitempty"`
 }
 
 // GetShortUrlClicks  godoc
 // @Summary      Get clicks for a short URL
-// @Description  Get clicks (statistics) for a short URL. Time periods of all time, 24 hours, and 1 week are permitted.
+// @Description  Get clicks (statistics) for a short URL. Either a named time period or an explicit from/to range must be supplied. Calendar time periods (days, weeks starting on Monday, and months) are evaluated in the given IANA time zone, which defaults to UTC. The LAST_7_DAYS and LAST_30_DAYS periods include today.
 // @Tags         shorturls
 // @Accept       json
 // @Produce      json
-// @Param        slug         path      string  true  "slug of short URL to retrieve statistics for"
-// @Param        time_period  query     string  true  "time period to retrieve statistics for"  Enums(24_HOURS, 1_WEEK, ALL_TIME)
+// @Param        slug         path      string  true   "slug of short URL to retrieve statistics for"
+// @Param        time_period  query     string  false  "time period to retrieve statistics for"                        Enums(24_HOURS, 1_WEEK, ALL_TIME, TODAY, YESTERDAY, THIS_WEEK, THIS_MONTH, LAST_MONTH, LAST_7_DAYS, LAST_30_DAYS)
+// @Param        from         query     string  false  "count clicks at or after this time, in RFC 3339 format"        format(dateTime)
+// @Param        to           query     string  false  "count clicks before this time, in RFC 3339 format"             format(dateTime)
+// @Param        tz           query     string  false  "IANA time zone that calendar time periods are evaluated in"  default(UTC)
 // @Success      200          {object}  GetShortUrlClicksResponse
+// @Failure      400          {object}  e.ErrorResponse
 // @Failure      404          {object}  e.ErrorResponse
 // @Failure      500
 // @Router       /shorturls/{slug}/clicks [get]
 func (controller *GetShortUrlClicksController) HandleRequest(c *gin.Context, request GetShortUrlClicksRequest) {
 	slug := c.Param("slug")
 
-	var timePeriod enums.GetClicksTimePeriod
-
-	switch request.TimePeriod {
-	case "24_HOURS":
-		timePeriod = enums.GetClicksTimePeriod24Hours
-	case "1_WEEK":
-		timePeriod = enums.GetClicksTimePeriodPastWeek
-	case "ALL_TIME":
-		timePeriod = enums.GetClicksTimePeriodAllTime
-	}
-
-	result := controller.GetClicksService.GetClicks(slug, timePeriod)
+	timeRange := resolveTimeRange(controller.GetClicksService, request)
+	result := controller.GetClicksService.GetClicksInRange(slug, timeRange)
 
 	var status int
 	var body interface{}
@@ -65,6 +68,9 @@ func (controller *GetShortUrlClicksController) HandleRequest(c *gin.Context, req
 		body = GetShortUrlClicksResponse{
 			Count:      result.Count,
 			TimePeriod: request.TimePeriod,
+			From:       optionalTime(timeRange.Start),
+			To:         optionalTime(timeRange.End),
+			TimeZone:   request.TimeZone,
 		}
 	case enums.GetClicksResultNotFound:
 		status = http.StatusNotFound
@@ -81,6 +87,48 @@ func (controller *GetShortUrlClicksController) HandleRequest(c *gin.Context, req
 	c.JSON(status, body)
 }
 
+// resolveTimeRange turns the time period or explicit bounds in a request into
+// the range of time to count clicks in.
+func resolveTimeRange(service *services.GetClicksService, request GetShortUrlClicksRequest) services.TimeRange {
+	if request.TimePeriod == "" {
+		return services.TimeRange{Start: request.From, End: request.To}
+	}
+
+	location := time.UTC
+
+	if request.TimeZone != "" {
+		// The time zone has already been validated during binding.
+		location, _ = time.LoadLocation(request.TimeZone)
+	}
+
+	return service.TimePeriodRange(parseTimePeriod(request.TimePeriod), location)
+}
+
+func parseTimePeriod(timePeriod string) enums.GetClicksTimePeriod {
+	timePeriods := map[string]enums.GetClicksTimePeriod{
+		"24_HOURS":     enums.GetClicksTimePeriod24Hours,
+		"1_WEEK":       enums.GetClicksTimePeriodPastWeek,
+		"ALL_TIME":     enums.GetClicksTimePeriodAllTime,
+		"TODAY":        enums.GetClicksTimePeriodToday,
+		"YESTERDAY":    enums.GetClicksTimePeriodYesterday,
+		"THIS_WEEK":    enums.GetClicksTimePeriodThisWeek,
+		"THIS_MONTH":   enums.GetClicksTimePeriodThisMonth,
+		"LAST_MONTH":   enums.GetClicksTimePeriodLastMonth,
+		"LAST_7_DAYS":  enums.GetClicksTimePeriodLast7Days,
+		"LAST_30_DAYS": enums.GetClicksTimePeriodLast30Days,
+	}
+
+	return timePeriods[timePeriod]
+}
+
+func optionalTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+
+	return &t
+}
+
 func (controller *GetShortUrlClicksController) Register(r *gin.Engine) {
 	r.GET("/api/v1/shorturls/:slug/clicks", middleware.ModelBindingWrapper[GetShortUrlClicksRequest](controller))
 }
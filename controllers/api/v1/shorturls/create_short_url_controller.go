@@ -13,24 +13,34 @@ import (
 
 type CreateShortUrlController struct {
 	CreateShortUrlService *services.CreateShortUrlService
+	Clock                 services.Clock
 }
 
 // CreateShortUrl godoc
 // @Summary      Create a new short url
-// @Description  Create a new short url. Users may specify a slug and an expiration date. If a slug is not supplied, an 8 character slug will automatically be generated for the short url.
+// @Description  Create a new short url. Users may specify a slug and an expiration date. If a slug is not supplied, one will automatically be generated for the short url, and generated again if it collides with an existing slug. Short urls created with an API key are owned by the owner of that key. A slug containing a slash, such as eng/oncall, must be in a namespace, and only the namespace's owner or an admin may use it.
 // @Tags         shorturls
 // @Accept       json
 // @Produce      json
+// @Security     BearerAuth
 // @Param        shorturl  body      models.ShortUrlCreateFields  true  "New short URL parameters"
-// @Success      200       {object}  models.ShortUrlReadFields
-// @Success      201       {object}  models.ShortUrlReadFields
+// @Success      200       {object}  ShortUrlResponse
+// @Success      201       {object}  ShortUrlResponse
 // @Failure      400       {object}  e.ErrorResponse
+// @Failure      401       {object}  e.ErrorResponse
+// @Failure      403       {object}  e.ErrorResponse
 // @Failure      404
 // @Failure      409  {object}  e.ErrorResponse
 // @Failure      500
 // @Router       /shorturls [post]
 func (controller *CreateShortUrlController) HandleRequest(c *gin.Context, request models.ShortUrl) {
-	createResult := controller.CreateShortUrlService.Create(&request)
+	principal := middleware.GetPrincipal(c)
+
+	if principal != nil {
+		request.OwnerId = principal.OwnerId
+	}
+
+	createResult := controller.CreateShortUrlService.Create(principal, middleware.GetOrigin(c), &request)
 
 	if createResult.Error != nil {
 		c.Writer.WriteHeader(http.StatusInternalServerError)
@@ -45,12 +55,14 @@ func (controller *CreateShortUrlController) HandleRequest(c *gin.Context, reques
 		status = http.StatusCreated
 		body = shortUrlResponseHelper{
 			Host:     c.Request.Host,
+			Now:      controller.Clock.Now(),
 			ShortUrl: *createResult.Record,
 		}
 	case enums.CreationResultAlreadyExists:
 		status = http.StatusOK
 		body = shortUrlResponseHelper{
 			Host:     c.Request.Host,
+			Now:      controller.Clock.Now(),
 			ShortUrl: *createResult.Record,
 		}
 	case enums.CreationResultDuplicateSlug:
@@ -73,6 +85,51 @@ func (controller *CreateShortUrlController) HandleRequest(c *gin.Context, reques
 				},
 			},
 		}
+	case enums.CreationResultInvalidTemplate:
+		status = http.StatusBadRequest
+		body = e.ErrorResponse{
+			Errors: []e.ValidationError{
+				{
+					Field:  "LongUrl",
+					Reason: "must be an http or https url with placeholders only in its path, query string, or fragment",
+				},
+			},
+		}
+	case enums.CreationResultInvalidFallbackUrl:
+		status = http.StatusBadRequest
+		body = e.ErrorResponse{
+			Errors: []e.ValidationError{
+				{
+					Field:  "FallbackUrl",
+					Reason: "only http and https are supported",
+				},
+			},
+		}
+	case enums.CreationResultInvalidSlug:
+		status = http.StatusBadRequest
+		body = e.ErrorResponse{
+			Errors: createResult.ValidationErrors,
+		}
+	case enums.CreationResultSlugOutsideNamespace:
+		status = http.StatusBadRequest
+		body = e.ErrorResponse{
+			Errors: []e.ValidationError{
+				{
+					Field:  "Slug",
+					Reason: "must be in a namespace to contain a slash",
+				},
+			},
+		}
+	case enums.CreationResultForbiddenSlug:
+		status = http.StatusForbidden
+		body = e.ErrorResponse{
+			Errors: []e.ValidationError{
+				{
+					Field:  "Authorization",
+					Reason: "not allowed to use this namespace",
+				},
+			},
+		}
 	}
 
 	c.JSON(status, body)
@@ -4,31 +4,33 @@ import (
 	"net/http"
 	"url-shortener/e"
 	"url-shortener/enums"
+	"url-shortener/middleware"
 	"url-shortener/services"
 
 	"github.com/gin-gonic/gin"
-	"gorm.io/gorm"
 )
 
 type DeleteShortUrlController struct {
-	DB                    *gorm.DB
 	DeleteShortUrlService *services.DeleteShortUrlService
 }
 
 // DeleteShortUrl  godoc
 // @Summary      Delete an existing short URL
-// @Description  Delete an existing short URL by supplying the slug.
+// @Description  Delete an existing short URL by supplying the slug. Only the owner of the short URL or an admin may delete it. Deleted short URLs are moved to the trash, where they keep their statistics and their slug until they are purged, and can be restored until then.
 // @Tags         shorturls
 // @Accept       json
 // @Produce      json
+// @Security     BearerAuth
 // @Param        slug  path  string  true  "slug of short URL to delete"
 // @Success      204
+// @Failure      401  {object}  e.ErrorResponse
+// @Failure      403  {object}  e.ErrorResponse
 // @Failure      404  {object}  e.ErrorResponse
 // @Failure      500
 // @Router       /shorturls/{slug} [delete]
 func (controller *DeleteShortUrlController) HandleRequest(c *gin.Context) {
 	slug := c.Param("slug")
-	result := controller.DeleteShortUrlService.Delete(slug)
+	result := controller.DeleteShortUrlService.Delete(middleware.GetPrincipal(c), middleware.GetOrigin(c), slug)
 
 	if result.Error != nil {
 		c.Writer.WriteHeader(http.StatusInternalServerError)
@@ -47,6 +49,15 @@ func (controller *DeleteShortUrlController) HandleRequest(c *gin.Context) {
 				},
 			},
 		})
+	case enums.DeleteResultForbidden:
+		c.JSON(http.StatusForbidden, e.ErrorResponse{
+			Errors: []e.ValidationError{
+				{
+					Field:  "Authorization",
+					Reason: "not allowed to modify this short url",
+				},
+			},
+		})
 	default:
 		c.Writer.WriteHeader(http.StatusInternalServerError)
 	}
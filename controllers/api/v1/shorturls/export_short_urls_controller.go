@@ -0,0 +1,67 @@
+package shorturls
+
+import (
+	"log"
+	"net/http"
+	"url-shortener/enums"
+	"url-shortener/middleware"
+	"url-shortener/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ExportShortUrlsController struct {
+	ExportShortUrlsService *services.ExportShortUrlsService
+}
+
+type ExportShortUrlsRequest struct {
+	Format string `form:"format" binding:"omitempty,oneof=csv jsonl"`
+}
+
+// ExportShortUrls godoc
+// @Summary      Export short URLs
+// @Description  Export short URLs along with their total number of clicks, as CSV (with a header row) or JSON Lines. Admins export every short URL, and anyone else only the short URLs they own. The export is streamed, so it starts arriving right away no matter how many short URLs there are.
+// @Tags         shorturls
+// @Produce      text/csv
+// @Produce      application/x-ndjson
+// @Security     BearerAuth
+// @Param        format  query     string  false  "format of the export"  Enums(csv, jsonl)  default(csv)
+// @Success      200     {string}  string
+// @Failure      400     {object}  e.ErrorResponse
+// @Failure      401     {object}  e.ErrorResponse
+// @Router       /shorturls/export [get]
+func (controller *ExportShortUrlsController) HandleRequest(c *gin.Context, request ExportShortUrlsRequest) {
+	principal := middleware.GetPrincipal(c)
+
+	if principal == nil {
+		middleware.AbortUnauthorized(c, "required")
+		return
+	}
+
+	format, contentType, filename := parseRecordFormat(request.Format)
+
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+	c.Status(http.StatusOK)
+
+	writer := services.NewShortUrlRecordWriter(c.Writer, format)
+
+	// Once the export has started, the status has already been sent, so
+	// all that can be done about an error is to stop.
+	if err := controller.ExportShortUrlsService.Export(principal, writer); err != nil {
+		log.Printf("encountered error exporting short urls: %v", err)
+		c.Abort()
+	}
+}
+
+func (controller *ExportShortUrlsController) Register(r *gin.Engine) {
+	r.GET("/api/v1/shorturls/export", middleware.ModelBindingWrapper[ExportShortUrlsRequest](controller))
+}
+
+func parseRecordFormat(format string) (enums.RecordFormat, string, string) {
+	if format == "jsonl" {
+		return enums.RecordFormatJsonl, "application/x-ndjson", "shorturls.jsonl"
+	}
+
+	return enums.RecordFormatCsv, "text/csv", "shorturls.csv"
+}
@@ -3,42 +3,37 @@ package shorturls
 import (
 	"errors"
 	"net/http"
-	"url-shortener/models"
+	"url-shortener/repositories"
+	"url-shortener/services"
 
 	"github.com/gin-gonic/gin"
-	"gorm.io/gorm"
 )
 
 type GetShortUrlController struct {
-	DB *gorm.DB
+	ShortUrls repositories.ShortUrlRepository
+	Clock     services.Clock
 }
 
 // GetShortUrl godoc
 // @Summary      Get information about an existing short URL
-// @Description  Get information about an existing short URL
+// @Description  Get information about an existing short URL, including whether it is active or has expired. Expired short URLs can be looked up until they are deleted at the end of their grace period.
 // @Tags         shorturls
 // @Accept       json
 // @Produce      json
 // @Param        slug  path      string  true  "slug of short URL to get information about"
-// @Success      200   {object}  models.ShortUrlReadFields
+// @Success      200   {object}  ShortUrlResponse
 // @Failure      404   {object}  e.ErrorResponse
 // @Failure      500
 // @Router       /shorturls/{slug} [get]
 func (controller *GetShortUrlController) HandleRequest(c *gin.Context) {
 	slug := c.Param("slug")
 
-	var shortUrl models.ShortUrl
-
-	whereClause := models.ShortUrl{}
-	whereClause.Slug = slug
-
-	err := controller.DB.
-		Where(&whereClause).
-		First(&shortUrl).Error
+	shortUrl, err := controller.ShortUrls.FindBySlug(slug)
 
 	if err == nil {
 		c.JSON(http.StatusOK, shortUrlResponseHelper{
 			Host:     c.Request.Host,
+			Now:      controller.Clock.Now(),
 			ShortUrl: shortUrl,
 		})
 
@@ -47,7 +42,7 @@ func (controller *GetShortUrlController) HandleRequest(c *gin.Context) {
 
 	status := http.StatusInternalServerError
 
-	if errors.Is(err, gorm.ErrRecordNotFound) {
+	if errors.Is(err, repositories.ErrNotFound) {
 		status = http.StatusNotFound
 	}
 
@@ -0,0 +1,124 @@
+package shorturls
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"net/url"
+	"url-shortener/enums"
+	"url-shortener/middleware"
+	"url-shortener/repositories"
+	"url-shortener/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultQrCodeSize   = 256
+	defaultQrCodeMargin = 4
+)
+
+type GetShortUrlQrCodeController struct {
+	ShortUrls repositories.ShortUrlRepository
+}
+
+type GetShortUrlQrCodeRequest struct {
+	Format string `form:"format" binding:"omitempty,oneof=png svg"`
+	Size   *int   `form:"size" binding:"omitempty,min=64,max=2048"`
+	Ecc    string `form:"ecc" binding:"omitempty,oneof=L M Q H"`
+	Margin *int   `form:"margin" binding:"omitempty,min=0,max=16"`
+	// UtmSource is added to the encoded URL, so that scans are counted
+	// apart from other clicks.
+	UtmSource string `form:"utm_source" binding:"omitempty,max=100"`
+}
+
+// GetShortUrlQrCode godoc
+// @Summary      Get a QR code for a short URL
+// @Description  Get a QR code that encodes the same URL as the short_url of the short URL, as a PNG or SVG image. With utm_source (e.g. qr), the URL is tagged with it, and clicks from scanning the code are counted under that source in the sources breakdown.
+// @Tags         shorturls
+// @Produce      png
+// @Produce      image/svg+xml
+// @Param        slug        path      string  true   "slug of short URL to get a QR code for"
+// @Param        format      query     string  false  "image format"                                      Enums(png, svg)     default(png)
+// @Param        size        query     int     false  "width and height of the image, in pixels"          minimum(64)         maximum(2048)  default(256)
+// @Param        ecc         query     string  false  "error correction level"                            Enums(L, M, Q, H)   default(M)
+// @Param        margin      query     int     false  "width of the quiet zone around the code, in modules"  minimum(0)    maximum(16)    default(4)
+// @Param        utm_source  query     string  false  "utm_source to add to the encoded URL"
+// @Success      200         {file}    binary
+// @Failure      400         {object}  e.ErrorResponse
+// @Failure      404
+// @Failure      500
+// @Router       /shorturls/{slug}/qr [get]
+func (controller *GetShortUrlQrCodeController) HandleRequest(c *gin.Context, request GetShortUrlQrCodeRequest) {
+	slug := c.Param("slug")
+
+	shortUrl, err := controller.ShortUrls.FindBySlug(slug)
+
+	if err != nil {
+		status := http.StatusInternalServerError
+
+		if errors.Is(err, repositories.ErrNotFound) {
+			status = http.StatusNotFound
+		}
+
+		c.Writer.WriteHeader(status)
+		return
+	}
+
+	link := shortUrlResponseHelper{Host: c.Request.Host, ShortUrl: shortUrl}.link()
+
+	if request.UtmSource != "" {
+		link.RawQuery = url.Values{"utm_source": {request.UtmSource}}.Encode()
+	}
+
+	options, contentType := parseQrCodeOptions(request)
+
+	code, err := services.RenderQrCode(link.String(), options)
+
+	if err != nil {
+		log.Printf("encountered error rendering qr code for %s: %v", slug, err)
+		c.Writer.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	c.Data(http.StatusOK, contentType, code)
+}
+
+func (controller *GetShortUrlQrCodeController) Register(r *gin.Engine) {
+	r.GET("/api/v1/shorturls/:slug/qr", middleware.ModelBindingWrapper[GetShortUrlQrCodeRequest](controller))
+}
+
+func parseQrCodeOptions(request GetShortUrlQrCodeRequest) (services.QrCodeOptions, string) {
+	options := services.QrCodeOptions{
+		Format:          enums.QrCodeFormatPng,
+		ErrorCorrection: enums.QrCodeErrorCorrectionMedium,
+		Size:            defaultQrCodeSize,
+		Margin:          defaultQrCodeMargin,
+	}
+
+	contentType := "image/png"
+
+	if request.Format == "svg" {
+		options.Format = enums.QrCodeFormatSvg
+		contentType = "image/svg+xml"
+	}
+
+	if request.Ecc != "" {
+		options.ErrorCorrection = map[string]enums.QrCodeErrorCorrection{
+			"L": enums.QrCodeErrorCorrectionLow,
+			"M": enums.QrCodeErrorCorrectionMedium,
+			"Q": enums.QrCodeErrorCorrectionQuartile,
+			"H": enums.QrCodeErrorCorrectionHigh,
+		}[request.Ecc]
+	}
+
+	if request.Size != nil {
+		options.Size = *request.Size
+	}
+
+	if request.Margin != nil {
+		options.Margin = *request.Margin
+	}
+
+	return options, contentType
+}
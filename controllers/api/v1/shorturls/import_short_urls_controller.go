@@ -0,0 +1,97 @@
+package shorturls
+
+import (
+	"net/http"
+	"url-shortener/enums"
+	"url-shortener/middleware"
+	"url-shortener/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ImportShortUrlsController struct {
+	ImportShortUrlsService *services.ImportShortUrlsService
+}
+
+type ImportShortUrlsRequest struct {
+	Format     string `form:"format"      binding:"omitempty,oneof=csv jsonl"`
+	OnConflict string `form:"on_conflict" binding:"omitempty,oneof=skip overwrite fail"`
+	DryRun     bool   `form:"dry_run"`
+}
+
+type ImportShortUrlsResponse struct {
+	DryRun    bool                      `json:"dry_run"`
+	Committed bool                      `json:"committed"`
+	Created   int                       `json:"created"`
+	Updated   int                       `json:"updated"`
+	Skipped   int                       `json:"skipped"`
+	Errors    []services.ImportRowError `json:"errors"`
+}
+
+// ImportShortUrls godoc
+// @Summary      Import short URLs
+// @Description  Import short URLs from the request body, in the same CSV or JSON Lines format as the export. The whole import is applied in a single transaction: if any row is invalid, or a conflict occurs with on_conflict=fail, nothing is imported. on_conflict decides what happens when a row's slug or long URL is already used: skip the row, overwrite the existing short URL, or fail the import. With dry_run=true, the import is checked and counted but nothing is kept.
+// @Tags         shorturls
+// @Accept       text/csv
+// @Accept       application/x-ndjson
+// @Produce      json
+// @Security     BearerAuth
+// @Param        format       query     string   false  "format of the request body"                      Enums(csv, jsonl)  default(csv)
+// @Param        on_conflict  query     string   false  "what to do with rows that clash with existing short URLs"  Enums(skip, overwrite, fail)  default(fail)
+// @Param        dry_run      query     boolean  false  "report what the import would do without keeping it"
+// @Success      200          {object}  ImportShortUrlsResponse
+// @Failure      400          {object}  e.ErrorResponse
+// @Failure      401          {object}  e.ErrorResponse
+// @Failure      409          {object}  ImportShortUrlsResponse
+// @Failure      422          {object}  ImportShortUrlsResponse
+// @Failure      500
+// @Router       /shorturls/import [post]
+func (controller *ImportShortUrlsController) HandleRequest(c *gin.Context, request ImportShortUrlsRequest) {
+	format, _, _ := parseRecordFormat(request.Format)
+
+	options := services.ImportOptions{
+		DryRun: request.DryRun,
+	}
+
+	switch request.OnConflict {
+	case "skip":
+		options.ConflictStrategy = enums.ConflictStrategySkip
+	case "overwrite":
+		options.ConflictStrategy = enums.ConflictStrategyOverwrite
+	default:
+		options.ConflictStrategy = enums.ConflictStrategyFail
+	}
+
+	result := controller.ImportShortUrlsService.Import(
+		middleware.GetPrincipal(c),
+		middleware.GetOrigin(c),
+		services.NewShortUrlRecordReader(c.Request.Body, format),
+		options,
+	)
+
+	if result.Error != nil {
+		c.Writer.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	status := http.StatusOK
+
+	if result.Conflict {
+		status = http.StatusConflict
+	} else if len(result.Errors) > 0 {
+		status = http.StatusUnprocessableEntity
+	}
+
+	c.JSON(status, ImportShortUrlsResponse{
+		DryRun:    request.DryRun,
+		Committed: result.Committed,
+		Created:   result.Created,
+		Updated:   result.Updated,
+		Skipped:   result.Skipped,
+		Errors:    result.Errors,
+	})
+}
+
+func (controller *ImportShortUrlsController) Register(r *gin.Engine) {
+	r.POST("/api/v1/shorturls/import", middleware.QueryBindingWrapper[ImportShortUrlsRequest](controller))
+}
@@ -2,49 +2,165 @@ package shorturls
 
 import (
 	"net/http"
-	"url-shortener/models"
+	"time"
+	"url-shortener/e"
+	"url-shortener/enums"
+	"url-shortener/middleware"
+	"url-shortener/services"
 
 	"github.com/gin-gonic/gin"
-	"gorm.io/gorm"
 )
 
+const defaultListLimit = 50
+
 type ListShortUrlsController struct {
-	DB *gorm.DB
+	ListShortUrlsService *services.ListShortUrlsService
+	Clock                services.Clock
+}
+
+type ListShortUrlsRequest struct {
+	Owner           string    `form:"owner"             binding:"omitempty,oneof=me"`
+	Limit           int       `form:"limit"             binding:"omitempty,min=1,max=100"`
+	Cursor          string    `form:"cursor"`
+	Namespace       string    `form:"namespace"`
+	SlugPrefix      string    `form:"slug_prefix"`
+	LongUrlContains string    `form:"long_url_contains"`
+	CreatedAfter    time.Time `form:"created_after"`
+	CreatedBefore   time.Time `form:"created_before"`
+	ExpiresBefore   time.Time `form:"expires_before"`
+	HasExpiry       *bool     `form:"has_expiry"`
+	Sort            string    `form:"sort"              binding:"omitempty,oneof=created_at slug clicks"`
+	Order           string    `form:"order"             binding:"omitempty,oneof=asc desc"`
+}
+
+type ListShortUrlsResponse struct {
+	ShortUrls []ShortUrlResponse `json:"short_urls"`
+	// NextCursor is null on the last page.
+	NextCursor *string `json:"next_cursor" example:"eyJzIjowLCJvIjowLCJjIjoiMjAyMi0wNS0xMVQxMTozMDowMFoiLCJpZCI6NTB9"`
 }
 
 // ListShortUrls  godoc
-// @Summary      List all short URLs
-// @Description  List all short URLs
+// @Summary      List short URLs
+// @Description  List short URLs a page at a time, optionally filtered and sorted. Pass the next_cursor from a response as the cursor of the next request (with the same filters and sort) to get the following page; next_cursor is null on the last page. With owner=me, only the short URLs owned by the caller are listed.
 // @Tags         shorturls
 // @Accept       json
 // @Produce      json
-// @Success      200  {array}  models.ShortUrlReadFields
+// @Security     BearerAuth
+// @Param        owner              query     string   false  "only list short URLs owned by the caller"                     Enums(me)
+// @Param        limit              query     int      false  "maximum number of short URLs to return"                       minimum(1) maximum(100) default(50)
+// @Param        cursor             query     string   false  "next_cursor from the previous page"
+// @Param        namespace          query     string   false  "only list short URLs in the namespace with this prefix"
+// @Param        slug_prefix        query     string   false  "only list short URLs whose slug starts with this (after the namespace's prefix, with namespace)"
+// @Param        long_url_contains  query     string   false  "only list short URLs whose long URL contains this (ignoring case)"
+// @Param        created_after      query     string   false  "only list short URLs created after this time, in RFC 3339 format"  format(dateTime)
+// @Param        created_before     query     string   false  "only list short URLs created before this time, in RFC 3339 format"  format(dateTime)
+// @Param        expires_before     query     string   false  "only list short URLs expiring before this time, in RFC 3339 format"  format(dateTime)
+// @Param        has_expiry         query     boolean  false  "only list short URLs with (true) or without (false) an expiration date"
+// @Param        sort               query     string   false  "field to sort by"                                             Enums(created_at, slug, clicks)  default(created_at)
+// @Param        order              query     string   false  "sort order"                                                   Enums(asc, desc)  default(asc)
+// @Success      200                {object}  ListShortUrlsResponse
+// @Failure      400                {object}  e.ErrorResponse
+// @Failure      401                {object}  e.ErrorResponse
 // @Failure      500
 // @Router       /shorturls [get]
-func (controller *ListShortUrlsController) HandleRequest(c *gin.Context) {
-	var allShortUrls []models.ShortUrl
+func (controller *ListShortUrlsController) HandleRequest(c *gin.Context, request ListShortUrlsRequest) {
+	controller.list(c, request, false)
+}
+
+// list lists either the short URLs in use or, if deleted is set, the ones in
+// the trash.
+func (controller *ListShortUrlsController) list(c *gin.Context, request ListShortUrlsRequest, deleted bool) {
+	query := services.ListShortUrlsQuery{
+		Namespace:       request.Namespace,
+		SlugPrefix:      request.SlugPrefix,
+		LongUrlContains: request.LongUrlContains,
+		CreatedAfter:    request.CreatedAfter,
+		CreatedBefore:   request.CreatedBefore,
+		ExpiresBefore:   request.ExpiresBefore,
+		HasExpiry:       request.HasExpiry,
+		Deleted:         deleted,
+		Limit:           request.Limit,
+		Cursor:          request.Cursor,
+	}
+
+	if query.Limit == 0 {
+		query.Limit = defaultListLimit
+	}
+
+	switch request.Sort {
+	case "slug":
+		query.Sort = enums.ShortUrlSortSlug
+	case "clicks":
+		query.Sort = enums.ShortUrlSortClicks
+	default:
+		query.Sort = enums.ShortUrlSortCreatedAt
+	}
+
+	if request.Order == "desc" {
+		query.Order = enums.SortOrderDescending
+	}
 
-	listResult := controller.DB.
-		Order("created_at ASC").
-		Find(&allShortUrls)
+	if request.Owner == "me" {
+		principal := middleware.GetPrincipal(c)
 
-	var jsonResults []shortUrlResponseHelper
+		if principal == nil {
+			middleware.AbortUnauthorized(c, "required to list your own short urls")
+			return
+		}
+
+		query.OwnerId = &principal.OwnerId
+	}
+
+	result := controller.ListShortUrlsService.List(query)
+
+	if result.Error != nil {
+		c.Writer.WriteHeader(http.StatusInternalServerError)
+		return
+	}
 
-	if listResult.Error == nil {
-		for _, shortUrl := range allShortUrls {
-			jsonResults = append(jsonResults, shortUrlResponseHelper{
+	var status int
+	var body interface{}
+
+	switch result.Status {
+	case enums.ListResultSuccessful:
+		response := ListShortUrlsResponse{
+			ShortUrls: []ShortUrlResponse{},
+		}
+
+		now := controller.Clock.Now()
+
+		for _, shortUrl := range result.Records {
+			response.ShortUrls = append(response.ShortUrls, shortUrlResponseHelper{
 				Host:     c.Request.Host,
+				Now:      now,
 				ShortUrl: shortUrl,
-			})
+			}.response())
 		}
 
-		c.JSON(http.StatusOK, jsonResults)
+		if result.NextCursor != "" {
+			response.NextCursor = &result.NextCursor
+		}
+
+		status = http.StatusOK
+		body = response
+	case enums.ListResultInvalidCursor:
+		status = http.StatusBadRequest
+		body = e.ErrorResponse{
+			Errors: []e.ValidationError{
+				{
+					Field:  "Cursor",
+					Reason: "invalid",
+				},
+			},
+		}
+	default:
+		c.Writer.WriteHeader(http.StatusInternalServerError)
 		return
 	}
 
-	c.Writer.WriteHeader(http.StatusInternalServerError)
+	c.JSON(status, body)
 }
 
 func (controller *ListShortUrlsController) Register(r *gin.Engine) {
-	r.GET("/api/v1/shorturls", controller.HandleRequest)
+	r.GET("/api/v1/shorturls", middleware.ModelBindingWrapper[ListShortUrlsRequest](controller))
 }
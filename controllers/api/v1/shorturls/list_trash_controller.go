@@ -0,0 +1,45 @@
+package shorturls
+
+import (
+	"url-shortener/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ListTrashController lists the deleted short URLs that haven't been purged
+// yet, the same way ListShortUrlsController lists the others.
+type ListTrashController struct {
+	ListShortUrlsController
+}
+
+// ListTrash  godoc
+// @Summary      List deleted short URLs
+// @Description  List the short URLs in the trash, which can be restored until they are purged. Their slugs can't be used by other short URLs until then. Filters, sorting, and pagination work the same way as when listing short URLs, but cursors from one list can't be used with the other.
+// @Tags         shorturls
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        owner              query     string   false  "only list short URLs owned by the caller"                     Enums(me)
+// @Param        limit              query     int      false  "maximum number of short URLs to return"                       minimum(1) maximum(100) default(50)
+// @Param        cursor             query     string   false  "next_cursor from the previous page"
+// @Param        namespace          query     string   false  "only list short URLs in the namespace with this prefix"
+// @Param        slug_prefix        query     string   false  "only list short URLs whose slug starts with this (after the namespace's prefix, with namespace)"
+// @Param        long_url_contains  query     string   false  "only list short URLs whose long URL contains this (ignoring case)"
+// @Param        created_after      query     string   false  "only list short URLs created after this time, in RFC 3339 format"  format(dateTime)
+// @Param        created_before     query     string   false  "only list short URLs created before this time, in RFC 3339 format"  format(dateTime)
+// @Param        expires_before     query     string   false  "only list short URLs expiring before this time, in RFC 3339 format"  format(dateTime)
+// @Param        has_expiry         query     boolean  false  "only list short URLs with (true) or without (false) an expiration date"
+// @Param        sort               query     string   false  "field to sort by"                                             Enums(created_at, slug, clicks)  default(created_at)
+// @Param        order              query     string   false  "sort order"                                                   Enums(asc, desc)  default(asc)
+// @Success      200                {object}  ListShortUrlsResponse
+// @Failure      400                {object}  e.ErrorResponse
+// @Failure      401                {object}  e.ErrorResponse
+// @Failure      500
+// @Router       /shorturls/trash [get]
+func (controller *ListTrashController) HandleRequest(c *gin.Context, request ListShortUrlsRequest) {
+	controller.list(c, request, true)
+}
+
+func (controller *ListTrashController) Register(r *gin.Engine) {
+	r.GET("/api/v1/shorturls/trash", middleware.ModelBindingWrapper[ListShortUrlsRequest](controller))
+}
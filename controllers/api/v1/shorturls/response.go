@@ -3,27 +3,62 @@ package shorturls
 import (
 	"encoding/json"
 	"net/url"
+	"time"
 	"url-shortener/models"
+	"url-shortener/services"
+)
+
+const (
+	shortUrlStatusActive  = "active"
+	shortUrlStatusExpired = "expired"
+	shortUrlStatusDeleted = "deleted"
 )
 
 type shortUrlResponseHelper struct {
 	Host string
+	// Now is when the response is made, which decides whether the short URL
+	// has expired.
+	Now time.Time
 	models.ShortUrl
 }
 type ShortUrlResponse struct {
 	ShortUrl string `json:"short_url"`
+	// Status is expired once the short URL has stopped redirecting, until
+	// it's deleted at the end of its grace period, and deleted while it's in
+	// the trash.
+	Status string `json:"status" enums:"active,expired,deleted" example:"active"`
 	models.ShortUrlReadFields
+	// DeletedAt is when the short URL was moved to the trash.
+	DeletedAt *time.Time `json:"deleted_at,omitempty" format:"dateTime" example:"2022-05-12T09:00:00Z"`
 }
 
 func (r shortUrlResponseHelper) MarshalJSON() ([]byte, error) {
-	u := url.URL{
+	return json.Marshal(r.response())
+}
+
+// link is the absolute URL that the short URL is shared as.
+func (r shortUrlResponseHelper) link() *url.URL {
+	return &url.URL{
 		Scheme: "http",
 		Host:   r.Host,
 		Path:   r.Slug,
 	}
+}
 
-	return json.Marshal(ShortUrlResponse{
-		ShortUrl:           u.String(),
+func (r shortUrlResponseHelper) response() ShortUrlResponse {
+	response := ShortUrlResponse{
+		ShortUrl:           r.link().String(),
+		Status:             shortUrlStatusActive,
 		ShortUrlReadFields: r.ShortUrl.ShortUrlReadFields,
-	})
+	}
+
+	switch {
+	case r.DeletedAt.Valid:
+		response.Status = shortUrlStatusDeleted
+		response.DeletedAt = &r.DeletedAt.Time
+	case services.IsExpired(r.ShortUrl, r.Now):
+		response.Status = shortUrlStatusExpired
+	}
+
+	return response
 }
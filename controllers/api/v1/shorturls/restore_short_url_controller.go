@@ -0,0 +1,83 @@
+package shorturls
+
+import (
+	"net/http"
+	"url-shortener/e"
+	"url-shortener/enums"
+	"url-shortener/middleware"
+	"url-shortener/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type RestoreShortUrlController struct {
+	RestoreShortUrlService *services.RestoreShortUrlService
+	Clock                  services.Clock
+}
+
+// RestoreShortUrl  godoc
+// @Summary      Restore a deleted short URL
+// @Description  Take a short URL out of the trash, along with the statistics it had when it was deleted. Only the owner of the short URL or an admin may restore it. A short URL can't be restored if its long URL has been shortened again since it was deleted.
+// @Tags         shorturls
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        slug  path      string  true  "slug of deleted short URL to restore"
+// @Success      200   {object}  ShortUrlResponse
+// @Failure      401   {object}  e.ErrorResponse
+// @Failure      403   {object}  e.ErrorResponse
+// @Failure      404   {object}  e.ErrorResponse
+// @Failure      409   {object}  e.ErrorResponse
+// @Failure      500
+// @Router       /shorturls/{slug}/restore [post]
+func (controller *RestoreShortUrlController) HandleRequest(c *gin.Context) {
+	slug := c.Param("slug")
+	result := controller.RestoreShortUrlService.Restore(middleware.GetPrincipal(c), middleware.GetOrigin(c), slug)
+
+	if result.Error != nil {
+		c.Writer.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	switch result.Status {
+	case enums.RestoreResultSuccessful:
+		c.JSON(http.StatusOK, shortUrlResponseHelper{
+			Host:     c.Request.Host,
+			Now:      controller.Clock.Now(),
+			ShortUrl: *result.Record,
+		})
+	case enums.RestoreResultNotFound:
+		c.JSON(http.StatusNotFound, e.ErrorResponse{
+			Errors: []e.ValidationError{
+				{
+					Field:  "Slug",
+					Reason: "not found in the trash",
+				},
+			},
+		})
+	case enums.RestoreResultForbidden:
+		c.JSON(http.StatusForbidden, e.ErrorResponse{
+			Errors: []e.ValidationError{
+				{
+					Field:  "Authorization",
+					Reason: "not allowed to modify this short url",
+				},
+			},
+		})
+	case enums.RestoreResultDuplicateLongUrl:
+		c.JSON(http.StatusConflict, e.ErrorResponse{
+			Errors: []e.ValidationError{
+				{
+					Field:  "LongUrl",
+					Reason: "has been shortened again since this short url was deleted",
+				},
+			},
+		})
+	default:
+		c.Writer.WriteHeader(http.StatusInternalServerError)
+	}
+}
+
+func (controller *RestoreShortUrlController) Register(r *gin.Engine) {
+	r.POST("/api/v1/shorturls/:slug/restore", controller.HandleRequest)
+}
@@ -0,0 +1,161 @@
+package shorturls
+
+import (
+	"net/http"
+	"url-shortener/e"
+	"url-shortener/enums"
+	"url-shortener/middleware"
+	"url-shortener/models"
+	"url-shortener/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type UpdateShortUrlController struct {
+	UpdateShortUrlService *services.UpdateShortUrlService
+	Clock                 services.Clock
+}
+
+// UpdateShortUrl godoc
+// @Summary      Update an existing short URL
+// @Description  Update the long URL, slug, expiration date, and/or redirect settings of an existing short URL. Fields that are omitted are left unchanged, and an expiration date of null removes the expiration.
+// @Tags         shorturls
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        slug      path      string                       true  "slug of short URL to update"
+// @Param        shorturl  body      models.ShortUrlUpdateFields  true  "Short URL fields to update"
+// @Success      200       {object}  ShortUrlResponse
+// @Failure      400       {object}  e.ErrorResponse
+// @Failure      401       {object}  e.ErrorResponse
+// @Failure      403       {object}  e.ErrorResponse
+// @Failure      404       {object}  e.ErrorResponse
+// @Failure      409       {object}  e.ErrorResponse
+// @Failure      500
+// @Router       /shorturls/{slug} [patch]
+func (controller *UpdateShortUrlController) HandleRequest(c *gin.Context, request models.ShortUrlUpdateFields) {
+	slug := c.Param("slug")
+	updateResult := controller.UpdateShortUrlService.Update(middleware.GetPrincipal(c), middleware.GetOrigin(c), slug, request)
+
+	if updateResult.Error != nil {
+		c.Writer.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	var status int
+	var body interface{}
+
+	switch updateResult.Status {
+	case enums.UpdateResultSuccessful:
+		status = http.StatusOK
+		body = shortUrlResponseHelper{
+			Host:     c.Request.Host,
+			Now:      controller.Clock.Now(),
+			ShortUrl: *updateResult.Record,
+		}
+	case enums.UpdateResultNotFound:
+		status = http.StatusNotFound
+		body = e.ErrorResponse{
+			Errors: []e.ValidationError{
+				{
+					Field:  "Slug",
+					Reason: "not found",
+				},
+			},
+		}
+	case enums.UpdateResultForbidden:
+		status = http.StatusForbidden
+		body = e.ErrorResponse{
+			Errors: []e.ValidationError{
+				{
+					Field:  "Authorization",
+					Reason: "not allowed to modify this short url",
+				},
+			},
+		}
+	case enums.UpdateResultDuplicateSlug:
+		status = http.StatusConflict
+		body = e.ErrorResponse{
+			Errors: []e.ValidationError{
+				{
+					Field:  "Slug",
+					Reason: "must be unique",
+				},
+			},
+		}
+	case enums.UpdateResultDuplicateLongUrl:
+		status = http.StatusConflict
+		body = e.ErrorResponse{
+			Errors: []e.ValidationError{
+				{
+					Field:  "LongUrl",
+					Reason: "must be unique",
+				},
+			},
+		}
+	case enums.UpdateResultInvalidLongUrl:
+		status = http.StatusBadRequest
+		body = e.ErrorResponse{
+			Errors: []e.ValidationError{
+				{
+					Field:  "LongUrl",
+					Reason: "only http and https are supported",
+				},
+			},
+		}
+	case enums.UpdateResultInvalidTemplate:
+		status = http.StatusBadRequest
+		body = e.ErrorResponse{
+			Errors: []e.ValidationError{
+				{
+					Field:  "LongUrl",
+					Reason: "must be an http or https url with placeholders only in its path, query string, or fragment",
+				},
+			},
+		}
+	case enums.UpdateResultInvalidFallbackUrl:
+		status = http.StatusBadRequest
+		body = e.ErrorResponse{
+			Errors: []e.ValidationError{
+				{
+					Field:  "FallbackUrl",
+					Reason: "only http and https are supported",
+				},
+			},
+		}
+	case enums.UpdateResultInvalidSlug:
+		status = http.StatusBadRequest
+		body = e.ErrorResponse{
+			Errors: updateResult.ValidationErrors,
+		}
+	case enums.UpdateResultSlugOutsideNamespace:
+		status = http.StatusBadRequest
+		body = e.ErrorResponse{
+			Errors: []e.ValidationError{
+				{
+					Field:  "Slug",
+					Reason: "must be in a namespace to contain a slash",
+				},
+			},
+		}
+	case enums.UpdateResultForbiddenSlug:
+		status = http.StatusForbidden
+		body = e.ErrorResponse{
+			Errors: []e.ValidationError{
+				{
+					Field:  "Authorization",
+					Reason: "not allowed to use this namespace",
+				},
+			},
+		}
+	default:
+		c.Writer.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(status, body)
+}
+
+func (controller *UpdateShortUrlController) Register(r *gin.Engine) {
+	r.PATCH("/api/v1/shorturls/:slug", middleware.ModelBindingWrapper[models.ShortUrlUpdateFields](controller))
+}
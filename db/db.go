@@ -18,22 +18,49 @@ func ConnectDatabase(sqlDB *sql.DB) (*gorm.DB, error) {
 		return db, err
 	}
 
-	db.AutoMigrate(&models.ShortUrl{}, models.Click{})
-
-	return db, err
+	return db, migrate(db)
 }
 
 func ConnectDatabaseWithoutMigrating(sqlDB *sql.DB) (*gorm.DB, error) {
 	db, err := gorm.Open(postgres.New(postgres.Config{
 		Conn: sqlDB,
-	}), &gorm.Config{
+	}), gormConfig())
+
+	return db, err
+}
+
+func migrate(db *gorm.DB) error {
+	err := db.AutoMigrate(&models.ShortUrl{}, models.Click{}, models.ClickRollup{}, models.ApiKey{}, models.Namespace{}, models.AuditEvent{})
+
+	if err != nil {
+		return err
+	}
+
+	// Long URLs used to be unique by themselves, before short URLs could be
+	// tagged with UTM parameters, then unique along with their UTM parameters
+	// even once they'd been deleted, and then until they'd been deleted.
+	// uq_short_urls_current_destination replaces the indexes that enforced
+	// any of these.
+	for _, index := range []string{"uq_short_urls_long_url", "uq_short_urls_destination", "uq_short_urls_live_destination"} {
+		if !db.Migrator().HasIndex(&models.ShortUrl{}, index) {
+			continue
+		}
+
+		if err := db.Migrator().DropIndex(&models.ShortUrl{}, index); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func gormConfig() *gorm.Config {
+	return &gorm.Config{
 		Logger: logger.Default.LogMode(logger.Info),
 		NowFunc: func() time.Time {
 			return time.Now().Truncate(time.Microsecond)
 		},
-	})
-
-	return db, err
+	}
 }
 
 type UniqueConstraintName int
@@ -42,16 +69,18 @@ const (
 	None UniqueConstraintName = iota
 	DuplicateLongUrl
 	DuplicateSlug
+	DuplicatePrefix
 )
 
 func (u UniqueConstraintName) String() string {
-	return []string{"uq_short_urls_long_url", "uq_short_urls_slug"}[u]
+	return []string{"", "uq_short_urls_current_destination", "uq_short_urls_slug", "uq_namespaces_prefix"}[u]
 }
 
 func ParseString(s string) UniqueConstraintName {
 	constraintsMap := map[string]UniqueConstraintName{
-		"uq_short_urls_long_url": DuplicateLongUrl,
-		"uq_short_urls_slug":     DuplicateSlug,
+		"uq_short_urls_current_destination": DuplicateLongUrl,
+		"uq_short_urls_slug":                DuplicateSlug,
+		"uq_namespaces_prefix":              DuplicatePrefix,
 	}
 
 	u, ok := constraintsMap[s]
@@ -0,0 +1,144 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// ConnectSqlite opens (creating it if needed) and migrates the SQLite
+// database at path, which may be ":memory:" for a database that only lasts as
+// long as the process.
+func ConnectSqlite(path string) (*gorm.DB, error) {
+	// Foreign keys are off by default in SQLite, and clicks rely on them
+	// being deleted along with their short URL.
+	sqlDB, err := sql.Open(sqlite.DriverName, "file:"+path+"?_foreign_keys=on&_busy_timeout=5000")
+
+	if err != nil {
+		return nil, err
+	}
+
+	// SQLite only allows one writer at a time, and every connection to
+	// ":memory:" gets a database of its own, so a single connection is
+	// shared.
+	sqlDB.SetMaxOpenConns(1)
+
+	db, err := gorm.Open(sqliteDialector{sqlite.Dialector{
+		Conn: utcConnPool{sqlDB},
+	}}, gormConfig())
+
+	if err != nil {
+		return db, err
+	}
+
+	return db, migrate(db)
+}
+
+// sqliteDialector migrates tables that already exist like gorm does, except
+// that it trusts the models about which columns are nullable. go-sqlite3
+// reports every column as nullable, so gorm would otherwise try to rebuild
+// any table with a NOT NULL column each time the database is opened, which
+// the driver fails to do.
+type sqliteDialector struct {
+	sqlite.Dialector
+}
+
+func (d sqliteDialector) Migrator(db *gorm.DB) gorm.Migrator {
+	return sqliteMigrator{d.Dialector.Migrator(db).(sqlite.Migrator)}
+}
+
+type sqliteMigrator struct {
+	sqlite.Migrator
+}
+
+func (m sqliteMigrator) MigrateColumn(value interface{}, field *schema.Field, columnType gorm.ColumnType) error {
+	return m.Migrator.MigrateColumn(value, field, fieldNullability{columnType, field})
+}
+
+// fieldNullability reports a column as nullable exactly when its field is.
+type fieldNullability struct {
+	// columnType is embedded under another name, since gorm.ColumnType has a
+	// method called ColumnType.
+	columnType
+	field *schema.Field
+}
+
+type columnType = gorm.ColumnType
+
+func (c fieldNullability) Nullable() (bool, bool) {
+	return !c.field.NotNull, true
+}
+
+// utcConnPool binds every time in UTC. SQLite stores times as text, which
+// only compares and sorts correctly if every time has the same offset.
+type utcConnPool struct {
+	*sql.DB
+}
+
+func (p utcConnPool) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return p.DB.ExecContext(ctx, query, inUtc(args)...)
+}
+
+func (p utcConnPool) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return p.DB.QueryContext(ctx, query, inUtc(args)...)
+}
+
+func (p utcConnPool) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	return p.DB.QueryRowContext(ctx, query, inUtc(args)...)
+}
+
+func (p utcConnPool) BeginTx(ctx context.Context, opts *sql.TxOptions) (gorm.ConnPool, error) {
+	tx, err := p.DB.BeginTx(ctx, opts)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &utcTx{tx}, nil
+}
+
+func (p utcConnPool) GetDBConn() (*sql.DB, error) {
+	return p.DB, nil
+}
+
+type utcTx struct {
+	*sql.Tx
+}
+
+func (tx *utcTx) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return tx.Tx.ExecContext(ctx, query, inUtc(args)...)
+}
+
+func (tx *utcTx) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return tx.Tx.QueryContext(ctx, query, inUtc(args)...)
+}
+
+func (tx *utcTx) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	return tx.Tx.QueryRowContext(ctx, query, inUtc(args)...)
+}
+
+func inUtc(args []interface{}) []interface{} {
+	converted := make([]interface{}, len(args))
+
+	for i, arg := range args {
+		converted[i] = arg
+
+		// Nullable times, such as null.Time, are valuers.
+		if valuer, ok := arg.(driver.Valuer); ok {
+			if value, err := valuer.Value(); err == nil {
+				arg = value
+			}
+		}
+
+		if t, ok := arg.(time.Time); ok {
+			converted[i] = t.UTC()
+		}
+	}
+
+	return converted
+}
@@ -16,9 +16,74 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/shorturls": {
+        "/apikeys": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Create a new API key for an owner. Only admins may create API keys. The key is returned in the response and can't be retrieved again.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "apikeys"
+                ],
+                "summary": "Create a new API key",
+                "parameters": [
+                    {
+                        "description": "New API key parameters",
+                        "name": "apikey",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiKeyCreateFields"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/apikeys.CreateApiKeyResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/e.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/e.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/e.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": ""
+                    }
+                }
+            }
+        },
+        "/audit": {
             "get": {
-                "description": "List all short URLs",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List the changes made to short URLs, newest first, a page at a time. Every creation, update, deletion, and restoration is recorded with who made it, the ID and source IP address of the request, and snapshots of the short URL before and after, as are the short URLs that expire or are purged from the trash. Only admins may read the audit log. Pass the next_cursor from a response as the cursor of the next request (with the same filters) to get the following page; next_cursor is null on the last page.",
                 "consumes": [
                     "application/json"
                 ],
@@ -26,17 +91,121 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "shorturls"
+                    "audit"
+                ],
+                "summary": "List audit events",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "only list events about short URLs with this slug at the time",
+                        "name": "slug",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "only list events by this owner",
+                        "name": "actor",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "create",
+                            "update",
+                            "delete",
+                            "restore",
+                            "expire",
+                            "purge"
+                        ],
+                        "type": "string",
+                        "description": "only list events with this action",
+                        "name": "action",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "only list events made by the request with this X-Request-Id",
+                        "name": "request_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "format": "dateTime",
+                        "description": "only list events after this time, in RFC 3339 format",
+                        "name": "created_after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "format": "dateTime",
+                        "description": "only list events before this time, in RFC 3339 format",
+                        "name": "created_before",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 100,
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 50,
+                        "description": "maximum number of events to return",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor from the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/audit.ListAuditEventsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/e.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/e.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/e.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": ""
+                    }
+                }
+            }
+        },
+        "/namespaces": {
+            "get": {
+                "description": "List every namespace in order of prefix.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
                 ],
-                "summary": "List all short URLs",
+                "tags": [
+                    "namespaces"
+                ],
+                "summary": "List namespaces",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.ShortUrlReadFields"
-                            }
+                            "$ref": "#/definitions/namespaces.ListNamespacesResponse"
                         }
                     },
                     "500": {
@@ -45,7 +214,12 @@ const docTemplate = `{
                 }
             },
             "post": {
-                "description": "Create a new short url. Users may specify a slug and an expiration date. If a slug is not supplied, an 8 character slug will automatically be generated for the short url.",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Reserve the short URLs under a prefix, such as eng/oncall under eng, for an owner. Only admins may create namespaces. Prefixes follow the same rules as slugs, and can themselves contain slashes.",
                 "consumes": [
                     "application/json"
                 ],
@@ -53,31 +227,25 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "shorturls"
+                    "namespaces"
                 ],
-                "summary": "Create a new short url",
+                "summary": "Create a new namespace",
                 "parameters": [
                     {
-                        "description": "New short URL parameters",
-                        "name": "shorturl",
+                        "description": "New namespace parameters",
+                        "name": "namespace",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.ShortUrlCreateFields"
+                            "$ref": "#/definitions/models.NamespaceCreateFields"
                         }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/models.ShortUrlReadFields"
-                        }
-                    },
                     "201": {
                         "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/models.ShortUrlReadFields"
+                            "$ref": "#/definitions/models.Namespace"
                         }
                     },
                     "400": {
@@ -86,8 +254,17 @@ const docTemplate = `{
                             "$ref": "#/definitions/e.ErrorResponse"
                         }
                     },
-                    "404": {
-                        "description": ""
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/e.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/e.ErrorResponse"
+                        }
                     },
                     "409": {
                         "description": "Conflict",
@@ -101,9 +278,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/shorturls/{slug}": {
-            "get": {
-                "description": "Get information about an existing short URL",
+        "/namespaces/{prefix}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Delete a namespace. Only its owner or an admin may. The short URLs in it keep working, but no more can be created in it until it's created again. Slashes in the prefix must be escaped as %2F.",
                 "consumes": [
                     "application/json"
                 ],
@@ -111,23 +293,32 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "shorturls"
+                    "namespaces"
                 ],
-                "summary": "Get information about an existing short URL",
+                "summary": "Delete an existing namespace",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "slug of short URL to get information about",
-                        "name": "slug",
+                        "description": "prefix of namespace to delete",
+                        "name": "prefix",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "204": {
+                        "description": ""
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/e.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
-                            "$ref": "#/definitions/models.ShortUrlReadFields"
+                            "$ref": "#/definitions/e.ErrorResponse"
                         }
                     },
                     "404": {
@@ -141,8 +332,13 @@ const docTemplate = `{
                     }
                 }
             },
-            "delete": {
-                "description": "Delete an existing short URL by supplying the slug.",
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Hand a namespace over to a new owner. Only its owner or an admin may. Slashes in the prefix must be escaped as %2F.",
                 "consumes": [
                     "application/json"
                 ],
@@ -150,21 +346,51 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "shorturls"
+                    "namespaces"
                 ],
-                "summary": "Delete an existing short URL",
+                "summary": "Update an existing namespace",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "slug of short URL to delete",
-                        "name": "slug",
+                        "description": "prefix of namespace to update",
+                        "name": "prefix",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "Namespace fields to update",
+                        "name": "namespace",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.NamespaceUpdateFields"
+                        }
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": ""
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Namespace"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/e.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/e.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/e.ErrorResponse"
+                        }
                     },
                     "404": {
                         "description": "Not Found",
@@ -178,9 +404,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/shorturls/{slug}/clicks": {
+        "/shorturls": {
             "get": {
-                "description": "Get clicks (statistics) for a short URL. Time periods of all time, 24 hours, and 1 week are permitted.",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List short URLs a page at a time, optionally filtered and sorted. Pass the next_cursor from a response as the cursor of the next request (with the same filters and sort) to get the following page; next_cursor is null on the last page. With owner=me, only the short URLs owned by the caller are listed.",
                 "consumes": [
                     "application/json"
                 ],
@@ -190,37 +421,116 @@ const docTemplate = `{
                 "tags": [
                     "shorturls"
                 ],
-                "summary": "Get clicks for a short URL",
+                "summary": "List short URLs",
                 "parameters": [
                     {
+                        "enum": [
+                            "me"
+                        ],
                         "type": "string",
-                        "description": "slug of short URL to retrieve statistics for",
-                        "name": "slug",
-                        "in": "path",
-                        "required": true
+                        "description": "only list short URLs owned by the caller",
+                        "name": "owner",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 100,
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 50,
+                        "description": "maximum number of short URLs to return",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor from the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "only list short URLs in the namespace with this prefix",
+                        "name": "namespace",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "only list short URLs whose slug starts with this (after the namespace's prefix, with namespace)",
+                        "name": "slug_prefix",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "only list short URLs whose long URL contains this (ignoring case)",
+                        "name": "long_url_contains",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "format": "dateTime",
+                        "description": "only list short URLs created after this time, in RFC 3339 format",
+                        "name": "created_after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "format": "dateTime",
+                        "description": "only list short URLs created before this time, in RFC 3339 format",
+                        "name": "created_before",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "format": "dateTime",
+                        "description": "only list short URLs expiring before this time, in RFC 3339 format",
+                        "name": "expires_before",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "only list short URLs with (true) or without (false) an expiration date",
+                        "name": "has_expiry",
+                        "in": "query"
                     },
                     {
                         "enum": [
-                            "24_HOURS",
-                            "1_WEEK",
-                            "ALL_TIME"
+                            "created_at",
+                            "slug",
+                            "clicks"
                         ],
                         "type": "string",
-                        "description": "time period to retrieve statistics for",
-                        "name": "time_period",
-                        "in": "query",
-                        "required": true
+                        "default": "created_at",
+                        "description": "field to sort by",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "asc",
+                            "desc"
+                        ],
+                        "type": "string",
+                        "default": "asc",
+                        "description": "sort order",
+                        "name": "order",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/clicks.GetShortUrlClicksResponse"
+                            "$ref": "#/definitions/shorturls.ListShortUrlsResponse"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/e.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/e.ErrorResponse"
                         }
@@ -229,18 +539,1678 @@ const docTemplate = `{
                         "description": ""
                     }
                 }
-            }
-        }
-    },
-    "definitions": {
-        "clicks.GetShortUrlClicksResponse": {
-            "type": "object",
-            "properties": {
-                "count": {
-                    "type": "integer"
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Create a new short url. Users may specify a slug and an expiration date. If a slug is not supplied, one will automatically be generated for the short url, and generated again if it collides with an existing slug. Short urls created with an API key are owned by the owner of that key. A slug containing a slash, such as eng/oncall, must be in a namespace, and only the namespace's owner or an admin may use it.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "shorturls"
+                ],
+                "summary": "Create a new short url",
+                "parameters": [
+                    {
+                        "description": "New short URL parameters",
+                        "name": "shorturl",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ShortUrlCreateFields"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/shorturls.ShortUrlResponse"
+                        }
+                    },
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/shorturls.ShortUrlResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/e.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/e.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/e.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": ""
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/e.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": ""
+                    }
+                }
+            }
+        },
+        "/shorturls/export": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Export short URLs along with their total number of clicks, as CSV (with a header row) or JSON Lines. Admins export every short URL, and anyone else only the short URLs they own. The export is streamed, so it starts arriving right away no matter how many short URLs there are.",
+                "produces": [
+                    "text/csv",
+                    "application/x-ndjson"
+                ],
+                "tags": [
+                    "shorturls"
+                ],
+                "summary": "Export short URLs",
+                "parameters": [
+                    {
+                        "enum": [
+                            "csv",
+                            "jsonl"
+                        ],
+                        "type": "string",
+                        "default": "csv",
+                        "description": "format of the export",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/e.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/e.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/shorturls/import": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Import short URLs from the request body, in the same CSV or JSON Lines format as the export. The whole import is applied in a single transaction: if any row is invalid, or a conflict occurs with on_conflict=fail, nothing is imported. on_conflict decides what happens when a row's slug or long URL is already used: skip the row, overwrite the existing short URL, or fail the import. With dry_run=true, the import is checked and counted but nothing is kept.",
+                "consumes": [
+                    "text/csv",
+                    "application/x-ndjson"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "shorturls"
+                ],
+                "summary": "Import short URLs",
+                "parameters": [
+                    {
+                        "enum": [
+                            "csv",
+                            "jsonl"
+                        ],
+                        "type": "string",
+                        "default": "csv",
+                        "description": "format of the request body",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "skip",
+                            "overwrite",
+                            "fail"
+                        ],
+                        "type": "string",
+                        "default": "fail",
+                        "description": "what to do with rows that clash with existing short URLs",
+                        "name": "on_conflict",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "report what the import would do without keeping it",
+                        "name": "dry_run",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/shorturls.ImportShortUrlsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/e.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/e.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/shorturls.ImportShortUrlsResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/shorturls.ImportShortUrlsResponse"
+                        }
+                    },
+                    "500": {
+                        "description": ""
+                    }
+                }
+            }
+        },
+        "/shorturls/trash": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List the short URLs in the trash, which can be restored until they are purged. Their slugs can't be used by other short URLs until then. Filters, sorting, and pagination work the same way as when listing short URLs, but cursors from one list can't be used with the other.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "shorturls"
+                ],
+                "summary": "List deleted short URLs",
+                "parameters": [
+                    {
+                        "enum": [
+                            "me"
+                        ],
+                        "type": "string",
+                        "description": "only list short URLs owned by the caller",
+                        "name": "owner",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 100,
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 50,
+                        "description": "maximum number of short URLs to return",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor from the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "only list short URLs in the namespace with this prefix",
+                        "name": "namespace",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "only list short URLs whose slug starts with this (after the namespace's prefix, with namespace)",
+                        "name": "slug_prefix",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "only list short URLs whose long URL contains this (ignoring case)",
+                        "name": "long_url_contains",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "format": "dateTime",
+                        "description": "only list short URLs created after this time, in RFC 3339 format",
+                        "name": "created_after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "format": "dateTime",
+                        "description": "only list short URLs created before this time, in RFC 3339 format",
+                        "name": "created_before",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "format": "dateTime",
+                        "description": "only list short URLs expiring before this time, in RFC 3339 format",
+                        "name": "expires_before",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "only list short URLs with (true) or without (false) an expiration date",
+                        "name": "has_expiry",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "created_at",
+                            "slug",
+                            "clicks"
+                        ],
+                        "type": "string",
+                        "default": "created_at",
+                        "description": "field to sort by",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "asc",
+                            "desc"
+                        ],
+                        "type": "string",
+                        "default": "asc",
+                        "description": "sort order",
+                        "name": "order",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/shorturls.ListShortUrlsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/e.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/e.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": ""
+                    }
+                }
+            }
+        },
+        "/shorturls/{slug}": {
+            "get": {
+                "description": "Get information about an existing short URL, including whether it is active or has expired. Expired short URLs can be looked up until they are deleted at the end of their grace period.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "shorturls"
+                ],
+                "summary": "Get information about an existing short URL",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "slug of short URL to get information about",
+                        "name": "slug",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/shorturls.ShortUrlResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/e.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": ""
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Delete an existing short URL by supplying the slug. Only the owner of the short URL or an admin may delete it. Deleted short URLs are moved to the trash, where they keep their statistics and their slug until they are purged, and can be restored until then.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "shorturls"
+                ],
+                "summary": "Delete an existing short URL",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "slug of short URL to delete",
+                        "name": "slug",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": ""
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/e.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/e.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/e.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": ""
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Update the long URL, slug, expiration date, and/or redirect settings of an existing short URL. Fields that are omitted are left unchanged, and an expiration date of null removes the expiration.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "shorturls"
+                ],
+                "summary": "Update an existing short URL",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "slug of short URL to update",
+                        "name": "slug",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Short URL fields to update",
+                        "name": "shorturl",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ShortUrlUpdateFields"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/shorturls.ShortUrlResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/e.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/e.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/e.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/e.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/e.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": ""
+                    }
+                }
+            }
+        },
+        "/shorturls/{slug}/clicks": {
+            "get": {
+                "description": "Get clicks (statistics) for a short URL. Either a named time period or an explicit from/to range must be supplied. Calendar time periods (days, weeks starting on Monday, and months) are evaluated in the given IANA time zone, which defaults to UTC. The LAST_7_DAYS and LAST_30_DAYS periods include today.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "shorturls"
+                ],
+                "summary": "Get clicks for a short URL",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "slug of short URL to retrieve statistics for",
+                        "name": "slug",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "24_HOURS",
+                            "1_WEEK",
+                            "ALL_TIME",
+                            "TODAY",
+                            "YESTERDAY",
+                            "THIS_WEEK",
+                            "THIS_MONTH",
+                            "LAST_MONTH",
+                            "LAST_7_DAYS",
+                            "LAST_30_DAYS"
+                        ],
+                        "type": "string",
+                        "description": "time period to retrieve statistics for",
+                        "name": "time_period",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "format": "dateTime",
+                        "description": "count clicks at or after this time, in RFC 3339 format",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "format": "dateTime",
+                        "description": "count clicks before this time, in RFC 3339 format",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "UTC",
+                        "description": "IANA time zone that calendar time periods are evaluated in",
+                        "name": "tz",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/clicks.GetShortUrlClicksResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/e.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/e.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": ""
+                    }
+                }
+            }
+        },
+        "/shorturls/{slug}/clicks/campaigns": {
+            "get": {
+                "description": "Get the number of clicks for a short URL grouped by the referrer, user agent, Accept-Language header, UTM campaign, or UTM source of each visit, ordered from most to least common. Clicks are selected the same way as when counting them: with either a named time period or an explicit from/to range.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "shorturls"
+                ],
+                "summary": "Get a breakdown of clicks for a short URL",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "slug of short URL to retrieve statistics for",
+                        "name": "slug",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "24_HOURS",
+                            "1_WEEK",
+                            "ALL_TIME",
+                            "TODAY",
+                            "YESTERDAY",
+                            "THIS_WEEK",
+                            "THIS_MONTH",
+                            "LAST_MONTH",
+                            "LAST_7_DAYS",
+                            "LAST_30_DAYS"
+                        ],
+                        "type": "string",
+                        "description": "time period to retrieve statistics for",
+                        "name": "time_period",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "format": "dateTime",
+                        "description": "count clicks at or after this time, in RFC 3339 format",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "format": "dateTime",
+                        "description": "count clicks before this time, in RFC 3339 format",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "UTC",
+                        "description": "IANA time zone that calendar time periods are evaluated in",
+                        "name": "tz",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/clicks.GetShortUrlClickBreakdownResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/e.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/e.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": ""
+                    }
+                }
+            }
+        },
+        "/shorturls/{slug}/clicks/languages": {
+            "get": {
+                "description": "Get the number of clicks for a short URL grouped by the referrer, user agent, Accept-Language header, UTM campaign, or UTM source of each visit, ordered from most to least common. Clicks are selected the same way as when counting them: with either a named time period or an explicit from/to range.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "shorturls"
+                ],
+                "summary": "Get a breakdown of clicks for a short URL",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "slug of short URL to retrieve statistics for",
+                        "name": "slug",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "24_HOURS",
+                            "1_WEEK",
+                            "ALL_TIME",
+                            "TODAY",
+                            "YESTERDAY",
+                            "THIS_WEEK",
+                            "THIS_MONTH",
+                            "LAST_MONTH",
+                            "LAST_7_DAYS",
+                            "LAST_30_DAYS"
+                        ],
+                        "type": "string",
+                        "description": "time period to retrieve statistics for",
+                        "name": "time_period",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "format": "dateTime",
+                        "description": "count clicks at or after this time, in RFC 3339 format",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "format": "dateTime",
+                        "description": "count clicks before this time, in RFC 3339 format",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "UTC",
+                        "description": "IANA time zone that calendar time periods are evaluated in",
+                        "name": "tz",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/clicks.GetShortUrlClickBreakdownResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/e.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/e.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": ""
+                    }
+                }
+            }
+        },
+        "/shorturls/{slug}/clicks/referrers": {
+            "get": {
+                "description": "Get the number of clicks for a short URL grouped by the referrer, user agent, Accept-Language header, UTM campaign, or UTM source of each visit, ordered from most to least common. Clicks are selected the same way as when counting them: with either a named time period or an explicit from/to range.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "shorturls"
+                ],
+                "summary": "Get a breakdown of clicks for a short URL",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "slug of short URL to retrieve statistics for",
+                        "name": "slug",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "24_HOURS",
+                            "1_WEEK",
+                            "ALL_TIME",
+                            "TODAY",
+                            "YESTERDAY",
+                            "THIS_WEEK",
+                            "THIS_MONTH",
+                            "LAST_MONTH",
+                            "LAST_7_DAYS",
+                            "LAST_30_DAYS"
+                        ],
+                        "type": "string",
+                        "description": "time period to retrieve statistics for",
+                        "name": "time_period",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "format": "dateTime",
+                        "description": "count clicks at or after this time, in RFC 3339 format",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "format": "dateTime",
+                        "description": "count clicks before this time, in RFC 3339 format",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "UTC",
+                        "description": "IANA time zone that calendar time periods are evaluated in",
+                        "name": "tz",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/clicks.GetShortUrlClickBreakdownResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/e.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/e.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": ""
+                    }
+                }
+            }
+        },
+        "/shorturls/{slug}/clicks/series": {
+            "get": {
+                "description": "Get the number of clicks for a short URL in each hour, day, or week between two points in time. Buckets are aligned to the given IANA time zone (UTC by default) and buckets without any clicks are included with a count of 0. The series is widened to whole buckets: the buckets that from and to fall in are counted in full.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "shorturls"
+                ],
+                "summary": "Get a time series of clicks for a short URL",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "slug of short URL to retrieve statistics for",
+                        "name": "slug",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "format": "dateTime",
+                        "description": "start of the series, in RFC 3339 format; the bucket it falls in is counted in full",
+                        "name": "from",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "format": "dateTime",
+                        "description": "end of the series (exclusive), in RFC 3339 format; the bucket it falls in is counted in full",
+                        "name": "to",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "hour",
+                            "day",
+                            "week"
+                        ],
+                        "type": "string",
+                        "description": "size of each bucket",
+                        "name": "interval",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "default": "UTC",
+                        "description": "IANA time zone that buckets are aligned to",
+                        "name": "tz",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/clicks.GetShortUrlClickSeriesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/e.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/e.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": ""
+                    }
+                }
+            }
+        },
+        "/shorturls/{slug}/clicks/sources": {
+            "get": {
+                "description": "Get the number of clicks for a short URL grouped by the referrer, user agent, Accept-Language header, UTM campaign, or UTM source of each visit, ordered from most to least common. Clicks are selected the same way as when counting them: with either a named time period or an explicit from/to range.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "shorturls"
+                ],
+                "summary": "Get a breakdown of clicks for a short URL",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "slug of short URL to retrieve statistics for",
+                        "name": "slug",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "24_HOURS",
+                            "1_WEEK",
+                            "ALL_TIME",
+                            "TODAY",
+                            "YESTERDAY",
+                            "THIS_WEEK",
+                            "THIS_MONTH",
+                            "LAST_MONTH",
+                            "LAST_7_DAYS",
+                            "LAST_30_DAYS"
+                        ],
+                        "type": "string",
+                        "description": "time period to retrieve statistics for",
+                        "name": "time_period",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "format": "dateTime",
+                        "description": "count clicks at or after this time, in RFC 3339 format",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "format": "dateTime",
+                        "description": "count clicks before this time, in RFC 3339 format",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "UTC",
+                        "description": "IANA time zone that calendar time periods are evaluated in",
+                        "name": "tz",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/clicks.GetShortUrlClickBreakdownResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/e.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/e.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": ""
+                    }
+                }
+            }
+        },
+        "/shorturls/{slug}/clicks/user-agents": {
+            "get": {
+                "description": "Get the number of clicks for a short URL grouped by the referrer, user agent, Accept-Language header, UTM campaign, or UTM source of each visit, ordered from most to least common. Clicks are selected the same way as when counting them: with either a named time period or an explicit from/to range.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "shorturls"
+                ],
+                "summary": "Get a breakdown of clicks for a short URL",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "slug of short URL to retrieve statistics for",
+                        "name": "slug",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "24_HOURS",
+                            "1_WEEK",
+                            "ALL_TIME",
+                            "TODAY",
+                            "YESTERDAY",
+                            "THIS_WEEK",
+                            "THIS_MONTH",
+                            "LAST_MONTH",
+                            "LAST_7_DAYS",
+                            "LAST_30_DAYS"
+                        ],
+                        "type": "string",
+                        "description": "time period to retrieve statistics for",
+                        "name": "time_period",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "format": "dateTime",
+                        "description": "count clicks at or after this time, in RFC 3339 format",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "format": "dateTime",
+                        "description": "count clicks before this time, in RFC 3339 format",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "UTC",
+                        "description": "IANA time zone that calendar time periods are evaluated in",
+                        "name": "tz",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/clicks.GetShortUrlClickBreakdownResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/e.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/e.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": ""
+                    }
+                }
+            }
+        },
+        "/shorturls/{slug}/history": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List the audit events of a short URL, newest first, a page at a time, including the ones from before its slug was changed. Short URLs in the trash have a history too. Only the owner of the short URL or an admin may read its history, so short URLs created without an API key only have a history for admins.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "audit"
+                ],
+                "summary": "Get the history of a short URL",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "slug of short URL to retrieve the history of",
+                        "name": "slug",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "only list events by this owner",
+                        "name": "actor",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "create",
+                            "update",
+                            "delete",
+                            "restore",
+                            "expire",
+                            "purge"
+                        ],
+                        "type": "string",
+                        "description": "only list events with this action",
+                        "name": "action",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "only list events made by the request with this X-Request-Id",
+                        "name": "request_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "format": "dateTime",
+                        "description": "only list events after this time, in RFC 3339 format",
+                        "name": "created_after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "format": "dateTime",
+                        "description": "only list events before this time, in RFC 3339 format",
+                        "name": "created_before",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 100,
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 50,
+                        "description": "maximum number of events to return",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor from the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/audit.ListAuditEventsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/e.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/e.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/e.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/e.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": ""
+                    }
+                }
+            }
+        },
+        "/shorturls/{slug}/qr": {
+            "get": {
+                "description": "Get a QR code that encodes the same URL as the short_url of the short URL, as a PNG or SVG image. With utm_source (e.g. qr), the URL is tagged with it, and clicks from scanning the code are counted under that source in the sources breakdown.",
+                "produces": [
+                    "image/png",
+                    "image/svg+xml"
+                ],
+                "tags": [
+                    "shorturls"
+                ],
+                "summary": "Get a QR code for a short URL",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "slug of short URL to get a QR code for",
+                        "name": "slug",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "png",
+                            "svg"
+                        ],
+                        "type": "string",
+                        "default": "png",
+                        "description": "image format",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 2048,
+                        "minimum": 64,
+                        "type": "integer",
+                        "default": 256,
+                        "description": "width and height of the image, in pixels",
+                        "name": "size",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "L",
+                            "M",
+                            "Q",
+                            "H"
+                        ],
+                        "type": "string",
+                        "default": "M",
+                        "description": "error correction level",
+                        "name": "ecc",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 16,
+                        "minimum": 0,
+                        "type": "integer",
+                        "default": 4,
+                        "description": "width of the quiet zone around the code, in modules",
+                        "name": "margin",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "utm_source to add to the encoded URL",
+                        "name": "utm_source",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/e.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": ""
+                    },
+                    "500": {
+                        "description": ""
+                    }
+                }
+            }
+        },
+        "/shorturls/{slug}/restore": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Take a short URL out of the trash, along with the statistics it had when it was deleted. Only the owner of the short URL or an admin may restore it. A short URL can't be restored if its long URL has been shortened again since it was deleted.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "shorturls"
+                ],
+                "summary": "Restore a deleted short URL",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "slug of deleted short URL to restore",
+                        "name": "slug",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/shorturls.ShortUrlResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/e.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/e.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/e.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/e.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": ""
+                    }
+                }
+            }
+        },
+        "/shorturls:batch": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Create up to 1000 short urls in one request. Each item is handled like a single create and gets its own result, in the same order as the request: created, already_exists, duplicate_slug, invalid, forbidden, or error. In atomic mode (the default), nothing is kept unless every item is created or already exists; items that would have succeeded are reported as aborted and the response status is 422. In best_effort mode, every item that can be created is.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "shorturls"
+                ],
+                "summary": "Create many short urls",
+                "parameters": [
+                    {
+                        "description": "Short URLs to create",
+                        "name": "batch",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/shorturls.BatchCreateShortUrlsRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/shorturls.BatchCreateShortUrlsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/e.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/e.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/shorturls.BatchCreateShortUrlsResponse"
+                        }
+                    },
+                    "500": {
+                        "description": ""
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Delete up to 1000 short urls by slug in one request. Each slug gets its own result, in the same order as the request: deleted, not_found, forbidden, or error. In atomic mode (the default), nothing is deleted unless every short url can be; slugs that would have been deleted are reported as aborted and the response status is 422. In best_effort mode, every short url that can be deleted is.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "shorturls"
+                ],
+                "summary": "Delete many short urls",
+                "parameters": [
+                    {
+                        "description": "Slugs of short URLs to delete",
+                        "name": "batch",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/shorturls.BatchDeleteShortUrlsRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/shorturls.BatchDeleteShortUrlsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/e.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/e.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/shorturls.BatchDeleteShortUrlsResponse"
+                        }
+                    },
+                    "500": {
+                        "description": ""
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "apikeys.CreateApiKeyResponse": {
+            "type": "object",
+            "required": [
+                "name",
+                "owner_id"
+            ],
+            "properties": {
+                "admin": {
+                    "description": "Admin keys may change or delete any short URL and create other keys.",
+                    "type": "boolean"
+                },
+                "created_at": {
+                    "type": "string",
+                    "format": "dateTime",
+                    "example": "2022-05-11T11:30:00Z"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "key": {
+                    "description": "Key is only ever returned here.",
+                    "type": "string",
+                    "example": "usk_4bXh1n0xkTqz8M1dKqzUu2Yf3CnqfXH1ZlU6mKQm9pY"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "deploy bot"
+                },
+                "owner_id": {
+                    "type": "string",
+                    "example": "platform-team"
+                }
+            }
+        },
+        "audit.AuditEventResponse": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string",
+                    "enum": [
+                        "create",
+                        "update",
+                        "delete",
+                        "restore",
+                        "expire",
+                        "purge"
+                    ],
+                    "example": "update"
+                },
+                "actor": {
+                    "type": "string",
+                    "example": "platform-team"
+                },
+                "actor_type": {
+                    "type": "string",
+                    "enum": [
+                        "user",
+                        "anonymous",
+                        "system"
+                    ],
+                    "example": "user"
+                },
+                "after": {
+                    "type": "object"
+                },
+                "before": {
+                    "description": "Before and After are snapshots of the short URL, or null if it didn't\nexist before the change or doesn't after it.",
+                    "type": "object"
+                },
+                "created_at": {
+                    "type": "string",
+                    "format": "dateTime",
+                    "example": "2022-05-11T11:30:00Z"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 42
+                },
+                "ip_address": {
+                    "type": "string",
+                    "example": "192.0.2.1"
+                },
+                "request_id": {
+                    "type": "string",
+                    "example": "V1StGXR8_Z5jdHi6B-myT"
+                },
+                "short_url_id": {
+                    "type": "integer",
+                    "example": 7
+                },
+                "slug": {
+                    "type": "string",
+                    "example": "myslug"
+                }
+            }
+        },
+        "audit.ListAuditEventsResponse": {
+            "type": "object",
+            "properties": {
+                "events": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/audit.AuditEventResponse"
+                    }
+                },
+                "next_cursor": {
+                    "description": "NextCursor is null on the last page.",
+                    "type": "string",
+                    "example": "eyJpZCI6NDJ9"
+                }
+            }
+        },
+        "clicks.GetShortUrlClickBreakdownResponse": {
+            "type": "object",
+            "properties": {
+                "breakdown": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/services.ClickBreakdownEntry"
+                    }
+                },
+                "time_period": {
+                    "type": "string"
+                }
+            }
+        },
+        "clicks.GetShortUrlClickSeriesResponse": {
+            "type": "object",
+            "properties": {
+                "buckets": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/services.ClickSeriesBucket"
+                    }
+                },
+                "interval": {
+                    "type": "string"
+                },
+                "tz": {
+                    "type": "string"
+                }
+            }
+        },
+        "clicks.GetShortUrlClicksResponse": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "from": {
+                    "type": "string"
                 },
                 "time_period": {
                     "type": "string"
+                },
+                "to": {
+                    "type": "string"
+                },
+                "tz": {
+                    "type": "string"
                 }
             }
         },
@@ -266,55 +2236,591 @@ const docTemplate = `{
                 }
             }
         },
+        "models.ApiKeyCreateFields": {
+            "type": "object",
+            "required": [
+                "name",
+                "owner_id"
+            ],
+            "properties": {
+                "admin": {
+                    "description": "Admin keys may change or delete any short URL and create other keys.",
+                    "type": "boolean"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "deploy bot"
+                },
+                "owner_id": {
+                    "type": "string",
+                    "example": "platform-team"
+                }
+            }
+        },
+        "models.Namespace": {
+            "type": "object",
+            "required": [
+                "owner_id",
+                "prefix"
+            ],
+            "properties": {
+                "created_at": {
+                    "type": "string",
+                    "format": "dateTime",
+                    "example": "2022-05-11T11:30:00Z"
+                },
+                "owner_id": {
+                    "type": "string",
+                    "example": "platform-team"
+                },
+                "prefix": {
+                    "type": "string",
+                    "example": "eng"
+                }
+            }
+        },
+        "models.NamespaceCreateFields": {
+            "type": "object",
+            "required": [
+                "owner_id",
+                "prefix"
+            ],
+            "properties": {
+                "owner_id": {
+                    "type": "string",
+                    "example": "platform-team"
+                },
+                "prefix": {
+                    "type": "string",
+                    "example": "eng"
+                }
+            }
+        },
+        "models.NamespaceUpdateFields": {
+            "type": "object",
+            "properties": {
+                "owner_id": {
+                    "type": "string",
+                    "minLength": 1,
+                    "example": "platform-team"
+                }
+            }
+        },
         "models.ShortUrlCreateFields": {
             "type": "object",
             "required": [
                 "long_url"
             ],
             "properties": {
+                "cache_control": {
+                    "description": "CacheControl and CacheMaxAge (in seconds) make up the Cache-Control\nheader of the redirect.",
+                    "type": "string",
+                    "enum": [
+                        "no-cache",
+                        "no-store",
+                        "private",
+                        "public"
+                    ],
+                    "example": "public"
+                },
+                "cache_max_age": {
+                    "type": "integer",
+                    "minimum": 0,
+                    "example": 3600
+                },
+                "expires_on": {
+                    "type": "string",
+                    "format": "dateTime",
+                    "example": "2023-01-01T16:30:00Z"
+                },
+                "fallback_url": {
+                    "description": "FallbackUrl is where the short URL sends visitors once it's expired,\nalong with a 410 Gone.",
+                    "type": "string",
+                    "format": "url",
+                    "example": "http://www.google.com/expired"
+                },
+                "link_type": {
+                    "description": "LinkType template makes the long URL a template with named\nplaceholders, such as https://jira.corp/browse/{ticket}, which are\nfilled from the path segments after the slug, in order, and then from\nthe query parameters with the same names.",
+                    "type": "string",
+                    "enum": [
+                        "standard",
+                        "template"
+                    ],
+                    "example": "template"
+                },
+                "long_url": {
+                    "type": "string",
+                    "format": "url",
+                    "example": "http://www.google.com"
+                },
+                "path_passthrough": {
+                    "description": "PathPassthrough appends whatever follows the slug in the request's path\nto the long URL's path.",
+                    "type": "boolean",
+                    "example": true
+                },
+                "query_passthrough": {
+                    "description": "QueryPassthrough merges the query string of the request into the long\nURL's. When both have a parameter, prefer_incoming keeps the request's\nvalues, prefer_destination keeps the long URL's, and append keeps both.",
+                    "type": "string",
+                    "enum": [
+                        "none",
+                        "prefer_incoming",
+                        "prefer_destination",
+                        "append"
+                    ],
+                    "example": "prefer_incoming"
+                },
+                "redirect_type": {
+                    "description": "RedirectType is the status code of the redirect. 307 and 308 keep the\nmethod and body of the request, so they suit API endpoints.",
+                    "type": "integer",
+                    "enum": [
+                        301,
+                        302,
+                        307,
+                        308
+                    ],
+                    "example": 302
+                },
+                "slug": {
+                    "type": "string",
+                    "example": "myslug"
+                },
+                "utm_campaign": {
+                    "type": "string",
+                    "example": "spring_sale"
+                },
+                "utm_medium": {
+                    "type": "string",
+                    "example": "email"
+                },
+                "utm_source": {
+                    "type": "string",
+                    "example": "newsletter"
+                }
+            }
+        },
+        "models.ShortUrlUpdateFields": {
+            "type": "object",
+            "properties": {
+                "cache_control": {
+                    "description": "CacheControl and CacheMaxAge (in seconds) make up the Cache-Control\nheader of the redirect.",
+                    "type": "string",
+                    "enum": [
+                        "no-cache",
+                        "no-store",
+                        "private",
+                        "public"
+                    ],
+                    "example": "public"
+                },
+                "cache_max_age": {
+                    "type": "integer",
+                    "minimum": 0,
+                    "example": 3600
+                },
                 "expires_on": {
                     "type": "string",
                     "format": "dateTime",
                     "example": "2023-01-01T16:30:00Z"
                 },
+                "fallback_url": {
+                    "description": "FallbackUrl is where the short URL sends visitors once it's expired,\nalong with a 410 Gone.",
+                    "type": "string",
+                    "format": "url",
+                    "example": "http://www.google.com/expired"
+                },
+                "link_type": {
+                    "description": "LinkType template makes the long URL a template with named\nplaceholders, such as https://jira.corp/browse/{ticket}, which are\nfilled from the path segments after the slug, in order, and then from\nthe query parameters with the same names.",
+                    "type": "string",
+                    "enum": [
+                        "standard",
+                        "template"
+                    ],
+                    "example": "template"
+                },
                 "long_url": {
                     "type": "string",
                     "format": "url",
                     "example": "http://www.google.com"
                 },
+                "path_passthrough": {
+                    "description": "PathPassthrough appends whatever follows the slug in the request's path\nto the long URL's path.",
+                    "type": "boolean",
+                    "example": true
+                },
+                "query_passthrough": {
+                    "description": "QueryPassthrough merges the query string of the request into the long\nURL's. When both have a parameter, prefer_incoming keeps the request's\nvalues, prefer_destination keeps the long URL's, and append keeps both.",
+                    "type": "string",
+                    "enum": [
+                        "none",
+                        "prefer_incoming",
+                        "prefer_destination",
+                        "append"
+                    ],
+                    "example": "prefer_incoming"
+                },
+                "redirect_type": {
+                    "description": "RedirectType is the status code of the redirect. 307 and 308 keep the\nmethod and body of the request, so they suit API endpoints.",
+                    "type": "integer",
+                    "enum": [
+                        301,
+                        302,
+                        307,
+                        308
+                    ],
+                    "example": 302
+                },
                 "slug": {
                     "type": "string",
+                    "minLength": 1,
                     "example": "myslug"
+                },
+                "utm_campaign": {
+                    "type": "string",
+                    "example": "spring_sale"
+                },
+                "utm_medium": {
+                    "type": "string",
+                    "example": "email"
+                },
+                "utm_source": {
+                    "type": "string",
+                    "example": "newsletter"
+                }
+            }
+        },
+        "namespaces.ListNamespacesResponse": {
+            "type": "object",
+            "properties": {
+                "namespaces": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Namespace"
+                    }
+                }
+            }
+        },
+        "services.ClickBreakdownEntry": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "value": {
+                    "type": "string"
+                }
+            }
+        },
+        "services.ClickSeriesBucket": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "start": {
+                    "type": "string"
+                }
+            }
+        },
+        "services.ImportRowError": {
+            "type": "object",
+            "properties": {
+                "line": {
+                    "type": "integer"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "slug": {
+                    "type": "string"
+                }
+            }
+        },
+        "shorturls.BatchCreateShortUrlsItemResponse": {
+            "type": "object",
+            "properties": {
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/e.ValidationError"
+                    }
+                },
+                "short_url": {
+                    "$ref": "#/definitions/shorturls.ShortUrlResponse"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "created",
+                        "already_exists",
+                        "duplicate_slug",
+                        "invalid",
+                        "forbidden",
+                        "error",
+                        "aborted"
+                    ]
+                }
+            }
+        },
+        "shorturls.BatchCreateShortUrlsRequest": {
+            "type": "object",
+            "required": [
+                "short_urls"
+            ],
+            "properties": {
+                "mode": {
+                    "type": "string",
+                    "default": "atomic",
+                    "enum": [
+                        "atomic",
+                        "best_effort"
+                    ]
+                },
+                "short_urls": {
+                    "description": "Items are validated one at a time, so that an invalid item is reported\nin its result rather than failing the whole request.",
+                    "type": "array",
+                    "maxItems": 1000,
+                    "minItems": 1,
+                    "items": {
+                        "$ref": "#/definitions/models.ShortUrlCreateFields"
+                    }
+                }
+            }
+        },
+        "shorturls.BatchCreateShortUrlsResponse": {
+            "type": "object",
+            "properties": {
+                "committed": {
+                    "type": "boolean"
+                },
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/shorturls.BatchCreateShortUrlsItemResponse"
+                    }
+                }
+            }
+        },
+        "shorturls.BatchDeleteShortUrlsItemResponse": {
+            "type": "object",
+            "properties": {
+                "slug": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "deleted",
+                        "not_found",
+                        "forbidden",
+                        "error",
+                        "aborted"
+                    ]
+                }
+            }
+        },
+        "shorturls.BatchDeleteShortUrlsRequest": {
+            "type": "object",
+            "required": [
+                "slugs"
+            ],
+            "properties": {
+                "mode": {
+                    "type": "string",
+                    "default": "atomic",
+                    "enum": [
+                        "atomic",
+                        "best_effort"
+                    ]
+                },
+                "slugs": {
+                    "type": "array",
+                    "maxItems": 1000,
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "shorturls.BatchDeleteShortUrlsResponse": {
+            "type": "object",
+            "properties": {
+                "committed": {
+                    "type": "boolean"
+                },
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/shorturls.BatchDeleteShortUrlsItemResponse"
+                    }
+                }
+            }
+        },
+        "shorturls.ImportShortUrlsResponse": {
+            "type": "object",
+            "properties": {
+                "committed": {
+                    "type": "boolean"
+                },
+                "created": {
+                    "type": "integer"
+                },
+                "dry_run": {
+                    "type": "boolean"
+                },
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/services.ImportRowError"
+                    }
+                },
+                "skipped": {
+                    "type": "integer"
+                },
+                "updated": {
+                    "type": "integer"
+                }
+            }
+        },
+        "shorturls.ListShortUrlsResponse": {
+            "type": "object",
+            "properties": {
+                "next_cursor": {
+                    "description": "NextCursor is null on the last page.",
+                    "type": "string",
+                    "example": "eyJzIjowLCJvIjowLCJjIjoiMjAyMi0wNS0xMVQxMTozMDowMFoiLCJpZCI6NTB9"
+                },
+                "short_urls": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/shorturls.ShortUrlResponse"
+                    }
                 }
             }
         },
-        "models.ShortUrlReadFields": {
+        "shorturls.ShortUrlResponse": {
             "type": "object",
             "required": [
                 "long_url"
             ],
             "properties": {
+                "cache_control": {
+                    "description": "CacheControl and CacheMaxAge (in seconds) make up the Cache-Control\nheader of the redirect.",
+                    "type": "string",
+                    "enum": [
+                        "no-cache",
+                        "no-store",
+                        "private",
+                        "public"
+                    ],
+                    "example": "public"
+                },
+                "cache_max_age": {
+                    "type": "integer",
+                    "minimum": 0,
+                    "example": 3600
+                },
                 "created_at": {
                     "type": "string",
                     "format": "dateTime",
                     "example": "2022-05-11T11:30:00Z"
                 },
+                "deleted_at": {
+                    "description": "DeletedAt is when the short URL was moved to the trash.",
+                    "type": "string",
+                    "format": "dateTime",
+                    "example": "2022-05-12T09:00:00Z"
+                },
                 "expires_on": {
                     "type": "string",
                     "format": "dateTime",
                     "example": "2023-01-01T16:30:00Z"
                 },
+                "fallback_url": {
+                    "description": "FallbackUrl is where the short URL sends visitors once it's expired,\nalong with a 410 Gone.",
+                    "type": "string",
+                    "format": "url",
+                    "example": "http://www.google.com/expired"
+                },
+                "link_type": {
+                    "description": "LinkType template makes the long URL a template with named\nplaceholders, such as https://jira.corp/browse/{ticket}, which are\nfilled from the path segments after the slug, in order, and then from\nthe query parameters with the same names.",
+                    "type": "string",
+                    "enum": [
+                        "standard",
+                        "template"
+                    ],
+                    "example": "template"
+                },
                 "long_url": {
                     "type": "string",
                     "format": "url",
                     "example": "http://www.google.com"
                 },
+                "path_passthrough": {
+                    "description": "PathPassthrough appends whatever follows the slug in the request's path\nto the long URL's path.",
+                    "type": "boolean",
+                    "example": true
+                },
+                "query_passthrough": {
+                    "description": "QueryPassthrough merges the query string of the request into the long\nURL's. When both have a parameter, prefer_incoming keeps the request's\nvalues, prefer_destination keeps the long URL's, and append keeps both.",
+                    "type": "string",
+                    "enum": [
+                        "none",
+                        "prefer_incoming",
+                        "prefer_destination",
+                        "append"
+                    ],
+                    "example": "prefer_incoming"
+                },
+                "redirect_type": {
+                    "description": "RedirectType is the status code of the redirect. 307 and 308 keep the\nmethod and body of the request, so they suit API endpoints.",
+                    "type": "integer",
+                    "enum": [
+                        301,
+                        302,
+                        307,
+                        308
+                    ],
+                    "example": 302
+                },
+                "short_url": {
+                    "type": "string"
+                },
                 "slug": {
                     "type": "string",
                     "example": "myslug"
+                },
+                "status": {
+                    "description": "Status is expired once the short URL has stopped redirecting, until\nit's deleted at the end of its grace period, and deleted while it's in\nthe trash.",
+                    "type": "string",
+                    "enum": [
+                        "active",
+                        "expired",
+                        "deleted"
+                    ],
+                    "example": "active"
+                },
+                "utm_campaign": {
+                    "type": "string",
+                    "example": "spring_sale"
+                },
+                "utm_medium": {
+                    "type": "string",
+                    "example": "email"
+                },
+                "utm_source": {
+                    "type": "string",
+                    "example": "newsletter"
                 }
             }
         }
+    },
+    "securityDefinitions": {
+        "BearerAuth": {
+            "type": "apiKey",
+            "name": "Authorization",
+            "in": "header"
+        }
     }
 }`
 
@@ -325,7 +2831,7 @@ var SwaggerInfo = &swag.Spec{
 	BasePath:         "/api/v1",
 	Schemes:          []string{},
 	Title:            "URL Shortener",
-	Description:      "A basic URL shortener",
+	Description:      "An API key or a JWT, sent as \"Bearer <token>\"",
 	InfoInstanceName: "swagger",
 	SwaggerTemplate:  docTemplate,
 }
@@ -8,6 +8,11 @@ const (
 	CreationResultAlreadyExists
 	CreationResultDuplicateSlug
 	CreationResultInvalidLongUrl
+	CreationResultInvalidTemplate
+	CreationResultInvalidSlug
+	CreationResultSlugOutsideNamespace
+	CreationResultForbiddenSlug
+	CreationResultInvalidFallbackUrl
 	CreationResultUnknownError
 )
 
@@ -17,15 +22,28 @@ const (
 	DeleteResultUnknown DeleteStatus = iota
 	DeleteResultSuccessful
 	DeleteResultNotFound
+	DeleteResultForbidden
 	DeleteResultUnknownError
 )
 
+type RestoreStatus int
+
+const (
+	RestoreResultUnknown RestoreStatus = iota
+	RestoreResultSuccessful
+	RestoreResultNotFound
+	RestoreResultForbidden
+	RestoreResultDuplicateLongUrl
+	RestoreResultUnknownError
+)
+
 type GetClicksStatus int
 
 const (
 	GetClicksResultUnknown GetClicksStatus = iota
 	GetClicksResultSuccessful
 	GetClicksResultNotFound
+	GetClicksResultRangeTooLarge
 	GetClicksResultUnknownError
 )
 
@@ -35,4 +53,160 @@ const (
 	GetClicksTimePeriodAllTime GetClicksTimePeriod = iota
 	GetClicksTimePeriodPastWeek
 	GetClicksTimePeriod24Hours
+	GetClicksTimePeriodToday
+	GetClicksTimePeriodYesterday
+	GetClicksTimePeriodThisWeek
+	GetClicksTimePeriodThisMonth
+	GetClicksTimePeriodLastMonth
+	GetClicksTimePeriodLast7Days
+	GetClicksTimePeriodLast30Days
+)
+
+type UpdateStatus int
+
+const (
+	UpdateResultUnknown UpdateStatus = iota
+	UpdateResultSuccessful
+	UpdateResultNotFound
+	UpdateResultForbidden
+	UpdateResultDuplicateSlug
+	UpdateResultDuplicateLongUrl
+	UpdateResultInvalidLongUrl
+	UpdateResultInvalidTemplate
+	UpdateResultInvalidSlug
+	UpdateResultSlugOutsideNamespace
+	UpdateResultForbiddenSlug
+	UpdateResultInvalidFallbackUrl
+	UpdateResultUnknownError
+)
+
+type ClickSeriesInterval int
+
+const (
+	ClickSeriesIntervalHour ClickSeriesInterval = iota
+	ClickSeriesIntervalDay
+	ClickSeriesIntervalWeek
+)
+
+type ClickDimension int
+
+const (
+	ClickDimensionReferrer ClickDimension = iota
+	ClickDimensionUserAgent
+	ClickDimensionLanguage
+	ClickDimensionCampaign
+	ClickDimensionSource
+)
+
+type IpAnonymizationMode int
+
+const (
+	IpAnonymizationTruncate IpAnonymizationMode = iota
+	IpAnonymizationHash
+)
+
+type ClickOverflowPolicy int
+
+const (
+	ClickOverflowPolicyDrop ClickOverflowPolicy = iota
+	ClickOverflowPolicyBlock
+)
+
+type ShortUrlSort int
+
+const (
+	ShortUrlSortCreatedAt ShortUrlSort = iota
+	ShortUrlSortSlug
+	ShortUrlSortClicks
+)
+
+type SortOrder int
+
+const (
+	SortOrderAscending SortOrder = iota
+	SortOrderDescending
+)
+
+type ListStatus int
+
+const (
+	ListResultUnknown ListStatus = iota
+	ListResultSuccessful
+	ListResultInvalidCursor
+	ListResultUnknownError
+)
+
+type AuditStatus int
+
+const (
+	AuditResultUnknown AuditStatus = iota
+	AuditResultSuccessful
+	AuditResultNotFound
+	AuditResultForbidden
+	AuditResultInvalidCursor
+	AuditResultUnknownError
+)
+
+type BatchMode int
+
+const (
+	// BatchModeAtomic applies every item in a batch or none of them.
+	BatchModeAtomic BatchMode = iota
+	// BatchModeBestEffort applies every item it can, independently.
+	BatchModeBestEffort
+)
+
+type RecordFormat int
+
+const (
+	RecordFormatCsv RecordFormat = iota
+	RecordFormatJsonl
+)
+
+type ConflictStrategy int
+
+const (
+	// ConflictStrategySkip leaves the existing short URL alone.
+	ConflictStrategySkip ConflictStrategy = iota
+	// ConflictStrategyOverwrite updates the existing short URL to match.
+	ConflictStrategyOverwrite
+	// ConflictStrategyFail stops and rolls back the whole import.
+	ConflictStrategyFail
+)
+
+type NamespaceStatus int
+
+const (
+	NamespaceResultUnknown NamespaceStatus = iota
+	NamespaceResultSuccessful
+	NamespaceResultNotFound
+	NamespaceResultForbidden
+	NamespaceResultDuplicatePrefix
+	NamespaceResultInvalidPrefix
+	NamespaceResultUnknownError
+)
+
+type SlugCase int
+
+const (
+	SlugCasePreserve SlugCase = iota
+	SlugCaseLower
+)
+
+type QrCodeFormat int
+
+const (
+	QrCodeFormatPng QrCodeFormat = iota
+	QrCodeFormatSvg
+)
+
+// QrCodeErrorCorrection is how much of a QR code can be damaged and still
+// scan: about 7%, 15%, 25% or 30% respectively.
+type QrCodeErrorCorrection int
+
+const (
+	QrCodeErrorCorrectionLow QrCodeErrorCorrection = iota
+	QrCodeErrorCorrectionMedium
+	QrCodeErrorCorrectionQuartile
+	QrCodeErrorCorrectionHigh
 )
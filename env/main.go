@@ -3,6 +3,8 @@ package env
 import (
 	"log"
 	"os"
+	"strconv"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -14,7 +16,49 @@ const (
 	PostgresPassword = "POSTGRES_PASSWORD"
 	PostgresDatabase = "POSTGRES_DATABASE"
 
+	Storage    = "STORAGE"
+	SqlitePath = "SQLITE_PATH"
+
 	GinMode = "GIN_MODE"
+	Port    = "PORT"
+
+	ClickIpAnonymization = "CLICK_IP_ANONYMIZATION"
+	ClickIpHashSalt      = "CLICK_IP_HASH_SALT"
+
+	ClickRecorder       = "CLICK_RECORDER"
+	ClickBufferSize     = "CLICK_BUFFER_SIZE"
+	ClickBatchSize      = "CLICK_BATCH_SIZE"
+	ClickFlushInterval  = "CLICK_FLUSH_INTERVAL"
+	ClickOverflowPolicy = "CLICK_OVERFLOW_POLICY"
+	ClickRetentionDays  = "CLICK_RETENTION_DAYS"
+
+	ExpiryGracePeriodDays = "EXPIRY_GRACE_PERIOD_DAYS"
+	TrashRetentionDays    = "TRASH_RETENTION_DAYS"
+
+	SlugCache           = "SLUG_CACHE"
+	SlugCacheSize       = "SLUG_CACHE_SIZE"
+	SlugCacheTtl        = "SLUG_CACHE_TTL"
+	SlugCacheMissingTtl = "SLUG_CACHE_MISSING_TTL"
+	RedisUrl            = "REDIS_URL"
+
+	RedirectType         = "REDIRECT_TYPE"
+	RedirectCacheControl = "REDIRECT_CACHE_CONTROL"
+
+	AuthRequired = "AUTH_REQUIRED"
+
+	SlugMinLength     = "SLUG_MIN_LENGTH"
+	SlugMaxLength     = "SLUG_MAX_LENGTH"
+	SlugCase          = "SLUG_CASE"
+	SlugReservedWords = "SLUG_RESERVED_WORDS"
+	SlugBlocklist     = "SLUG_BLOCKLIST"
+	SlugGenerator     = "SLUG_GENERATOR"
+	SlugLength        = "SLUG_LENGTH"
+	SlugAlphabet      = "SLUG_ALPHABET"
+
+	JwtJwks       = "JWT_JWKS"
+	JwtIssuer     = "JWT_ISSUER"
+	JwtAudience   = "JWT_AUDIENCE"
+	JwtAdminGroup = "JWT_ADMIN_GROUP"
 )
 
 func GetEnvVariable(key string) string {
@@ -25,3 +69,57 @@ func GetEnvVariable(key string) string {
 
 	return os.Getenv(key)
 }
+
+// GetIntEnvVariable returns the integer value of an environment variable, or
+// defaultValue if it isn't set.
+func GetIntEnvVariable(key string, defaultValue int) int {
+	value := GetEnvVariable(key)
+
+	if value == "" {
+		return defaultValue
+	}
+
+	i, err := strconv.Atoi(value)
+
+	if err != nil {
+		log.Fatalf("error parsing environment variable %s as an integer: %v\n", key, err)
+	}
+
+	return i
+}
+
+// GetDurationEnvVariable returns the value of an environment variable parsed
+// as a duration (e.g. "1s" or "500ms"), or defaultValue if it isn't set.
+func GetDurationEnvVariable(key string, defaultValue time.Duration) time.Duration {
+	value := GetEnvVariable(key)
+
+	if value == "" {
+		return defaultValue
+	}
+
+	d, err := time.ParseDuration(value)
+
+	if err != nil {
+		log.Fatalf("error parsing environment variable %s as a duration: %v\n", key, err)
+	}
+
+	return d
+}
+
+// GetBoolEnvVariable returns the value of an environment variable parsed as
+// a boolean (e.g. "true" or "1"), or defaultValue if it isn't set.
+func GetBoolEnvVariable(key string, defaultValue bool) bool {
+	value := GetEnvVariable(key)
+
+	if value == "" {
+		return defaultValue
+	}
+
+	b, err := strconv.ParseBool(value)
+
+	if err != nil {
+		log.Fatalf("error parsing environment variable %s as a boolean: %v\n", key, err)
+	}
+
+	return b
+}
@@ -4,27 +4,52 @@ import (
 	"log"
 	"time"
 	"url-shortener/models"
+	"url-shortener/repositories"
 	"url-shortener/services"
 
 	"github.com/go-co-op/gocron"
-	"gorm.io/gorm"
 )
 
-func CleanupExpiredShortUrls(db *gorm.DB, clock services.Clock) (int64, error) {
-	now := clock.Now()
-	deleteResult := db.Where("expires_on <= ?", now).Delete(&models.ShortUrl{})
+// CleanupExpiredShortUrls deletes the short URLs that expired more than
+// gracePeriod ago, recording each deletion in the audit log. Until then,
+// they're kept as tombstones that answer 410 Gone, and their slugs can't be
+// reused.
+func CleanupExpiredShortUrls(store repositories.Store, clock services.Clock, gracePeriod time.Duration) (int64, error) {
+	var deleted []models.ShortUrl
 
-	if deleteResult.Error != nil {
-		return 0, deleteResult.Error
+	err := store.Transaction(func(tx repositories.Store) error {
+		var err error
+
+		if deleted, err = tx.ShortUrls().DeleteExpired(clock.Now().Add(-gracePeriod)); err != nil {
+			return err
+		}
+
+		return services.RecordSystemDeletions(tx, models.AuditActionExpire, deleted)
+	})
+
+	if err != nil {
+		return 0, err
 	}
 
-	return deleteResult.RowsAffected, nil
+	return int64(len(deleted)), nil
+}
+
+type SchedulerConfig struct {
+	// ExpiryGracePeriod is how long expired short URLs are kept before
+	// they're deleted.
+	ExpiryGracePeriod time.Duration
+	// TrashRetention is how long deleted short URLs stay in the trash
+	// before they're purged. Zero keeps them forever.
+	TrashRetention time.Duration
+	// ClickRetention is how long raw clicks are kept after they've been
+	// rolled up. Zero keeps them forever.
+	ClickRetention time.Duration
 }
 
-func StartScheduler(gormDB *gorm.DB, clock services.Clock) {
+func StartScheduler(store repositories.Store, clock services.Clock, config SchedulerConfig) {
 	scheduler := gocron.NewScheduler(time.UTC)
 	scheduler.Every(5).Seconds().Do(func() {
-		deletions, err := CleanupExpiredShortUrls(gormDB, services.SystemClock{})
+		deletions, err := CleanupExpiredShortUrls(store, clock, config.ExpiryGracePeriod)
 
 		if err != nil {
 			log.Printf("encountered error deleting expired short urls: %v", err)
@@ -36,5 +61,48 @@ func StartScheduler(gormDB *gorm.DB, clock services.Clock) {
 		}
 	})
 
+	scheduler.Every(1).Minute().Do(func() {
+		rollups, err := RollupClicks(store, 10000)
+
+		if err != nil {
+			log.Printf("encountered error rolling up clicks: %v", err)
+			return
+		}
+
+		if rollups > 0 {
+			log.Printf("updated %d hourly click rollups", rollups)
+		}
+	})
+
+	if config.ClickRetention > 0 {
+		scheduler.Every(1).Hour().Do(func() {
+			deletions, err := PruneClicks(store, clock, config.ClickRetention)
+
+			if err != nil {
+				log.Printf("encountered error pruning clicks: %v", err)
+				return
+			}
+
+			if deletions > 0 {
+				log.Printf("pruned %d clicks older than %s", deletions, config.ClickRetention)
+			}
+		})
+	}
+
+	if config.TrashRetention > 0 {
+		scheduler.Every(1).Hour().Do(func() {
+			purges, err := PurgeDeletedShortUrls(store, clock, config.TrashRetention)
+
+			if err != nil {
+				log.Printf("encountered error purging deleted short urls: %v", err)
+				return
+			}
+
+			if purges > 0 {
+				log.Printf("purged %d short urls deleted more than %s ago", purges, config.TrashRetention)
+			}
+		})
+	}
+
 	scheduler.StartAsync()
 }
@@ -5,6 +5,7 @@ import (
 	"testing"
 	"time"
 	"url-shortener/db"
+	"url-shortener/repositories"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/stretchr/testify/assert"
@@ -12,11 +13,20 @@ import (
 
 func TestCleanupExpiredShortUrlsReturnsNumberOfDeleteRowsOnSuccess(t *testing.T) {
 	sqlDB, mock, err := sqlmock.New()
+	cutoff := testClock{}.Now().Add(-24 * time.Hour)
 
 	mock.ExpectBegin()
+	mock.ExpectExec("SAVEPOINT").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM \"short_urls\"")).
+		WithArgs(cutoff).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "slug"}).AddRow(7, "expired"))
 	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM \"short_urls\"")).
-		WithArgs(testClock{}.Now()).
+		WithArgs(7, cutoff).
 		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectQuery(regexp.QuoteMeta("INSERT INTO \"audit_events\"")).
+		WithArgs(sqlmock.AnyArg(), "system", "", "expire", 7, "expired", sqlmock.AnyArg(), nil, "", "").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
 	mock.ExpectCommit()
 
 	gormDB, err := db.ConnectDatabaseWithoutMigrating(sqlDB)
@@ -25,13 +35,14 @@ func TestCleanupExpiredShortUrlsReturnsNumberOfDeleteRowsOnSuccess(t *testing.T)
 		t.Fatal(err)
 	}
 
-	rowsDeleted, err := CleanupExpiredShortUrls(gormDB, testClock{})
+	rowsDeleted, err := CleanupExpiredShortUrls(repositories.NewPostgresStore(gormDB), testClock{}, 24*time.Hour)
 
 	if err != nil {
 		t.Fatal(err)
 	}
 
 	assert.Equal(t, int64(1), rowsDeleted)
+	assert.Nil(t, mock.ExpectationsWereMet())
 }
 
 type testClock struct{}
@@ -0,0 +1,31 @@
+package jobs
+
+import (
+	"time"
+	"url-shortener/models"
+	"url-shortener/repositories"
+	"url-shortener/services"
+)
+
+// PurgeDeletedShortUrls permanently deletes the short URLs that have been in
+// the trash for longer than retention, along with their clicks, which frees
+// up their slugs. Each purge is recorded in the audit log.
+func PurgeDeletedShortUrls(store repositories.Store, clock services.Clock, retention time.Duration) (int64, error) {
+	var purged []models.ShortUrl
+
+	err := store.Transaction(func(tx repositories.Store) error {
+		var err error
+
+		if purged, err = tx.ShortUrls().Purge(clock.Now().Add(-retention)); err != nil {
+			return err
+		}
+
+		return services.RecordSystemDeletions(tx, models.AuditActionPurge, purged)
+	})
+
+	if err != nil {
+		return 0, err
+	}
+
+	return int64(len(purged)), nil
+}
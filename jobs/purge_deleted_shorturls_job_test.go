@@ -0,0 +1,45 @@
+package jobs
+
+import (
+	"regexp"
+	"testing"
+	"time"
+	"url-shortener/db"
+	"url-shortener/repositories"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPurgeDeletedShortUrlsReturnsNumberOfDeletedRowsOnSuccess(t *testing.T) {
+	sqlDB, mock, err := sqlmock.New()
+	cutoff := testClock{}.Now().Add(-30 * 24 * time.Hour)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("SAVEPOINT").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM \"short_urls\" WHERE deleted_at <= $1")).
+		WithArgs(cutoff).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "slug"}).AddRow(3, "deleted").AddRow(5, "also-deleted"))
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM \"short_urls\"")).
+		WithArgs(3, 5, cutoff).
+		WillReturnResult(sqlmock.NewResult(2, 2))
+	mock.ExpectQuery(regexp.QuoteMeta("INSERT INTO \"audit_events\"")).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1).AddRow(2))
+	mock.ExpectCommit()
+
+	gormDB, err := db.ConnectDatabaseWithoutMigrating(sqlDB)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rowsDeleted, err := PurgeDeletedShortUrls(repositories.NewPostgresStore(gormDB), testClock{}, 30*24*time.Hour)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, int64(2), rowsDeleted)
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
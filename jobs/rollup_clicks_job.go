@@ -0,0 +1,24 @@
+package jobs
+
+import (
+	"time"
+	"url-shortener/repositories"
+	"url-shortener/services"
+)
+
+// RollupClicks adds up to batchSize clicks that haven't been rolled up yet to
+// the hourly counts in click_rollups, and marks them as rolled up. Both
+// happen atomically, so a click is never counted twice or skipped, even if
+// it's inserted while the job is running. It returns the number of hourly
+// counts that were updated.
+func RollupClicks(store repositories.Store, batchSize int) (int64, error) {
+	return store.Clicks().Rollup(batchSize)
+}
+
+// PruneClicks deletes clicks that are older than the retention period and
+// have already been rolled up. Counts stay exact because the rollups keep
+// covering them, but breakdowns by referrer, user agent, or language only
+// include clicks that are still retained.
+func PruneClicks(store repositories.Store, clock services.Clock, retention time.Duration) (int64, error) {
+	return store.Clicks().Prune(clock.Now().Add(-retention))
+}
@@ -0,0 +1,56 @@
+package jobs
+
+import (
+	"regexp"
+	"testing"
+	"time"
+	"url-shortener/db"
+	"url-shortener/repositories"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRollupClicksReturnsNumberOfUpdatedRollupsOnSuccess(t *testing.T) {
+	sqlDB, mock, err := sqlmock.New()
+
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO click_rollups")).
+		WithArgs(100).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+
+	gormDB, err := db.ConnectDatabaseWithoutMigrating(sqlDB)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rollups, err := RollupClicks(repositories.NewPostgresStore(gormDB), 100)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, int64(3), rollups)
+}
+
+func TestPruneClicksDeletesRolledUpClicksOlderThanRetention(t *testing.T) {
+	sqlDB, mock, err := sqlmock.New()
+
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM clicks")).
+		WithArgs(testClock{}.Now().Add(-48 * time.Hour)).
+		WillReturnResult(sqlmock.NewResult(0, 5))
+
+	gormDB, err := db.ConnectDatabaseWithoutMigrating(sqlDB)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rowsDeleted, err := PruneClicks(repositories.NewPostgresStore(gormDB), testClock{}, 48*time.Hour)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, int64(5), rowsDeleted)
+}
@@ -4,19 +4,238 @@
 
 // @host      localhost:8080
 // @BasePath  /api/v1
+
+// @securityDefinitions.apikey  BearerAuth
+// @in                          header
+// @name                        Authorization
+// @description                 An API key or a JWT, sent as "Bearer <token>"
 package main
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+	_ "time/tzdata"
+	"unicode"
+	"url-shortener/cache"
+	"url-shortener/controllers"
 	"url-shortener/db"
+	"url-shortener/enums"
 	"url-shortener/env"
 	"url-shortener/jobs"
+	"url-shortener/repositories"
 	"url-shortener/server"
 	"url-shortener/services"
+
+	"github.com/go-redis/redis/v8"
+	"gorm.io/gorm"
 )
 
 func main() {
+	store := connectStore()
+
+	if len(os.Args) > 1 {
+		runCommand(store, os.Args[1:])
+		return
+	}
+
+	slugCache := connectSlugCache()
+	jobStore := store
+
+	if slugCache != nil {
+		jobStore = cache.NewInvalidatingStore(store, slugCache)
+	}
+
+	jobs.StartScheduler(jobStore, services.SystemClock{}, jobs.SchedulerConfig{
+		ExpiryGracePeriod: time.Duration(env.GetIntEnvVariable(env.ExpiryGracePeriodDays, 30)) * 24 * time.Hour,
+		TrashRetention:    time.Duration(env.GetIntEnvVariable(env.TrashRetentionDays, 30)) * 24 * time.Hour,
+		ClickRetention:    time.Duration(env.GetIntEnvVariable(env.ClickRetentionDays, 90)) * 24 * time.Hour,
+	})
+
+	ipAnonymizer := services.IpAnonymizer{
+		Salt: env.GetEnvVariable(env.ClickIpHashSalt),
+	}
+
+	switch mode := env.GetEnvVariable(env.ClickIpAnonymization); mode {
+	case "", "truncate":
+		ipAnonymizer.Mode = enums.IpAnonymizationTruncate
+	case "hash":
+		ipAnonymizer.Mode = enums.IpAnonymizationHash
+	default:
+		panic(fmt.Sprintf("Unknown %s value: %s", env.ClickIpAnonymization, mode))
+	}
+
+	slugPolicy := buildSlugPolicy()
+
+	config := server.ServerConfig{
+		Store:                store,
+		IpAnonymizer:         ipAnonymizer,
+		RequireAuthForWrites: env.GetBoolEnvVariable(env.AuthRequired, false),
+		JwtAuthenticator:     buildJwtAuthenticator(),
+		SlugCache:            slugCache,
+		SlugCacheConfig: services.SlugCacheConfig{
+			Ttl:        env.GetDurationEnvVariable(env.SlugCacheTtl, time.Hour),
+			MissingTtl: env.GetDurationEnvVariable(env.SlugCacheMissingTtl, time.Minute),
+		},
+		RedirectDefaults: buildRedirectDefaults(),
+		SlugPolicy:       slugPolicy,
+		SlugGenerator:    buildSlugGenerator(slugPolicy),
+	}
+
+	var clickRecorder *services.BufferedClickRecorder
+
+	switch recorder := env.GetEnvVariable(env.ClickRecorder); recorder {
+	case "", "buffered":
+		clickRecorder = services.NewBufferedClickRecorder(store.Clicks(), services.SystemClock{}, buildClickRecorderConfig())
+
+		clickRecorder.Start()
+		config.ClickRecorder = clickRecorder
+	case "sync":
+		config.ClickRecorder = &services.SynchronousClickRecorder{Clicks: store.Clicks()}
+	default:
+		panic(fmt.Sprintf("Unknown %s value: %s", env.ClickRecorder, recorder))
+	}
+
+	port := env.GetEnvVariable(env.Port)
+
+	if port == "" {
+		port = "8080"
+	}
+
+	httpServer := &http.Server{
+		Addr:    ":" + port,
+		Handler: server.SetupServer(&config),
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	go func() {
+		if err := httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("error running web server: %v", err)
+		}
+	}()
+
+	<-ctx.Done()
+	log.Println("shutting down")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := httpServer.Shutdown(shutdownCtx); err != nil {
+		log.Printf("encountered error shutting down web server: %v", err)
+	}
+
+	// Only drain the click buffer once the web server has stopped handling
+	// requests, so that no more clicks can be recorded.
+	if clickRecorder != nil {
+		if err := clickRecorder.Shutdown(shutdownCtx); err != nil {
+			log.Printf("encountered error writing buffered clicks: %v", err)
+		}
+	}
+}
+
+// buildJwtAuthenticator returns nil unless a JWKS is configured, in which
+// case JWTs are accepted alongside API keys.
+func buildJwtAuthenticator() *services.JwtAuthenticator {
+	jwks := env.GetEnvVariable(env.JwtJwks)
+
+	if jwks == "" {
+		return nil
+	}
+
+	config := services.JwtAuthenticatorConfig{
+		Issuer:     env.GetEnvVariable(env.JwtIssuer),
+		Audience:   env.GetEnvVariable(env.JwtAudience),
+		AdminGroup: env.GetEnvVariable(env.JwtAdminGroup),
+	}
+
+	if config.Issuer == "" || config.Audience == "" {
+		panic(fmt.Sprintf("%s and %s must be set when %s is", env.JwtIssuer, env.JwtAudience, env.JwtJwks))
+	}
+
+	keys, err := services.NewJwksKeySource(jwks, services.SystemClock{})
+
+	if err != nil {
+		panic(fmt.Sprintf("Unable to load JWKS: %s", err))
+	}
+
+	return &services.JwtAuthenticator{
+		Keys:   keys,
+		Clock:  services.SystemClock{},
+		Config: config,
+	}
+}
+
+// connectStore connects to Postgres unless STORAGE picks another database.
+func connectStore() repositories.Store {
+	switch storage := env.GetEnvVariable(env.Storage); storage {
+	case "", "postgres":
+		gormDB, err := connectPostgres()
+
+		if err != nil {
+			panic(fmt.Sprintf("Unable to connect to postgres: %s", err))
+		}
+
+		return repositories.NewPostgresStore(gormDB)
+	case "sqlite":
+		path := env.GetEnvVariable(env.SqlitePath)
+
+		if path == "" {
+			path = "url-shortener.db"
+		}
+
+		gormDB, err := db.ConnectSqlite(path)
+
+		if err != nil {
+			panic(fmt.Sprintf("Unable to open sqlite database: %s", err))
+		}
+
+		return repositories.NewSqliteStore(gormDB)
+	case "memory":
+		return repositories.NewMemoryStore()
+	default:
+		panic(fmt.Sprintf("Unknown %s value: %s", env.Storage, storage))
+	}
+}
+
+// connectSlugCache returns the cache to resolve slugs through, which is an
+// in-process LRU unless SLUG_CACHE picks Redis or turns it off.
+func connectSlugCache() cache.SlugCache {
+	switch slugCache := env.GetEnvVariable(env.SlugCache); slugCache {
+	case "", "lru":
+		size := env.GetIntEnvVariable(env.SlugCacheSize, 10000)
+
+		// The cache would otherwise panic on the first redirect.
+		if size < 1 {
+			panic(fmt.Sprintf("%s must be at least 1", env.SlugCacheSize))
+		}
+
+		return cache.NewLru(size)
+	case "redis":
+		options, err := redis.ParseURL(env.GetEnvVariable(env.RedisUrl))
+
+		if err != nil {
+			panic(fmt.Sprintf("Unable to parse %s: %s", env.RedisUrl, err))
+		}
+
+		return cache.NewRedis(redis.NewClient(options), "slug:")
+	case "none":
+		return nil
+	default:
+		panic(fmt.Sprintf("Unknown %s value: %s", env.SlugCache, slugCache))
+	}
+}
+
+func connectPostgres() (*gorm.DB, error) {
 	postgresHost := env.GetEnvVariable(env.PostgresHost)
 	postgresPort := env.GetEnvVariable(env.PostgresPort)
 	postgresUser := env.GetEnvVariable(env.PostgresUser)
@@ -36,13 +255,133 @@ func main() {
 	sqlDB, err := sql.Open("pgx", url)
 
 	if err != nil {
-		panic(fmt.Sprintf("Unable to connect to postgres: %s", err))
+		return nil, err
 	}
 
-	gormDB, err := db.ConnectDatabase(sqlDB)
+	return db.ConnectDatabase(sqlDB)
+}
+
+// buildRedirectDefaults accepts the same values that short URLs can set for
+// themselves.
+func buildRedirectDefaults() controllers.RedirectDefaults {
+	defaults := controllers.RedirectDefaults{
+		RedirectType: env.GetIntEnvVariable(env.RedirectType, http.StatusMovedPermanently),
+		CacheControl: env.GetEnvVariable(env.RedirectCacheControl),
+	}
+
+	switch defaults.RedirectType {
+	case http.StatusMovedPermanently, http.StatusFound, http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+	default:
+		panic(fmt.Sprintf("Unknown %s value: %d", env.RedirectType, defaults.RedirectType))
+	}
+
+	switch defaults.CacheControl {
+	case "", "no-cache", "no-store", "private", "public":
+	default:
+		panic(fmt.Sprintf("Unknown %s value: %s", env.RedirectCacheControl, defaults.CacheControl))
+	}
+
+	return defaults
+}
+
+// buildSlugPolicy reads the reserved words as a comma-separated list, and
+// the blocked words from a file with one on each line.
+func buildSlugPolicy() services.SlugPolicy {
+	policy := services.SlugPolicy{
+		MinLength:     env.GetIntEnvVariable(env.SlugMinLength, 1),
+		MaxLength:     env.GetIntEnvVariable(env.SlugMaxLength, services.DefaultSlugMaxLength),
+		ReservedWords: services.DefaultReservedWords,
+	}
+
+	switch slugCase := env.GetEnvVariable(env.SlugCase); slugCase {
+	case "", "preserve":
+		policy.Case = enums.SlugCasePreserve
+	case "lower":
+		policy.Case = enums.SlugCaseLower
+	default:
+		panic(fmt.Sprintf("Unknown %s value: %s", env.SlugCase, slugCase))
+	}
 
-	jobs.StartScheduler(gormDB, services.SystemClock{})
+	if reserved, ok := os.LookupEnv(env.SlugReservedWords); ok {
+		policy.ReservedWords = strings.FieldsFunc(reserved, func(r rune) bool {
+			return r == ',' || unicode.IsSpace(r)
+		})
+	}
+
+	if path := env.GetEnvVariable(env.SlugBlocklist); path != "" {
+		b, err := os.ReadFile(path)
+
+		if err != nil {
+			panic(fmt.Sprintf("Unable to read %s: %s", env.SlugBlocklist, err))
+		}
+
+		policy.BlockedWords = strings.Fields(string(b))
+	}
+
+	return policy
+}
+
+// buildSlugGenerator picks the strategy for generating slugs. SLUG_LENGTH is
+// the length slugs start at, and SLUG_ALPHABET only applies to nanoids.
+func buildSlugGenerator(policy services.SlugPolicy) services.SlugGenerator {
+	length := env.GetIntEnvVariable(env.SlugLength, 0)
+
+	switch generator := env.GetEnvVariable(env.SlugGenerator); generator {
+	case "", "nanoid":
+		nanoid := services.DefaultSlugGenerator(policy)
+		nanoid.SlugLength = length
+
+		if alphabet := env.GetEnvVariable(env.SlugAlphabet); alphabet != "" {
+			nanoid.Alphabet = alphabet
+		}
+
+		return nanoid
+	case "crockford":
+		return services.NewCrockfordSlugGenerator(length)
+	case "pronounceable":
+		return services.PronounceableSlugGenerator{SlugLength: length}
+	case "sequential":
+		return services.SequentialSlugGenerator{
+			Lowercase:  policy.Case == enums.SlugCaseLower,
+			SlugLength: length,
+		}
+	default:
+		panic(fmt.Sprintf("Unknown %s value: %s", env.SlugGenerator, generator))
+	}
+}
+
+func buildClickRecorderConfig() services.BufferedClickRecorderConfig {
+	config := services.BufferedClickRecorderConfig{
+		BufferSize:     env.GetIntEnvVariable(env.ClickBufferSize, 10000),
+		BatchSize:      env.GetIntEnvVariable(env.ClickBatchSize, 500),
+		FlushInterval:  env.GetDurationEnvVariable(env.ClickFlushInterval, time.Second),
+		OverflowPolicy: parseClickOverflowPolicy(env.GetEnvVariable(env.ClickOverflowPolicy)),
+	}
+
+	// These would otherwise only fail once the recorder is running, in its
+	// background goroutine.
+	if config.BufferSize < 1 {
+		panic(fmt.Sprintf("%s must be at least 1", env.ClickBufferSize))
+	}
+
+	if config.BatchSize < 1 {
+		panic(fmt.Sprintf("%s must be at least 1", env.ClickBatchSize))
+	}
+
+	if config.FlushInterval <= 0 {
+		panic(fmt.Sprintf("%s must be positive", env.ClickFlushInterval))
+	}
+
+	return config
+}
+
+func parseClickOverflowPolicy(policy string) enums.ClickOverflowPolicy {
+	switch policy {
+	case "", "drop":
+		return enums.ClickOverflowPolicyDrop
+	case "block":
+		return enums.ClickOverflowPolicyBlock
+	}
 
-	config := server.ServerConfig{DB: gormDB}
-	server.SetupServer(&config).Run()
+	panic(fmt.Sprintf("Unknown %s value: %s", env.ClickOverflowPolicy, policy))
 }
@@ -0,0 +1,89 @@
+package middleware
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"url-shortener/e"
+	"url-shortener/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+const principalKey = "principal"
+
+// Authenticate attaches the principal for the bearer token (an API key or a
+// JWT) in the Authorization header to the request, if there is one. Requests
+// with a token that isn't valid are rejected. Requests without a token are
+// anonymous, and are rejected when requireForWrites is set and they would
+// change something.
+func Authenticate(authenticator services.Authenticator, requireForWrites bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		header := c.GetHeader("Authorization")
+
+		if header == "" {
+			if requireForWrites && !isReadOnly(c.Request.Method) {
+				AbortUnauthorized(c, "required")
+				return
+			}
+
+			c.Next()
+			return
+		}
+
+		if !strings.HasPrefix(header, "Bearer ") {
+			AbortUnauthorized(c, "must be a bearer token")
+			return
+		}
+
+		principal, err := authenticator.Authenticate(strings.TrimPrefix(header, "Bearer "))
+
+		if errors.Is(err, services.ErrInvalidApiKey) {
+			AbortUnauthorized(c, "invalid api key")
+			return
+		}
+
+		if errors.Is(err, services.ErrInvalidToken) {
+			AbortUnauthorized(c, "invalid token")
+			return
+		}
+
+		if err != nil {
+			log.Printf("encountered error authenticating bearer token: %v", err)
+			c.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		c.Set(principalKey, principal)
+		c.Next()
+	}
+}
+
+// GetPrincipal returns the principal attached by Authenticate, or nil for an
+// anonymous request.
+func GetPrincipal(c *gin.Context) *services.Principal {
+	principal, ok := c.Get(principalKey)
+
+	if !ok {
+		return nil
+	}
+
+	return principal.(*services.Principal)
+}
+
+func AbortUnauthorized(c *gin.Context, reason string) {
+	c.Header("WWW-Authenticate", "Bearer")
+	c.AbortWithStatusJSON(http.StatusUnauthorized, e.ErrorResponse{
+		Errors: []e.ValidationError{
+			{
+				Field:  "Authorization",
+				Reason: reason,
+			},
+		},
+	})
+}
+
+func isReadOnly(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
+}
@@ -15,17 +15,50 @@ func ModelBindingWrapper[T any](controller controllers.Controller[T]) gin.Handle
 		var request T
 
 		if err := c.ShouldBind(&request); err != nil {
-			var verr validator.ValidationErrors
-			if errors.As(err, &verr) {
-				c.JSON(http.StatusBadRequest, e.ErrorResponse{
-					Errors: e.FormatErrors(verr),
-				})
-			} else {
-				c.Writer.WriteHeader(http.StatusBadRequest)
-			}
+			abortWithBindingError(c, err)
 			return
 		}
 
 		controller.HandleRequest(c, request)
 	}
 }
+
+// QueryBindingWrapper only binds the query string, leaving the request body
+// for the controller to read, e.g. to stream it.
+func QueryBindingWrapper[T any](controller controllers.Controller[T]) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var request T
+
+		if err := c.ShouldBindQuery(&request); err != nil {
+			abortWithBindingError(c, err)
+			return
+		}
+
+		controller.HandleRequest(c, request)
+	}
+}
+
+func abortWithBindingError(c *gin.Context, err error) {
+	var verr validator.ValidationErrors
+	if errors.As(err, &verr) {
+		c.JSON(http.StatusBadRequest, e.ErrorResponse{
+			Errors: e.FormatErrors(verr),
+		})
+	} else {
+		c.Writer.WriteHeader(http.StatusBadRequest)
+	}
+}
+
+// ExactPath rejects requests whose path isn't exactly path. gin treats a
+// colon anywhere in a route as the start of a parameter, so a route like
+// "/api/v1/shorturls:batch" also matches "/api/v1/shorturls:anything".
+func ExactPath(path string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.URL.Path != path {
+			c.AbortWithStatus(http.StatusNotFound)
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"log"
+	"url-shortener/services"
+
+	"github.com/gin-gonic/gin"
+	gonanoid "github.com/matoous/go-nanoid"
+)
+
+const (
+	RequestIdHeader = "X-Request-Id"
+	maxRequestIdLen = 128
+)
+
+// GetOrigin returns where the request came from, for the audit log. The
+// request keeps the id in its X-Request-Id header, e.g. from a load balancer,
+// as long as it's made of at most 128 printable ASCII characters; otherwise
+// it's given a new one. Either way, the id is echoed in the X-Request-Id
+// header of the response, so it must be called before the response is
+// written.
+func GetOrigin(c *gin.Context) services.Origin {
+	id := c.GetHeader(RequestIdHeader)
+
+	if !validRequestId(id) {
+		var err error
+
+		// An id that can't be generated only makes the audit log less
+		// useful, so the request goes ahead without one.
+		if id, err = gonanoid.Nanoid(); err != nil {
+			log.Printf("encountered error generating request id: %v", err)
+		}
+	}
+
+	if id != "" {
+		c.Header(RequestIdHeader, id)
+	}
+
+	return services.Origin{
+		RequestId: id,
+		IpAddress: c.ClientIP(),
+	}
+}
+
+func validRequestId(id string) bool {
+	if id == "" || len(id) > maxRequestIdLen {
+		return false
+	}
+
+	for i := 0; i < len(id); i++ {
+		if id[i] <= ' ' || id[i] > '~' {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// ApiKey authenticates requests on behalf of an owner. Only a hash of the key
+// is stored; the key itself is shown once, when it's created.
+type ApiKey struct {
+	Id      int64  `json:"id"         gorm:"primaryKey"`
+	KeyHash string `json:"-"          gorm:"index:uq_api_keys_key_hash,unique;not null"`
+	ApiKeyCreateFields
+	CreatedAt time.Time `json:"created_at" format:"dateTime" example:"2022-05-11T11:30:00Z"`
+}
+
+type ApiKeyCreateFields struct {
+	Name    string `json:"name"     gorm:"not null" binding:"required"  example:"deploy bot"`
+	OwnerId string `json:"owner_id" gorm:"not null" binding:"required"  example:"platform-team"`
+	// Admin keys may change or delete any short URL and create other keys.
+	Admin bool `json:"admin"    gorm:"not null;default:false"`
+}
@@ -0,0 +1,50 @@
+package models
+
+import (
+	"time"
+
+	"gopkg.in/guregu/null.v4"
+)
+
+// The actions that audit events record.
+const (
+	AuditActionCreate  = "create"
+	AuditActionUpdate  = "update"
+	AuditActionDelete  = "delete"
+	AuditActionRestore = "restore"
+	AuditActionExpire  = "expire"
+	AuditActionPurge   = "purge"
+)
+
+// The kinds of actor that make changes.
+const (
+	// AuditActorUser is a request made with an API key or a JWT.
+	AuditActorUser      = "user"
+	AuditActorAnonymous = "anonymous"
+	// AuditActorSystem is one of the scheduled jobs.
+	AuditActorSystem = "system"
+)
+
+// AuditEvent records a change to a short URL: who made it, from where, and
+// what the short URL looked like before and after. Events outlive the short
+// URLs they're about, so ShortUrlId isn't a foreign key.
+type AuditEvent struct {
+	Id        int64     `gorm:"primaryKey;index:idx_audit_events_short_url_id,priority:2"`
+	CreatedAt time.Time `gorm:"index"`
+	// ActorType is one of the AuditActor constants, and Actor is the owner
+	// of the API key or JWT that a user made the change with.
+	ActorType  string `gorm:"not null"`
+	Actor      string `gorm:"index;not null;default:''"`
+	Action     string `gorm:"index;not null"`
+	ShortUrlId int64  `gorm:"index:idx_audit_events_short_url_id,priority:1;not null"`
+	// Slug is the slug the short URL had after the change, or before it for
+	// deletions.
+	Slug string `gorm:"not null"`
+	// Before and After are JSON snapshots of the short URL. Before is null
+	// for creations, and After is null once the short URL is gone.
+	Before null.String
+	After  null.String
+	// RequestId and IpAddress are empty for the scheduled jobs.
+	RequestId string `gorm:"not null;default:''"`
+	IpAddress string `gorm:"not null;default:''"`
+}
@@ -3,7 +3,20 @@ package models
 import "time"
 
 type Click struct {
-	Id         int64 `gorm:"primaryKey"`
-	ShortUrlId int64
-	CreatedAt  time.Time `gorm:"index:idx_clicks_created_at,sort:asc"`
+	Id             int64 `gorm:"primaryKey"`
+	ShortUrlId     int64
+	CreatedAt      time.Time `gorm:"index:idx_clicks_created_at,sort:asc"`
+	Referrer       string
+	UserAgent      string
+	IpAddress      string
+	AcceptLanguage string
+	// UtmCampaign is the utm_campaign parameter of the location the click
+	// was redirected to.
+	UtmCampaign string `gorm:"not null;default:''"`
+	// UtmSource is the utm_source parameter the short URL was requested
+	// with, such as qr for scans of its QR code, or else the one of the
+	// location the click was redirected to.
+	UtmSource string `gorm:"not null;default:''"`
+	// RolledUp is set once the click has been counted in click_rollups.
+	RolledUp bool `gorm:"not null;default:false;index:idx_clicks_not_rolled_up,where:rolled_up = false"`
 }
@@ -0,0 +1,11 @@
+package models
+
+import "time"
+
+// ClickRollup is the number of clicks a short URL received in the hour (UTC)
+// starting at Hour.
+type ClickRollup struct {
+	ShortUrlId int64     `gorm:"primaryKey;autoIncrement:false"`
+	Hour       time.Time `gorm:"primaryKey"`
+	Count      int64     `gorm:"not null"`
+}
@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// Namespace reserves the hierarchical slugs under a prefix, such as eng/oncall
+// under eng, for its owner.
+type Namespace struct {
+	Id int64 `json:"-"          gorm:"primaryKey"`
+	NamespaceCreateFields
+	CreatedAt time.Time `json:"created_at" format:"dateTime" example:"2022-05-11T11:30:00Z"`
+}
+
+type NamespaceCreateFields struct {
+	Prefix  string `json:"prefix"   gorm:"index:uq_namespaces_prefix,unique;not null" binding:"required" example:"eng"`
+	OwnerId string `json:"owner_id" gorm:"not null"                                   binding:"required" example:"platform-team"`
+}
+
+type NamespaceUpdateFields struct {
+	OwnerId *string `json:"owner_id" binding:"omitempty,min=1" example:"platform-team"`
+}
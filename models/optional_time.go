@@ -0,0 +1,17 @@
+package models
+
+import "gopkg.in/guregu/null.v4"
+
+// OptionalTime distinguishes a time that was omitted from a JSON payload
+// from one that was explicitly set to null, which PATCH requests need in
+// order to tell "leave it alone" apart from "clear it".
+type OptionalTime struct {
+	Set bool
+	null.Time
+}
+
+func (o *OptionalTime) UnmarshalJSON(data []byte) error {
+	o.Set = true
+
+	return o.Time.UnmarshalJSON(data)
+}
@@ -4,21 +4,84 @@ import (
 	"time"
 
 	"gopkg.in/guregu/null.v4"
+	"gorm.io/gorm"
 )
 
 type ShortUrl struct {
-	Id     int64   `json:"-"          gorm:"primaryKey"`
-	Clicks []Click `json:"-"          gorm:"constraint:OnDelete:CASCADE"`
+	Id           int64         `json:"-"          gorm:"primaryKey;index:idx_short_urls_created_at_id,priority:2"`
+	Clicks       []Click       `json:"-"          gorm:"constraint:OnDelete:CASCADE"`
+	ClickRollups []ClickRollup `json:"-"          gorm:"constraint:OnDelete:CASCADE"`
+	// OwnerId is the owner of the API key that created the short URL, or
+	// empty if it was created without one.
+	OwnerId string `json:"-"          gorm:"index;not null;default:''"`
+	// DeletedAt is set while the short URL is in the trash. Its slug stays
+	// taken until it's purged, but its long URL can be shortened again.
+	DeletedAt gorm.DeletedAt `json:"-"          gorm:"index"`
+	// SupersededAt is set once the short URL has expired and its long URL
+	// has been shortened again. It keeps answering with a 410 until it's
+	// deleted, but no longer counts as its long URL's short URL.
+	SupersededAt null.Time `json:"-"`
 	ShortUrlReadFields
 }
 
 type ShortUrlCreateFields struct {
-	LongUrl   string    `json:"long_url"   gorm:"index:uq_short_urls_long_url,unique;not null" binding:"required,url" example:"http://www.google.com" format:"url"`
+	LongUrl   string    `json:"long_url"   gorm:"index:uq_short_urls_current_destination,unique,where:deleted_at IS NULL AND superseded_at IS NULL,priority:1;not null" binding:"required,url" example:"http://www.google.com" format:"url"`
 	ExpiresOn null.Time `json:"expires_on" format:"dateTime" example:"2023-01-01T16:30:00Z"`
 	Slug      string    `json:"slug"       gorm:"index:uq_short_urls_slug,unique;not null"  example:"myslug" binding:""`
+	ShortUrlUtmFields
+	ShortUrlRedirectFields
+}
+
+// ShortUrlUtmFields are UTM parameters that are added to the long URL's query
+// string when redirecting. They're stored apart from the long URL, so one
+// long URL can have a short URL for each campaign it's shared in. Empty
+// fields aren't added.
+type ShortUrlUtmFields struct {
+	UtmSource   string `json:"utm_source,omitempty"   gorm:"index:uq_short_urls_current_destination,unique,priority:2;not null;default:''" example:"newsletter"`
+	UtmMedium   string `json:"utm_medium,omitempty"   gorm:"index:uq_short_urls_current_destination,unique,priority:3;not null;default:''" example:"email"`
+	UtmCampaign string `json:"utm_campaign,omitempty" gorm:"index:uq_short_urls_current_destination,unique,priority:4;not null;default:''" example:"spring_sale"`
+}
+
+// ShortUrlRedirectFields control how a short URL redirects. The redirect
+// type and Cache-Control fields follow the server's defaults when they're
+// left out, passthrough is off, and the long URL isn't a template.
+type ShortUrlRedirectFields struct {
+	// LinkType template makes the long URL a template with named
+	// placeholders, such as https://jira.corp/browse/{ticket}, which are
+	// filled from the path segments after the slug, in order, and then from
+	// the query parameters with the same names.
+	LinkType *string `json:"link_type,omitempty" binding:"omitempty,oneof=standard template" enums:"standard,template" example:"template"`
+	// RedirectType is the status code of the redirect. 307 and 308 keep the
+	// method and body of the request, so they suit API endpoints.
+	RedirectType *int `json:"redirect_type,omitempty" binding:"omitempty,oneof=301 302 307 308" enums:"301,302,307,308" example:"302"`
+	// CacheControl and CacheMaxAge (in seconds) make up the Cache-Control
+	// header of the redirect.
+	CacheControl *string `json:"cache_control,omitempty" binding:"omitempty,oneof=no-cache no-store private public" enums:"no-cache,no-store,private,public" example:"public"`
+	CacheMaxAge  *int    `json:"cache_max_age,omitempty" binding:"omitempty,min=0" example:"3600"`
+	// QueryPassthrough merges the query string of the request into the long
+	// URL's. When both have a parameter, prefer_incoming keeps the request's
+	// values, prefer_destination keeps the long URL's, and append keeps both.
+	QueryPassthrough *string `json:"query_passthrough,omitempty" binding:"omitempty,oneof=none prefer_incoming prefer_destination append" enums:"none,prefer_incoming,prefer_destination,append" example:"prefer_incoming"`
+	// PathPassthrough appends whatever follows the slug in the request's path
+	// to the long URL's path.
+	PathPassthrough *bool `json:"path_passthrough,omitempty" example:"true"`
+	// FallbackUrl is where the short URL sends visitors once it's expired,
+	// along with a 410 Gone.
+	FallbackUrl *string `json:"fallback_url,omitempty" binding:"omitempty,url" example:"http://www.google.com/expired" format:"url"`
+}
+
+type ShortUrlUpdateFields struct {
+	LongUrl   *string      `json:"long_url"   binding:"omitempty,url" example:"http://www.google.com" format:"url"`
+	ExpiresOn OptionalTime `json:"expires_on" swaggertype:"string" format:"dateTime" example:"2023-01-01T16:30:00Z"`
+	Slug      *string      `json:"slug"       binding:"omitempty,min=1" example:"myslug"`
+
+	UtmSource   *string `json:"utm_source"   example:"newsletter"`
+	UtmMedium   *string `json:"utm_medium"   example:"email"`
+	UtmCampaign *string `json:"utm_campaign" example:"spring_sale"`
+	ShortUrlRedirectFields
 }
 
 type ShortUrlReadFields struct {
 	ShortUrlCreateFields
-	CreatedAt time.Time `json:"created_at" format:"dateTime" example:"2022-05-11T11:30:00Z"`
+	CreatedAt time.Time `json:"created_at" gorm:"index:idx_short_urls_created_at_id,priority:1" format:"dateTime" example:"2022-05-11T11:30:00Z"`
 }
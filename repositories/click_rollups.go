@@ -0,0 +1,130 @@
+package repositories
+
+import (
+	"strings"
+	"time"
+	"url-shortener/models"
+)
+
+// Once clicks have been rolled up, click_rollups can count them an hour at a
+// time, but older raw clicks may have been pruned. To count clicks exactly,
+// a range of time is split into the whole hours it contains, which are
+// answered from click_rollups plus any clicks that haven't been rolled up
+// yet, and the partial hours at either end, which are answered from the raw
+// clicks. Ranges that start and end on the hour (in UTC) never need pruned
+// clicks.
+type rollupSplit struct {
+	start time.Time
+	end   time.Time
+	// [wholeHoursStart, wholeHoursEnd) are the whole hours in the range.
+	wholeHoursStart time.Time
+	wholeHoursEnd   time.Time
+}
+
+func splitForRollups(start time.Time, end time.Time) rollupSplit {
+	split := rollupSplit{start: start, end: end}
+
+	if !start.IsZero() {
+		split.wholeHoursStart = start.Truncate(time.Hour)
+
+		if split.wholeHoursStart.Before(start) {
+			split.wholeHoursStart = split.wholeHoursStart.Add(time.Hour)
+		}
+	}
+
+	if !end.IsZero() {
+		split.wholeHoursEnd = end.Truncate(time.Hour)
+	}
+
+	return split
+}
+
+func (split rollupSplit) hasWholeHours() bool {
+	if split.wholeHoursStart.IsZero() || split.wholeHoursEnd.IsZero() {
+		return true
+	}
+
+	return split.wholeHoursStart.Before(split.wholeHoursEnd)
+}
+
+// rollupCondition selects the click_rollups covering the whole hours.
+func (split rollupSplit) rollupCondition() (string, []interface{}) {
+	if !split.hasWholeHours() {
+		return "1 = 0", nil
+	}
+
+	return timeCondition("click_rollups.hour", split.wholeHoursStart, split.wholeHoursEnd)
+}
+
+// clicksCondition selects the raw clicks that aren't covered by the rollups
+// selected by rollupCondition.
+func (split rollupSplit) clicksCondition() (string, []interface{}) {
+	if !split.hasWholeHours() {
+		return timeCondition("clicks.created_at", split.start, split.end)
+	}
+
+	wholeHours, args := timeCondition("clicks.created_at", split.wholeHoursStart, split.wholeHoursEnd)
+	conditions := []string{"(clicks.rolled_up = FALSE AND " + wholeHours + ")"}
+
+	if !split.start.Equal(split.wholeHoursStart) {
+		condition, conditionArgs := timeCondition("clicks.created_at", split.start, split.wholeHoursStart)
+		conditions = append(conditions, "("+condition+")")
+		args = append(args, conditionArgs...)
+	}
+
+	if !split.end.Equal(split.wholeHoursEnd) {
+		condition, conditionArgs := timeCondition("clicks.created_at", split.wholeHoursEnd, split.end)
+		conditions = append(conditions, "("+condition+")")
+		args = append(args, conditionArgs...)
+	}
+
+	return "(" + strings.Join(conditions, " OR ") + ")", args
+}
+
+// timeCondition restricts column to [start, end), leaving out the bounds
+// that are zero.
+func timeCondition(column string, start time.Time, end time.Time) (string, []interface{}) {
+	conditions := []string{}
+	args := []interface{}{}
+
+	if !start.IsZero() {
+		conditions = append(conditions, column+" >= ?")
+		args = append(args, start)
+	}
+
+	if !end.IsZero() {
+		conditions = append(conditions, column+" < ?")
+		args = append(args, end)
+	}
+
+	if len(conditions) == 0 {
+		return "1 = 1", args
+	}
+
+	return strings.Join(conditions, " AND "), args
+}
+
+// coversRollup reports whether rollupCondition selects the rollup for hour,
+// for stores that don't speak SQL.
+func (split rollupSplit) coversRollup(hour time.Time) bool {
+	return split.hasWholeHours() && inRange(hour, split.wholeHoursStart, split.wholeHoursEnd)
+}
+
+// coversClick reports whether clicksCondition selects click.
+func (split rollupSplit) coversClick(click models.Click) bool {
+	if !split.hasWholeHours() {
+		return inRange(click.CreatedAt, split.start, split.end)
+	}
+
+	if !click.RolledUp && inRange(click.CreatedAt, split.wholeHoursStart, split.wholeHoursEnd) {
+		return true
+	}
+
+	return inRange(click.CreatedAt, split.start, split.wholeHoursStart) && !split.start.Equal(split.wholeHoursStart) ||
+		inRange(click.CreatedAt, split.wholeHoursEnd, split.end) && !split.end.Equal(split.wholeHoursEnd)
+}
+
+// inRange is timeCondition for a time that has already been read.
+func inRange(t time.Time, start time.Time, end time.Time) bool {
+	return (start.IsZero() || !t.Before(start)) && (end.IsZero() || t.Before(end))
+}
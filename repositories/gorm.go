@@ -0,0 +1,636 @@
+package repositories
+
+import (
+	"errors"
+	"strings"
+	"time"
+	"url-shortener/db"
+	"url-shortener/enums"
+	"url-shortener/models"
+
+	"gorm.io/gorm"
+)
+
+// clickCountExpression is the total number of clicks on a short URL, which
+// is spread across click_rollups and the clicks that haven't been rolled up
+// yet.
+const clickCountExpression = `(
+	COALESCE((
+		SELECT SUM(click_rollups.count)
+		FROM click_rollups
+		WHERE click_rollups.short_url_id = short_urls.id
+	), 0) + (
+		SELECT COUNT(*)
+		FROM clicks
+		WHERE clicks.short_url_id = short_urls.id AND clicks.rolled_up = FALSE
+	)
+)`
+
+// dialect covers what differs between the SQL databases that gormStore
+// supports.
+type dialect interface {
+	// uniqueConstraint returns the unique constraint that err violated, if
+	// any.
+	uniqueConstraint(err error) db.UniqueConstraintName
+	rollupClicks(tx *gorm.DB, batchSize int) (int64, error)
+}
+
+// gormStore stores everything in a SQL database through gorm.
+type gormStore struct {
+	db      *gorm.DB
+	dialect dialect
+}
+
+func (s *gormStore) ShortUrls() ShortUrlRepository {
+	return &gormShortUrlRepository{s}
+}
+
+func (s *gormStore) Clicks() ClickRepository {
+	return &gormClickRepository{s}
+}
+
+func (s *gormStore) ApiKeys() ApiKeyRepository {
+	return &gormApiKeyRepository{s}
+}
+
+func (s *gormStore) Namespaces() NamespaceRepository {
+	return &gormNamespaceRepository{s}
+}
+
+func (s *gormStore) AuditEvents() AuditEventRepository {
+	return &gormAuditEventRepository{s}
+}
+
+func (s *gormStore) Transaction(fn func(tx Store) error) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		return fn(&gormStore{db: tx, dialect: s.dialect})
+	})
+}
+
+// savepoint runs fn in a nested transaction, so that a failed statement
+// doesn't leave an enclosing transaction unusable (as it would in Postgres).
+func (s *gormStore) savepoint(fn func(tx *gorm.DB) error) error {
+	err := s.db.Transaction(fn)
+
+	switch s.dialect.uniqueConstraint(err) {
+	case db.DuplicateSlug:
+		return ErrDuplicateSlug
+	case db.DuplicateLongUrl:
+		return ErrDuplicateLongUrl
+	case db.DuplicatePrefix:
+		return ErrDuplicatePrefix
+	}
+
+	return err
+}
+
+func notFound(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrNotFound
+	}
+
+	return err
+}
+
+type gormShortUrlRepository struct {
+	*gormStore
+}
+
+func (r *gormShortUrlRepository) Create(shortUrl *models.ShortUrl) error {
+	return r.savepoint(func(tx *gorm.DB) error {
+		return tx.Create(shortUrl).Error
+	})
+}
+
+func (r *gormShortUrlRepository) FindById(id int64) (models.ShortUrl, error) {
+	var shortUrl models.ShortUrl
+
+	err := r.db.
+		Where("id = ?", id).
+		First(&shortUrl).Error
+
+	return shortUrl, notFound(err)
+}
+
+func (r *gormShortUrlRepository) FindBySlug(slug string) (models.ShortUrl, error) {
+	whereClause := models.ShortUrl{}
+	whereClause.Slug = slug
+
+	return r.find(whereClause)
+}
+
+func (r *gormShortUrlRepository) FindByLongUrl(longUrl string, utm models.ShortUrlUtmFields) (models.ShortUrl, error) {
+	var shortUrl models.ShortUrl
+
+	// A struct would leave out the UTM fields that are empty.
+	err := r.db.
+		Where(map[string]interface{}{
+			"long_url":     longUrl,
+			"utm_source":   utm.UtmSource,
+			"utm_medium":   utm.UtmMedium,
+			"utm_campaign": utm.UtmCampaign,
+		}).
+		Where("superseded_at IS NULL").
+		First(&shortUrl).Error
+
+	return shortUrl, notFound(err)
+}
+
+func (r *gormShortUrlRepository) find(whereClause models.ShortUrl) (models.ShortUrl, error) {
+	var shortUrl models.ShortUrl
+
+	err := r.db.
+		Where(&whereClause).
+		First(&shortUrl).Error
+
+	return shortUrl, notFound(err)
+}
+
+func (r *gormShortUrlRepository) Update(shortUrl *models.ShortUrl) error {
+	return r.savepoint(func(tx *gorm.DB) error {
+		return tx.
+			Model(shortUrl).
+			Select("*").
+			Omit("Id", "OwnerId", "CreatedAt", "DeletedAt", "Clicks", "ClickRollups").
+			Updates(shortUrl).Error
+	})
+}
+
+func (r *gormShortUrlRepository) Delete(shortUrl models.ShortUrl) error {
+	result := r.db.Delete(&shortUrl)
+
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+func (r *gormShortUrlRepository) FindDeletedBySlug(slug string) (models.ShortUrl, error) {
+	var shortUrl models.ShortUrl
+
+	err := r.db.
+		Unscoped().
+		Where("slug = ? AND deleted_at IS NOT NULL", slug).
+		First(&shortUrl).Error
+
+	return shortUrl, notFound(err)
+}
+
+func (r *gormShortUrlRepository) Restore(shortUrl *models.ShortUrl) error {
+	return r.savepoint(func(tx *gorm.DB) error {
+		result := tx.
+			Unscoped().
+			Model(&models.ShortUrl{}).
+			Where("id = ? AND deleted_at IS NOT NULL", shortUrl.Id).
+			Update("deleted_at", nil)
+
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if result.RowsAffected == 0 {
+			return ErrNotFound
+		}
+
+		shortUrl.DeletedAt = gorm.DeletedAt{}
+
+		return nil
+	})
+}
+
+// Purge finds the short URLs before deleting them, for the same reason as
+// DeleteExpired.
+func (r *gormShortUrlRepository) Purge(cutoff time.Time) ([]models.ShortUrl, error) {
+	return r.deletePermanently("deleted_at <= ?", cutoff)
+}
+
+// DeleteExpired finds the expired short URLs before deleting them, since
+// SQLite can't return them from the DELETE.
+func (r *gormShortUrlRepository) DeleteExpired(now time.Time) ([]models.ShortUrl, error) {
+	return r.deletePermanently("deleted_at IS NULL AND expires_on <= ?", now)
+}
+
+// deletePermanently deletes the short URLs matching condition, bypassing the
+// trash, and returns what they were.
+func (r *gormShortUrlRepository) deletePermanently(condition string, args ...interface{}) ([]models.ShortUrl, error) {
+	var shortUrls []models.ShortUrl
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.
+			Unscoped().
+			Where(condition, args...).
+			Find(&shortUrls).Error
+
+		if err != nil || len(shortUrls) == 0 {
+			return err
+		}
+
+		ids := make([]int64, len(shortUrls))
+
+		for i, shortUrl := range shortUrls {
+			ids[i] = shortUrl.Id
+		}
+
+		return tx.
+			Unscoped().
+			Where("id IN ?", ids).
+			Where(condition, args...).
+			Delete(&models.ShortUrl{}).Error
+	})
+
+	return shortUrls, err
+}
+
+func (r *gormShortUrlRepository) List(query ListQuery) ([]ListedShortUrl, error) {
+	tx := r.db.Table("short_urls")
+
+	sortColumn := map[enums.ShortUrlSort]string{
+		enums.ShortUrlSortCreatedAt: "short_urls.created_at",
+		enums.ShortUrlSortSlug:      "short_urls.slug",
+		enums.ShortUrlSortClicks:    clickCountExpression,
+	}[query.Sort]
+
+	if query.Sort == enums.ShortUrlSortClicks {
+		tx = tx.Select("short_urls.*, " + clickCountExpression + " AS click_count")
+	}
+
+	tx = applyListFilters(tx, query)
+
+	if query.After != nil {
+		comparison := ">"
+
+		if query.Order == enums.SortOrderDescending {
+			comparison = "<"
+		}
+
+		tx = tx.Where("("+sortColumn+", short_urls.id) "+comparison+" (?, ?)", sortValue(query), query.After.Id)
+	}
+
+	direction := " ASC"
+
+	if query.Order == enums.SortOrderDescending {
+		direction = " DESC"
+	}
+
+	rows := []ListedShortUrl{}
+
+	err := tx.
+		Order(sortColumn + direction).
+		Order("short_urls.id" + direction).
+		Limit(query.Limit).
+		Scan(&rows).Error
+
+	return rows, err
+}
+
+func applyListFilters(tx *gorm.DB, query ListQuery) *gorm.DB {
+	if query.Deleted {
+		tx = tx.Where("short_urls.deleted_at IS NOT NULL")
+	} else {
+		tx = tx.Where("short_urls.deleted_at IS NULL")
+	}
+
+	if query.OwnerId != nil {
+		tx = tx.Where("short_urls.owner_id = ?", *query.OwnerId)
+	}
+
+	if query.SlugPrefix != "" {
+		tx = tx.Where(`short_urls.slug LIKE ? ESCAPE '\'`, escapeLike(query.SlugPrefix)+"%")
+	}
+
+	if query.LongUrlContains != "" {
+		tx = tx.Where(
+			`LOWER(short_urls.long_url) LIKE ? ESCAPE '\'`,
+			"%"+escapeLike(strings.ToLower(query.LongUrlContains))+"%",
+		)
+	}
+
+	if !query.CreatedAfter.IsZero() {
+		tx = tx.Where("short_urls.created_at > ?", query.CreatedAfter)
+	}
+
+	if !query.CreatedBefore.IsZero() {
+		tx = tx.Where("short_urls.created_at < ?", query.CreatedBefore)
+	}
+
+	if !query.ExpiresBefore.IsZero() {
+		tx = tx.Where("short_urls.expires_on < ?", query.ExpiresBefore)
+	}
+
+	if query.HasExpiry != nil {
+		if *query.HasExpiry {
+			tx = tx.Where("short_urls.expires_on IS NOT NULL")
+		} else {
+			tx = tx.Where("short_urls.expires_on IS NULL")
+		}
+	}
+
+	return tx
+}
+
+// escapeLike escapes the characters that have a special meaning in a LIKE
+// pattern, so that user input only ever matches literally.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+func sortValue(query ListQuery) interface{} {
+	switch query.Sort {
+	case enums.ShortUrlSortSlug:
+		return query.After.Slug
+	case enums.ShortUrlSortClicks:
+		return query.After.ClickCount
+	}
+
+	return query.After.CreatedAt
+}
+
+func (r *gormShortUrlRepository) Each(ownerId *string, fn func(shortUrl ListedShortUrl) error) error {
+	tx := r.db.
+		Table("short_urls").
+		Select("short_urls.*, " + clickCountExpression + " AS click_count").
+		Where("short_urls.deleted_at IS NULL")
+
+	if ownerId != nil {
+		tx = tx.Where("short_urls.owner_id = ?", *ownerId)
+	}
+
+	rows, err := tx.Order("short_urls.id ASC").Rows()
+
+	if err != nil {
+		return err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var shortUrl ListedShortUrl
+
+		if err := r.db.ScanRows(rows, &shortUrl); err != nil {
+			return err
+		}
+
+		if err := fn(shortUrl); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+type gormClickRepository struct {
+	*gormStore
+}
+
+func (r *gormClickRepository) Create(clicks []models.Click) error {
+	return r.db.Create(&clicks).Error
+}
+
+// Count answers whole hours from click_rollups, so the count stays exact
+// after old raw clicks are pruned.
+func (r *gormClickRepository) Count(shortUrlId int64, start time.Time, end time.Time) (int64, error) {
+	split := splitForRollups(start, end)
+
+	rollupCondition, rollupArgs := split.rollupCondition()
+	clicksCondition, clicksArgs := split.clicksCondition()
+
+	args := append([]interface{}{shortUrlId}, rollupArgs...)
+	args = append(args, shortUrlId)
+	args = append(args, clicksArgs...)
+
+	var count int64
+
+	err := r.db.Raw(`
+			SELECT
+				COALESCE((
+					SELECT SUM(click_rollups.count)
+					FROM click_rollups
+					WHERE
+						click_rollups.short_url_id = ? AND
+						`+rollupCondition+`
+				), 0) + (
+					SELECT COUNT(*)
+					FROM clicks
+					WHERE
+						clicks.short_url_id = ? AND
+						`+clicksCondition+`
+				) AS count
+	`, args...).Scan(&count).Error
+
+	return count, err
+}
+
+func (r *gormClickRepository) Points(shortUrlId int64, start time.Time, end time.Time) ([]ClickPoint, error) {
+	split := splitForRollups(start, end)
+
+	rollupCondition, rollupArgs := split.rollupCondition()
+	clicksCondition, clicksArgs := split.clicksCondition()
+
+	points := []ClickPoint{}
+
+	err := r.db.
+		Model(&models.ClickRollup{}).
+		Select("click_rollups.hour AS time, click_rollups.count AS count").
+		Where("click_rollups.short_url_id = ?", shortUrlId).
+		Where(rollupCondition, rollupArgs...).
+		Scan(&points).Error
+
+	if err != nil {
+		return nil, err
+	}
+
+	var clickTimes []time.Time
+
+	err = r.db.
+		Model(&models.Click{}).
+		Where("clicks.short_url_id = ?", shortUrlId).
+		Where(clicksCondition, clicksArgs...).
+		Pluck("clicks.created_at", &clickTimes).Error
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, clickTime := range clickTimes {
+		points = append(points, ClickPoint{Time: clickTime, Count: 1})
+	}
+
+	return points, nil
+}
+
+func (r *gormClickRepository) Breakdown(
+	shortUrlId int64,
+	dimension enums.ClickDimension,
+	start time.Time,
+	end time.Time,
+) ([]ClickCount, error) {
+	column := map[enums.ClickDimension]string{
+		enums.ClickDimensionReferrer:  "referrer",
+		enums.ClickDimensionUserAgent: "user_agent",
+		enums.ClickDimensionLanguage:  "accept_language",
+		enums.ClickDimensionCampaign:  "utm_campaign",
+		enums.ClickDimensionSource:    "utm_source",
+	}[dimension]
+
+	condition, args := timeCondition("clicks.created_at", start, end)
+
+	counts := []ClickCount{}
+
+	err := r.db.Raw(`
+			SELECT clicks.`+column+` AS value, COUNT(clicks.id) AS count
+			FROM clicks
+			WHERE
+				clicks.short_url_id = ? AND
+				`+condition+`
+			GROUP BY clicks.`+column+`
+			ORDER BY count DESC, value ASC
+	`, append([]interface{}{shortUrlId}, args...)...).Scan(&counts).Error
+
+	return counts, err
+}
+
+func (r *gormClickRepository) CreateRollup(rollup models.ClickRollup) error {
+	return r.db.Create(&rollup).Error
+}
+
+func (r *gormClickRepository) Rollup(batchSize int) (int64, error) {
+	return r.dialect.rollupClicks(r.db, batchSize)
+}
+
+func (r *gormClickRepository) Prune(cutoff time.Time) (int64, error) {
+	result := r.db.Exec(`
+			DELETE FROM clicks
+			WHERE rolled_up = TRUE AND created_at < ?
+	`, cutoff)
+
+	return result.RowsAffected, result.Error
+}
+
+type gormApiKeyRepository struct {
+	*gormStore
+}
+
+func (r *gormApiKeyRepository) Create(apiKey *models.ApiKey) error {
+	return r.db.Create(apiKey).Error
+}
+
+func (r *gormApiKeyRepository) FindByKeyHash(keyHash string) (models.ApiKey, error) {
+	var apiKey models.ApiKey
+
+	err := r.db.
+		Where("key_hash = ?", keyHash).
+		First(&apiKey).Error
+
+	return apiKey, notFound(err)
+}
+
+type gormNamespaceRepository struct {
+	*gormStore
+}
+
+func (r *gormNamespaceRepository) Create(namespace *models.Namespace) error {
+	return r.savepoint(func(tx *gorm.DB) error {
+		return tx.Create(namespace).Error
+	})
+}
+
+func (r *gormNamespaceRepository) FindByPrefix(prefix string) (models.Namespace, error) {
+	var namespace models.Namespace
+
+	err := r.db.
+		Where("prefix = ?", prefix).
+		First(&namespace).Error
+
+	return namespace, notFound(err)
+}
+
+func (r *gormNamespaceRepository) List() ([]models.Namespace, error) {
+	namespaces := []models.Namespace{}
+
+	err := r.db.
+		Order("prefix").
+		Find(&namespaces).Error
+
+	return namespaces, err
+}
+
+func (r *gormNamespaceRepository) Update(namespace *models.Namespace) error {
+	return r.db.
+		Model(&models.Namespace{}).
+		Where("prefix = ?", namespace.Prefix).
+		Update("owner_id", namespace.OwnerId).Error
+}
+
+func (r *gormNamespaceRepository) Delete(namespace models.Namespace) error {
+	result := r.db.
+		Where("prefix = ?", namespace.Prefix).
+		Delete(&models.Namespace{})
+
+	if result.Error == nil && result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+
+	return result.Error
+}
+
+type gormAuditEventRepository struct {
+	*gormStore
+}
+
+func (r *gormAuditEventRepository) Create(events []models.AuditEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	return r.db.Create(&events).Error
+}
+
+func (r *gormAuditEventRepository) List(query AuditQuery) ([]models.AuditEvent, error) {
+	tx := r.db.Model(&models.AuditEvent{})
+
+	if query.ShortUrlId != 0 {
+		tx = tx.Where("short_url_id = ?", query.ShortUrlId)
+	}
+
+	if query.Slug != "" {
+		tx = tx.Where("slug = ?", query.Slug)
+	}
+
+	if query.Actor != nil {
+		tx = tx.Where("actor = ?", *query.Actor)
+	}
+
+	if query.Action != "" {
+		tx = tx.Where("action = ?", query.Action)
+	}
+
+	if query.RequestId != "" {
+		tx = tx.Where("request_id = ?", query.RequestId)
+	}
+
+	if !query.CreatedAfter.IsZero() {
+		tx = tx.Where("created_at > ?", query.CreatedAfter)
+	}
+
+	if !query.CreatedBefore.IsZero() {
+		tx = tx.Where("created_at < ?", query.CreatedBefore)
+	}
+
+	if query.BeforeId != 0 {
+		tx = tx.Where("id < ?", query.BeforeId)
+	}
+
+	events := []models.AuditEvent{}
+
+	err := tx.
+		Order("id DESC").
+		Limit(query.Limit).
+		Find(&events).Error
+
+	return events, err
+}
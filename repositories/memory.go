@@ -0,0 +1,1038 @@
+package repositories
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"url-shortener/enums"
+	"url-shortener/models"
+
+	"gorm.io/gorm"
+)
+
+var (
+	errMissingShortUrl     = errors.New("short url does not exist")
+	errDuplicateRollup     = errors.New("rollup already exists")
+	errDuplicateApiKey     = errors.New("api key already exists")
+	errTransactionFinished = errors.New("transaction has already finished")
+)
+
+// memoryData is everything a memoryStore holds. Clicks and rollups are kept
+// per short URL so that deleting one cascades to them, as it does in SQL.
+type memoryData struct {
+	// shortUrls include the ones in the trash, whose slugs stay taken.
+	shortUrls map[int64]models.ShortUrl
+	slugs     map[string]int64
+	// destinations are unique among the short URLs that aren't in the trash
+	// or superseded, like uq_short_urls_current_destination.
+	destinations map[destination]int64
+	// clicks are in order of id. Their slices are never changed in place,
+	// other than to mark clicks as rolled up, so that a slice that's been
+	// replaced can be put back.
+	clicks map[int64][]models.Click
+	// rollups are keyed by the Unix time of their hour.
+	rollups map[int64]map[int64]models.ClickRollup
+
+	apiKeys   map[int64]models.ApiKey
+	keyHashes map[string]int64
+
+	// namespaces are keyed by prefix.
+	namespaces map[string]models.Namespace
+
+	// auditEvents are in order of id.
+	auditEvents []models.AuditEvent
+
+	lastShortUrlId   int64
+	lastClickId      int64
+	lastApiKeyId     int64
+	lastNamespaceId  int64
+	lastAuditEventId int64
+
+	// undo is the log of the innermost transaction in progress, or nil
+	// outside of one.
+	undo *undoLog
+}
+
+func newMemoryData() *memoryData {
+	return &memoryData{
+		shortUrls:    map[int64]models.ShortUrl{},
+		slugs:        map[string]int64{},
+		destinations: map[destination]int64{},
+		clicks:       map[int64][]models.Click{},
+		rollups:      map[int64]map[int64]models.ClickRollup{},
+		apiKeys:      map[int64]models.ApiKey{},
+		keyHashes:    map[string]int64{},
+		namespaces:   map[string]models.Namespace{},
+	}
+}
+
+// remember adds undo to the log of the transaction in progress, if there is
+// one. It's called before every change to d.
+func (d *memoryData) remember(undo func()) {
+	if d.undo != nil {
+		*d.undo = append(*d.undo, undo)
+	}
+}
+
+// nextId increments the id counter at last and returns the new id.
+func (d *memoryData) nextId(last *int64) int64 {
+	previous := *last
+	d.remember(func() { *last = previous })
+	*last++
+
+	return *last
+}
+
+// setKey sets m[key], one of d's maps, to value.
+func setKey[K comparable, V any](d *memoryData, m map[K]V, key K, value V) {
+	rememberKey(d, m, key)
+	m[key] = value
+}
+
+// deleteKey removes key from m, one of d's maps.
+func deleteKey[K comparable, V any](d *memoryData, m map[K]V, key K) {
+	rememberKey(d, m, key)
+	delete(m, key)
+}
+
+func rememberKey[K comparable, V any](d *memoryData, m map[K]V, key K) {
+	previous, ok := m[key]
+
+	d.remember(func() {
+		if ok {
+			m[key] = previous
+		} else {
+			delete(m, key)
+		}
+	})
+}
+
+func (d *memoryData) clickCount(shortUrlId int64) int64 {
+	var count int64
+
+	for _, rollup := range d.rollups[shortUrlId] {
+		count += rollup.Count
+	}
+
+	for _, click := range d.clicks[shortUrlId] {
+		if !click.RolledUp {
+			count++
+		}
+	}
+
+	return count
+}
+
+func (d *memoryData) deleteShortUrl(shortUrl models.ShortUrl) {
+	deleteKey(d, d.shortUrls, shortUrl.Id)
+	deleteKey(d, d.slugs, shortUrl.Slug)
+	d.removeDestination(shortUrl)
+	deleteKey(d, d.clicks, shortUrl.Id)
+	deleteKey(d, d.rollups, shortUrl.Id)
+}
+
+// removeDestination frees shortUrl's destination, unless another short URL
+// took it while shortUrl was in the trash.
+func (d *memoryData) removeDestination(shortUrl models.ShortUrl) {
+	if d.destinations[destinationOf(shortUrl)] == shortUrl.Id {
+		deleteKey(d, d.destinations, destinationOf(shortUrl))
+	}
+}
+
+// memoryStore keeps everything in memory, for tests and for deployments
+// that don't need to keep anything across restarts.
+//
+// A transaction holds the store's lock until it finishes, and changes the
+// data in place while logging how to undo each change. Rolling it back
+// replays its log backwards, and committing a nested transaction hands its
+// log to the enclosing one. Transactions therefore run one at a time, which
+// is simple rather than fast.
+type memoryStore struct {
+	lock *sync.RWMutex
+	data *memoryData
+	// inTransaction is set on the stores passed to Transaction, which
+	// already hold lock.
+	inTransaction bool
+	finished      bool
+}
+
+func NewMemoryStore() Store {
+	return &memoryStore{
+		lock: &sync.RWMutex{},
+		data: newMemoryData(),
+	}
+}
+
+func (s *memoryStore) ShortUrls() ShortUrlRepository {
+	return &memoryShortUrlRepository{s}
+}
+
+func (s *memoryStore) Clicks() ClickRepository {
+	return &memoryClickRepository{s}
+}
+
+func (s *memoryStore) ApiKeys() ApiKeyRepository {
+	return &memoryApiKeyRepository{s}
+}
+
+func (s *memoryStore) Namespaces() NamespaceRepository {
+	return &memoryNamespaceRepository{s}
+}
+
+func (s *memoryStore) AuditEvents() AuditEventRepository {
+	return &memoryAuditEventRepository{s}
+}
+
+func (s *memoryStore) Transaction(fn func(tx Store) error) error {
+	return s.write(func(data *memoryData) error {
+		tx := &memoryStore{
+			lock:          s.lock,
+			data:          data,
+			inTransaction: true,
+		}
+
+		outer := data.undo
+		undo := undoLog{}
+		data.undo = &undo
+		committed := false
+
+		// Rolling back here also covers fn panicking.
+		defer func() {
+			tx.finished = true
+			data.undo = outer
+
+			if !committed {
+				undo.rollback()
+			}
+		}()
+
+		if err := fn(tx); err != nil {
+			return err
+		}
+
+		committed = true
+
+		if outer != nil {
+			*outer = append(*outer, undo...)
+		}
+
+		return nil
+	})
+}
+
+// undoLog holds what undoes each change made in a transaction, in the order
+// the changes were made.
+type undoLog []func()
+
+func (l undoLog) rollback() {
+	for i := len(l) - 1; i >= 0; i-- {
+		l[i]()
+	}
+}
+
+func (s *memoryStore) read(fn func(data *memoryData) error) error {
+	if s.finished {
+		return errTransactionFinished
+	}
+
+	if !s.inTransaction {
+		s.lock.RLock()
+		defer s.lock.RUnlock()
+	}
+
+	return fn(s.data)
+}
+
+func (s *memoryStore) write(fn func(data *memoryData) error) error {
+	if s.finished {
+		return errTransactionFinished
+	}
+
+	if !s.inTransaction {
+		s.lock.Lock()
+		defer s.lock.Unlock()
+	}
+
+	return fn(s.data)
+}
+
+// memoryNow matches the precision of the times that Postgres stores.
+func memoryNow() time.Time {
+	return time.Now().Truncate(time.Microsecond)
+}
+
+type memoryShortUrlRepository struct {
+	*memoryStore
+}
+
+// destination is where a short URL redirects to: its long URL, tagged with
+// its UTM parameters.
+type destination struct {
+	longUrl string
+	utm     models.ShortUrlUtmFields
+}
+
+func destinationOf(shortUrl models.ShortUrl) destination {
+	return destination{longUrl: shortUrl.LongUrl, utm: shortUrl.ShortUrlUtmFields}
+}
+
+// holdsDestination is whether shortUrl is the one that its destination is
+// taken by.
+func holdsDestination(shortUrl models.ShortUrl) bool {
+	return !shortUrl.DeletedAt.Valid && !shortUrl.SupersededAt.Valid
+}
+
+// checkUnique returns the error that saving shortUrl would violate a unique
+// constraint with.
+func checkUnique(data *memoryData, shortUrl *models.ShortUrl) error {
+	if id, ok := data.slugs[shortUrl.Slug]; ok && id != shortUrl.Id {
+		return ErrDuplicateSlug
+	}
+
+	if id, ok := data.destinations[destinationOf(*shortUrl)]; ok && id != shortUrl.Id && holdsDestination(*shortUrl) {
+		return ErrDuplicateLongUrl
+	}
+
+	return nil
+}
+
+func (r *memoryShortUrlRepository) Create(shortUrl *models.ShortUrl) error {
+	return r.write(func(data *memoryData) error {
+		if err := checkUnique(data, shortUrl); err != nil {
+			return err
+		}
+
+		shortUrl.Id = data.nextId(&data.lastShortUrlId)
+
+		if shortUrl.CreatedAt.IsZero() {
+			shortUrl.CreatedAt = memoryNow()
+		}
+
+		stored := *shortUrl
+		stored.Clicks = nil
+		stored.ClickRollups = nil
+
+		setKey(data, data.shortUrls, stored.Id, stored)
+		setKey(data, data.slugs, stored.Slug, stored.Id)
+		setKey(data, data.destinations, destinationOf(stored), stored.Id)
+
+		return nil
+	})
+}
+
+func (r *memoryShortUrlRepository) FindById(id int64) (models.ShortUrl, error) {
+	return r.find(func(data *memoryData) (int64, bool) {
+		shortUrl, ok := data.shortUrls[id]
+
+		return id, ok && !shortUrl.DeletedAt.Valid
+	})
+}
+
+func (r *memoryShortUrlRepository) FindBySlug(slug string) (models.ShortUrl, error) {
+	return r.find(func(data *memoryData) (int64, bool) {
+		id, ok := data.slugs[slug]
+
+		return id, ok && !data.shortUrls[id].DeletedAt.Valid
+	})
+}
+
+func (r *memoryShortUrlRepository) FindDeletedBySlug(slug string) (models.ShortUrl, error) {
+	return r.find(func(data *memoryData) (int64, bool) {
+		id, ok := data.slugs[slug]
+
+		return id, ok && data.shortUrls[id].DeletedAt.Valid
+	})
+}
+
+func (r *memoryShortUrlRepository) FindByLongUrl(longUrl string, utm models.ShortUrlUtmFields) (models.ShortUrl, error) {
+	return r.find(func(data *memoryData) (int64, bool) {
+		id, ok := data.destinations[destination{longUrl: longUrl, utm: utm}]
+
+		return id, ok
+	})
+}
+
+func (r *memoryShortUrlRepository) find(lookup func(data *memoryData) (int64, bool)) (models.ShortUrl, error) {
+	var shortUrl models.ShortUrl
+
+	err := r.read(func(data *memoryData) error {
+		id, ok := lookup(data)
+
+		if !ok {
+			return ErrNotFound
+		}
+
+		shortUrl = data.shortUrls[id]
+
+		return nil
+	})
+
+	return shortUrl, err
+}
+
+func (r *memoryShortUrlRepository) Update(shortUrl *models.ShortUrl) error {
+	return r.write(func(data *memoryData) error {
+		stored, ok := data.shortUrls[shortUrl.Id]
+
+		if !ok || stored.DeletedAt.Valid {
+			return nil
+		}
+
+		if err := checkUnique(data, shortUrl); err != nil {
+			return err
+		}
+
+		deleteKey(data, data.slugs, stored.Slug)
+		data.removeDestination(stored)
+
+		stored.ShortUrlCreateFields = shortUrl.ShortUrlCreateFields
+		stored.SupersededAt = shortUrl.SupersededAt
+
+		setKey(data, data.shortUrls, stored.Id, stored)
+		setKey(data, data.slugs, stored.Slug, stored.Id)
+
+		if holdsDestination(stored) {
+			setKey(data, data.destinations, destinationOf(stored), stored.Id)
+		}
+
+		return nil
+	})
+}
+
+// Delete keeps the slug of shortUrl, but frees its destination.
+func (r *memoryShortUrlRepository) Delete(shortUrl models.ShortUrl) error {
+	return r.write(func(data *memoryData) error {
+		stored, ok := data.shortUrls[shortUrl.Id]
+
+		if !ok || stored.DeletedAt.Valid {
+			return ErrNotFound
+		}
+
+		stored.DeletedAt = gorm.DeletedAt{Time: memoryNow(), Valid: true}
+
+		setKey(data, data.shortUrls, stored.Id, stored)
+		data.removeDestination(stored)
+
+		return nil
+	})
+}
+
+func (r *memoryShortUrlRepository) Restore(shortUrl *models.ShortUrl) error {
+	return r.write(func(data *memoryData) error {
+		stored, ok := data.shortUrls[shortUrl.Id]
+
+		if !ok || !stored.DeletedAt.Valid {
+			return ErrNotFound
+		}
+
+		stored.DeletedAt = gorm.DeletedAt{}
+
+		if _, ok := data.destinations[destinationOf(stored)]; ok && holdsDestination(stored) {
+			return ErrDuplicateLongUrl
+		}
+
+		setKey(data, data.shortUrls, stored.Id, stored)
+
+		if holdsDestination(stored) {
+			setKey(data, data.destinations, destinationOf(stored), stored.Id)
+		}
+		shortUrl.DeletedAt = stored.DeletedAt
+
+		return nil
+	})
+}
+
+func (r *memoryShortUrlRepository) Purge(cutoff time.Time) ([]models.ShortUrl, error) {
+	return r.deletePermanently(func(shortUrl models.ShortUrl) bool {
+		return shortUrl.DeletedAt.Valid && !shortUrl.DeletedAt.Time.After(cutoff)
+	})
+}
+
+func (r *memoryShortUrlRepository) DeleteExpired(now time.Time) ([]models.ShortUrl, error) {
+	return r.deletePermanently(func(shortUrl models.ShortUrl) bool {
+		return !shortUrl.DeletedAt.Valid && shortUrl.ExpiresOn.Valid && !shortUrl.ExpiresOn.Time.After(now)
+	})
+}
+
+func (r *memoryShortUrlRepository) deletePermanently(matches func(shortUrl models.ShortUrl) bool) ([]models.ShortUrl, error) {
+	var deleted []models.ShortUrl
+
+	err := r.write(func(data *memoryData) error {
+		for _, shortUrl := range data.shortUrls {
+			if matches(shortUrl) {
+				data.deleteShortUrl(shortUrl)
+				deleted = append(deleted, shortUrl)
+			}
+		}
+
+		return nil
+	})
+
+	return deleted, err
+}
+
+func (r *memoryShortUrlRepository) List(query ListQuery) ([]ListedShortUrl, error) {
+	rows := []ListedShortUrl{}
+
+	err := r.read(func(data *memoryData) error {
+		for _, shortUrl := range data.shortUrls {
+			if !matchesListFilters(shortUrl, query) {
+				continue
+			}
+
+			row := ListedShortUrl{ShortUrl: shortUrl}
+
+			if query.Sort == enums.ShortUrlSortClicks {
+				row.ClickCount = data.clickCount(shortUrl.Id)
+			}
+
+			if query.After != nil && !listsAfter(row, *query.After, query) {
+				continue
+			}
+
+			rows = append(rows, row)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(rows, func(i, j int) bool {
+		return listsAfter(rows[j], rows[i], query)
+	})
+
+	if query.Limit > 0 && len(rows) > query.Limit {
+		rows = rows[:query.Limit]
+	}
+
+	return rows, nil
+}
+
+// matchesListFilters is applyListFilters for a single short URL.
+func matchesListFilters(shortUrl models.ShortUrl, query ListQuery) bool {
+	if shortUrl.DeletedAt.Valid != query.Deleted {
+		return false
+	}
+
+	if query.OwnerId != nil && shortUrl.OwnerId != *query.OwnerId {
+		return false
+	}
+
+	if !strings.HasPrefix(shortUrl.Slug, query.SlugPrefix) {
+		return false
+	}
+
+	if !strings.Contains(strings.ToLower(shortUrl.LongUrl), strings.ToLower(query.LongUrlContains)) {
+		return false
+	}
+
+	if !query.CreatedAfter.IsZero() && !shortUrl.CreatedAt.After(query.CreatedAfter) {
+		return false
+	}
+
+	if !query.CreatedBefore.IsZero() && !shortUrl.CreatedAt.Before(query.CreatedBefore) {
+		return false
+	}
+
+	if !query.ExpiresBefore.IsZero() && !(shortUrl.ExpiresOn.Valid && shortUrl.ExpiresOn.Time.Before(query.ExpiresBefore)) {
+		return false
+	}
+
+	if query.HasExpiry != nil && shortUrl.ExpiresOn.Valid != *query.HasExpiry {
+		return false
+	}
+
+	return true
+}
+
+// listsAfter reports whether row comes after position in the order that
+// query sorts by, ties being broken by id.
+func listsAfter(row ListedShortUrl, position ListedShortUrl, query ListQuery) bool {
+	comparison := 0
+
+	switch query.Sort {
+	case enums.ShortUrlSortSlug:
+		comparison = strings.Compare(row.Slug, position.Slug)
+	case enums.ShortUrlSortClicks:
+		comparison = compareInt64(row.ClickCount, position.ClickCount)
+	default:
+		comparison = compareInt64(row.CreatedAt.UnixNano(), position.CreatedAt.UnixNano())
+	}
+
+	if comparison == 0 {
+		comparison = compareInt64(row.Id, position.Id)
+	}
+
+	if query.Order == enums.SortOrderDescending {
+		return comparison < 0
+	}
+
+	return comparison > 0
+}
+
+func compareInt64(a int64, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+
+	return 0
+}
+
+// Each reads every short URL up front, so that fn can take as long as it
+// likes without holding up writes.
+func (r *memoryShortUrlRepository) Each(ownerId *string, fn func(shortUrl ListedShortUrl) error) error {
+	var rows []ListedShortUrl
+
+	err := r.read(func(data *memoryData) error {
+		for _, shortUrl := range data.shortUrls {
+			if shortUrl.DeletedAt.Valid || (ownerId != nil && shortUrl.OwnerId != *ownerId) {
+				continue
+			}
+
+			rows = append(rows, ListedShortUrl{
+				ShortUrl:   shortUrl,
+				ClickCount: data.clickCount(shortUrl.Id),
+			})
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return err
+	}
+
+	sort.Slice(rows, func(i, j int) bool {
+		return rows[i].Id < rows[j].Id
+	})
+
+	for _, row := range rows {
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+type memoryClickRepository struct {
+	*memoryStore
+}
+
+func (r *memoryClickRepository) Create(clicks []models.Click) error {
+	return r.write(func(data *memoryData) error {
+		for _, click := range clicks {
+			if _, ok := data.shortUrls[click.ShortUrlId]; !ok {
+				return errMissingShortUrl
+			}
+		}
+
+		for i := range clicks {
+			clicks[i].Id = data.nextId(&data.lastClickId)
+
+			if clicks[i].CreatedAt.IsZero() {
+				clicks[i].CreatedAt = memoryNow()
+			}
+
+			setKey(data, data.clicks, clicks[i].ShortUrlId, append(data.clicks[clicks[i].ShortUrlId], clicks[i]))
+		}
+
+		return nil
+	})
+}
+
+func (r *memoryClickRepository) Count(shortUrlId int64, start time.Time, end time.Time) (int64, error) {
+	var count int64
+
+	err := r.eachPoint(shortUrlId, start, end, func(point ClickPoint) {
+		count += point.Count
+	})
+
+	return count, err
+}
+
+func (r *memoryClickRepository) Points(shortUrlId int64, start time.Time, end time.Time) ([]ClickPoint, error) {
+	points := []ClickPoint{}
+
+	err := r.eachPoint(shortUrlId, start, end, func(point ClickPoint) {
+		points = append(points, point)
+	})
+
+	return points, err
+}
+
+// eachPoint calls fn with the rollups and raw clicks that count the clicks on
+// a short URL in [start, end).
+func (r *memoryClickRepository) eachPoint(shortUrlId int64, start time.Time, end time.Time, fn func(point ClickPoint)) error {
+	split := splitForRollups(start, end)
+
+	return r.read(func(data *memoryData) error {
+		for _, rollup := range data.rollups[shortUrlId] {
+			if split.coversRollup(rollup.Hour) {
+				fn(ClickPoint{Time: rollup.Hour, Count: rollup.Count})
+			}
+		}
+
+		for _, click := range data.clicks[shortUrlId] {
+			if split.coversClick(click) {
+				fn(ClickPoint{Time: click.CreatedAt, Count: 1})
+			}
+		}
+
+		return nil
+	})
+}
+
+func (r *memoryClickRepository) Breakdown(
+	shortUrlId int64,
+	dimension enums.ClickDimension,
+	start time.Time,
+	end time.Time,
+) ([]ClickCount, error) {
+	value := map[enums.ClickDimension]func(click models.Click) string{
+		enums.ClickDimensionReferrer:  func(click models.Click) string { return click.Referrer },
+		enums.ClickDimensionUserAgent: func(click models.Click) string { return click.UserAgent },
+		enums.ClickDimensionLanguage:  func(click models.Click) string { return click.AcceptLanguage },
+		enums.ClickDimensionCampaign:  func(click models.Click) string { return click.UtmCampaign },
+		enums.ClickDimensionSource:    func(click models.Click) string { return click.UtmSource },
+	}[dimension]
+
+	totals := map[string]int64{}
+
+	err := r.read(func(data *memoryData) error {
+		for _, click := range data.clicks[shortUrlId] {
+			if inRange(click.CreatedAt, start, end) {
+				totals[value(click)]++
+			}
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	counts := []ClickCount{}
+
+	for value, count := range totals {
+		counts = append(counts, ClickCount{Value: value, Count: count})
+	}
+
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].Count != counts[j].Count {
+			return counts[i].Count > counts[j].Count
+		}
+
+		return counts[i].Value < counts[j].Value
+	})
+
+	return counts, nil
+}
+
+func (r *memoryClickRepository) CreateRollup(rollup models.ClickRollup) error {
+	return r.write(func(data *memoryData) error {
+		if _, ok := data.shortUrls[rollup.ShortUrlId]; !ok {
+			return errMissingShortUrl
+		}
+
+		if _, ok := data.rollups[rollup.ShortUrlId][rollup.Hour.Unix()]; ok {
+			return errDuplicateRollup
+		}
+
+		addRollup(data, rollup)
+
+		return nil
+	})
+}
+
+func addRollup(data *memoryData, rollup models.ClickRollup) {
+	rollups, ok := data.rollups[rollup.ShortUrlId]
+
+	if !ok {
+		rollups = map[int64]models.ClickRollup{}
+		setKey(data, data.rollups, rollup.ShortUrlId, rollups)
+	}
+
+	existing, ok := rollups[rollup.Hour.Unix()]
+
+	if ok {
+		rollup.Hour = existing.Hour
+		rollup.Count += existing.Count
+	}
+
+	setKey(data, rollups, rollup.Hour.Unix(), rollup)
+}
+
+func (r *memoryClickRepository) Rollup(batchSize int) (int64, error) {
+	var changed int64
+
+	err := r.write(func(data *memoryData) error {
+		var batch []*models.Click
+
+		for shortUrlId := range data.clicks {
+			clicks := data.clicks[shortUrlId]
+
+			for i := range clicks {
+				if !clicks[i].RolledUp {
+					batch = append(batch, &clicks[i])
+				}
+			}
+		}
+
+		sort.Slice(batch, func(i, j int) bool {
+			return batch[i].Id < batch[j].Id
+		})
+
+		if len(batch) > batchSize {
+			batch = batch[:batchSize]
+		}
+
+		type rollupKey struct {
+			shortUrlId int64
+			hour       int64
+		}
+
+		changes := map[rollupKey]bool{}
+
+		for i := range batch {
+			click := batch[i]
+			hour := click.CreatedAt.UTC().Truncate(time.Hour)
+
+			addRollup(data, models.ClickRollup{ShortUrlId: click.ShortUrlId, Hour: hour, Count: 1})
+			changes[rollupKey{click.ShortUrlId, hour.Unix()}] = true
+
+			data.remember(func() { click.RolledUp = false })
+			click.RolledUp = true
+		}
+
+		changed = int64(len(changes))
+
+		return nil
+	})
+
+	return changed, err
+}
+
+func (r *memoryClickRepository) Prune(cutoff time.Time) (int64, error) {
+	var pruned int64
+
+	err := r.write(func(data *memoryData) error {
+		for shortUrlId, clicks := range data.clicks {
+			kept := make([]models.Click, 0, len(clicks))
+
+			for _, click := range clicks {
+				if click.RolledUp && click.CreatedAt.Before(cutoff) {
+					pruned++
+				} else {
+					kept = append(kept, click)
+				}
+			}
+
+			if len(kept) < len(clicks) {
+				setKey(data, data.clicks, shortUrlId, kept)
+			}
+		}
+
+		return nil
+	})
+
+	return pruned, err
+}
+
+type memoryApiKeyRepository struct {
+	*memoryStore
+}
+
+func (r *memoryApiKeyRepository) Create(apiKey *models.ApiKey) error {
+	return r.write(func(data *memoryData) error {
+		if _, ok := data.keyHashes[apiKey.KeyHash]; ok {
+			return errDuplicateApiKey
+		}
+
+		apiKey.Id = data.nextId(&data.lastApiKeyId)
+
+		if apiKey.CreatedAt.IsZero() {
+			apiKey.CreatedAt = memoryNow()
+		}
+
+		setKey(data, data.apiKeys, apiKey.Id, *apiKey)
+		setKey(data, data.keyHashes, apiKey.KeyHash, apiKey.Id)
+
+		return nil
+	})
+}
+
+func (r *memoryApiKeyRepository) FindByKeyHash(keyHash string) (models.ApiKey, error) {
+	var apiKey models.ApiKey
+
+	err := r.read(func(data *memoryData) error {
+		id, ok := data.keyHashes[keyHash]
+
+		if !ok {
+			return ErrNotFound
+		}
+
+		apiKey = data.apiKeys[id]
+
+		return nil
+	})
+
+	return apiKey, err
+}
+
+type memoryNamespaceRepository struct {
+	*memoryStore
+}
+
+func (r *memoryNamespaceRepository) Create(namespace *models.Namespace) error {
+	return r.write(func(data *memoryData) error {
+		if _, ok := data.namespaces[namespace.Prefix]; ok {
+			return ErrDuplicatePrefix
+		}
+
+		namespace.Id = data.nextId(&data.lastNamespaceId)
+
+		if namespace.CreatedAt.IsZero() {
+			namespace.CreatedAt = memoryNow()
+		}
+
+		setKey(data, data.namespaces, namespace.Prefix, *namespace)
+
+		return nil
+	})
+}
+
+func (r *memoryNamespaceRepository) FindByPrefix(prefix string) (models.Namespace, error) {
+	var namespace models.Namespace
+
+	err := r.read(func(data *memoryData) error {
+		var ok bool
+
+		if namespace, ok = data.namespaces[prefix]; !ok {
+			return ErrNotFound
+		}
+
+		return nil
+	})
+
+	return namespace, err
+}
+
+func (r *memoryNamespaceRepository) List() ([]models.Namespace, error) {
+	namespaces := []models.Namespace{}
+
+	err := r.read(func(data *memoryData) error {
+		for _, namespace := range data.namespaces {
+			namespaces = append(namespaces, namespace)
+		}
+
+		return nil
+	})
+
+	sort.Slice(namespaces, func(i, j int) bool {
+		return namespaces[i].Prefix < namespaces[j].Prefix
+	})
+
+	return namespaces, err
+}
+
+func (r *memoryNamespaceRepository) Update(namespace *models.Namespace) error {
+	return r.write(func(data *memoryData) error {
+		stored, ok := data.namespaces[namespace.Prefix]
+
+		if !ok {
+			return nil
+		}
+
+		stored.OwnerId = namespace.OwnerId
+		setKey(data, data.namespaces, stored.Prefix, stored)
+
+		return nil
+	})
+}
+
+func (r *memoryNamespaceRepository) Delete(namespace models.Namespace) error {
+	return r.write(func(data *memoryData) error {
+		if _, ok := data.namespaces[namespace.Prefix]; !ok {
+			return ErrNotFound
+		}
+
+		deleteKey(data, data.namespaces, namespace.Prefix)
+
+		return nil
+	})
+}
+
+type memoryAuditEventRepository struct {
+	*memoryStore
+}
+
+func (r *memoryAuditEventRepository) Create(events []models.AuditEvent) error {
+	return r.write(func(data *memoryData) error {
+		now := memoryNow()
+		count := len(data.auditEvents)
+
+		data.remember(func() { data.auditEvents = data.auditEvents[:count] })
+
+		for i := range events {
+			events[i].Id = data.nextId(&data.lastAuditEventId)
+
+			if events[i].CreatedAt.IsZero() {
+				events[i].CreatedAt = now
+			}
+
+			data.auditEvents = append(data.auditEvents, events[i])
+		}
+
+		return nil
+	})
+}
+
+func (r *memoryAuditEventRepository) List(query AuditQuery) ([]models.AuditEvent, error) {
+	events := []models.AuditEvent{}
+
+	err := r.read(func(data *memoryData) error {
+		for i := len(data.auditEvents) - 1; i >= 0 && len(events) < query.Limit; i-- {
+			if event := data.auditEvents[i]; matchesAuditQuery(event, query) {
+				events = append(events, event)
+			}
+		}
+
+		return nil
+	})
+
+	return events, err
+}
+
+// matchesAuditQuery is the filters of an AuditQuery for a single event.
+func matchesAuditQuery(event models.AuditEvent, query AuditQuery) bool {
+	switch {
+	case query.ShortUrlId != 0 && event.ShortUrlId != query.ShortUrlId:
+		return false
+	case query.Slug != "" && event.Slug != query.Slug:
+		return false
+	case query.Actor != nil && event.Actor != *query.Actor:
+		return false
+	case query.Action != "" && event.Action != query.Action:
+		return false
+	case query.RequestId != "" && event.RequestId != query.RequestId:
+		return false
+	case !query.CreatedAfter.IsZero() && !event.CreatedAt.After(query.CreatedAfter):
+		return false
+	case !query.CreatedBefore.IsZero() && !event.CreatedAt.Before(query.CreatedBefore):
+		return false
+	case query.BeforeId != 0 && event.Id >= query.BeforeId:
+		return false
+	}
+
+	return true
+}
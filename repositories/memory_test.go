@@ -0,0 +1,171 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+	"url-shortener/models"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/guregu/null.v4"
+)
+
+func TestMemoryStoreIsSafeForConcurrentUse(t *testing.T) {
+	store := NewMemoryStore()
+
+	var wg sync.WaitGroup
+
+	// Every worker tries to claim the same slug, and only one may win.
+	created := make(chan bool, 20)
+
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+
+		go func(i int) {
+			defer wg.Done()
+
+			err := store.Transaction(func(tx Store) error {
+				shortUrl := newShortUrl("shared", fmt.Sprintf("https://%d.example.com", i))
+
+				if err := tx.ShortUrls().Create(shortUrl); err != nil {
+					return err
+				}
+
+				return tx.Clicks().Create([]models.Click{{ShortUrlId: shortUrl.Id}})
+			})
+
+			if err == nil {
+				created <- true
+			} else {
+				assert.ErrorIs(t, err, ErrDuplicateSlug)
+			}
+
+			_, err = store.ShortUrls().FindBySlug("shared")
+			assert.Nil(t, err)
+		}(i)
+	}
+
+	wg.Wait()
+	close(created)
+
+	assert.Len(t, created, 1)
+
+	rows, err := store.ShortUrls().List(ListQuery{})
+
+	assert.Nil(t, err)
+	assert.Len(t, rows, 1)
+}
+
+func TestMemoryStoreRejectsClicksOnMissingShortUrl(t *testing.T) {
+	store := NewMemoryStore()
+
+	shortUrl := newShortUrl("google", "https://www.google.com")
+	assert.Nil(t, store.ShortUrls().Create(shortUrl))
+
+	// Like a foreign key, a missing short URL fails the whole batch.
+	err := store.Clicks().Create([]models.Click{{ShortUrlId: shortUrl.Id}, {ShortUrlId: shortUrl.Id + 1}})
+	assert.ErrorIs(t, err, errMissingShortUrl)
+
+	count, err := store.Clicks().Count(shortUrl.Id, time.Time{}, time.Time{})
+
+	assert.Nil(t, err)
+	assert.Equal(t, int64(0), count)
+}
+
+func TestMemoryStoreRollsBackEveryChange(t *testing.T) {
+	created := time.Date(2022, 5, 10, 12, 0, 0, 0, time.UTC)
+
+	populate := func() Store {
+		store := NewMemoryStore()
+
+		for _, slug := range []string{"google", "github", "expiring"} {
+			shortUrl := newShortUrl(slug, "https://"+slug+".example.com")
+			shortUrl.CreatedAt = created
+			assert.Nil(t, store.ShortUrls().Create(shortUrl))
+
+			clicks := []models.Click{
+				{ShortUrlId: shortUrl.Id, CreatedAt: created},
+				{ShortUrlId: shortUrl.Id, CreatedAt: created.Add(time.Hour)},
+			}
+			assert.Nil(t, store.Clicks().Create(clicks))
+		}
+
+		_, err := store.Clicks().Rollup(1)
+		assert.Nil(t, err)
+
+		assert.Nil(t, store.ApiKeys().Create(&models.ApiKey{KeyHash: "hash", CreatedAt: created}))
+		assert.Nil(t, store.Namespaces().Create(&models.Namespace{
+			NamespaceCreateFields: models.NamespaceCreateFields{Prefix: "eng", OwnerId: "alice"},
+			CreatedAt:             created,
+		}))
+		assert.Nil(t, store.AuditEvents().Create([]models.AuditEvent{{ShortUrlId: 1, Slug: "google", CreatedAt: created}}))
+
+		return store
+	}
+
+	expected := populate()
+	store := populate()
+	rollback := errors.New("rollback")
+
+	err := store.Transaction(func(tx Store) error {
+		// Only the first click on google has been rolled up, so the second
+		// is kept.
+		pruned, err := tx.Clicks().Prune(created.Add(time.Minute))
+		assert.Nil(t, err)
+		assert.Equal(t, int64(1), pruned)
+
+		google, err := tx.ShortUrls().FindBySlug("google")
+		assert.Nil(t, err)
+
+		google.Slug = "renamed"
+		assert.Nil(t, tx.ShortUrls().Update(&google))
+
+		github, err := tx.ShortUrls().FindBySlug("github")
+		assert.Nil(t, err)
+		assert.Nil(t, tx.ShortUrls().Delete(github))
+
+		_, err = tx.ShortUrls().Purge(created.AddDate(1, 0, 0))
+		assert.Nil(t, err)
+
+		expiring, err := tx.ShortUrls().FindBySlug("expiring")
+		assert.Nil(t, err)
+
+		expiring.ExpiresOn = null.TimeFrom(created)
+		assert.Nil(t, tx.ShortUrls().Update(&expiring))
+
+		_, err = tx.ShortUrls().DeleteExpired(created)
+		assert.Nil(t, err)
+
+		err = tx.Transaction(func(tx Store) error {
+			shortUrl := newShortUrl("nested", "https://nested.example.com")
+			assert.Nil(t, tx.ShortUrls().Create(shortUrl))
+			assert.Nil(t, tx.Clicks().Create([]models.Click{{ShortUrlId: shortUrl.Id, CreatedAt: created}}))
+			assert.Nil(t, tx.Clicks().CreateRollup(models.ClickRollup{ShortUrlId: shortUrl.Id, Hour: created, Count: 1}))
+
+			_, err := tx.Clicks().Rollup(100)
+			assert.Nil(t, err)
+
+			_, err = tx.Clicks().Prune(created.AddDate(1, 0, 0))
+			assert.Nil(t, err)
+
+			return nil
+		})
+		assert.Nil(t, err)
+
+		assert.Nil(t, tx.ApiKeys().Create(&models.ApiKey{KeyHash: "other"}))
+
+		namespace := models.Namespace{NamespaceCreateFields: models.NamespaceCreateFields{Prefix: "eng", OwnerId: "bob"}}
+		assert.Nil(t, tx.Namespaces().Update(&namespace))
+		assert.Nil(t, tx.Namespaces().Delete(namespace))
+		assert.Nil(t, tx.Namespaces().Create(&models.Namespace{NamespaceCreateFields: models.NamespaceCreateFields{Prefix: "ops"}}))
+
+		assert.Nil(t, tx.AuditEvents().Create([]models.AuditEvent{{ShortUrlId: 1, Slug: "renamed"}}))
+
+		return rollback
+	})
+
+	assert.ErrorIs(t, err, rollback)
+	assert.Equal(t, expected.(*memoryStore).data, store.(*memoryStore).data)
+}
@@ -0,0 +1,57 @@
+package repositories
+
+import (
+	"errors"
+	"url-shortener/db"
+
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgerrcode"
+	"gorm.io/gorm"
+)
+
+func NewPostgresStore(gormDB *gorm.DB) Store {
+	return &gormStore{db: gormDB, dialect: postgresDialect{}}
+}
+
+type postgresDialect struct{}
+
+func (postgresDialect) uniqueConstraint(err error) db.UniqueConstraintName {
+	var pgErr *pgconn.PgError
+
+	if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.UniqueViolation {
+		return db.ParseString(pgErr.ConstraintName)
+	}
+
+	return db.None
+}
+
+// rollupClicks marks the clicks as rolled up and adds them to the rollups in a
+// single statement, so a click is never counted twice or skipped, even if
+// it's inserted while the rollup is running.
+func (postgresDialect) rollupClicks(tx *gorm.DB, batchSize int) (int64, error) {
+	result := tx.Exec(`
+			WITH rolled_up AS (
+				UPDATE clicks
+				SET rolled_up = TRUE
+				WHERE id IN (
+					SELECT id
+					FROM clicks
+					WHERE rolled_up = FALSE
+					ORDER BY id
+					LIMIT ?
+				)
+				RETURNING short_url_id, created_at
+			)
+			INSERT INTO click_rollups (short_url_id, hour, count)
+			SELECT
+				short_url_id,
+				date_trunc('hour', created_at AT TIME ZONE 'UTC') AT TIME ZONE 'UTC',
+				COUNT(*)
+			FROM rolled_up
+			GROUP BY 1, 2
+			ON CONFLICT (short_url_id, hour)
+				DO UPDATE SET count = click_rollups.count + EXCLUDED.count
+	`, batchSize)
+
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,171 @@
+// Package repositories keeps the services independent of the database that
+// short URLs and clicks are stored in.
+package repositories
+
+import (
+	"errors"
+	"time"
+	"url-shortener/enums"
+	"url-shortener/models"
+)
+
+var (
+	ErrNotFound         = errors.New("record not found")
+	ErrDuplicateSlug    = errors.New("slug is already used by another short url")
+	ErrDuplicateLongUrl = errors.New("long url is already used by another short url")
+	ErrDuplicatePrefix  = errors.New("prefix is already used by another namespace")
+)
+
+// Store gives access to every repository.
+type Store interface {
+	ShortUrls() ShortUrlRepository
+	Clicks() ClickRepository
+	ApiKeys() ApiKeyRepository
+	Namespaces() NamespaceRepository
+	AuditEvents() AuditEventRepository
+	// Transaction runs fn with a Store whose repositories all share a single
+	// transaction, which is committed if fn returns nil and rolled back
+	// otherwise. Transactions can be nested, in which case the inner one only
+	// rolls back its own changes.
+	Transaction(fn func(tx Store) error) error
+}
+
+type ShortUrlRepository interface {
+	// Create inserts shortUrl, filling in its id and creation time. It
+	// returns ErrDuplicateSlug or ErrDuplicateLongUrl if another short URL
+	// already uses either, in which case an enclosing transaction can still be
+	// used.
+	Create(shortUrl *models.ShortUrl) error
+	FindById(id int64) (models.ShortUrl, error)
+	FindBySlug(slug string) (models.ShortUrl, error)
+	// FindByLongUrl finds the short URL to longUrl that's tagged with
+	// exactly utm, leaving out superseded ones.
+	FindByLongUrl(longUrl string, utm models.ShortUrlUtmFields) (models.ShortUrl, error)
+	// Update saves the fields of shortUrl that can change after it's created:
+	// everything but its id, owner, and creation time. Like Create, it
+	// returns ErrDuplicateSlug or ErrDuplicateLongUrl on conflicts.
+	Update(shortUrl *models.ShortUrl) error
+	// Delete moves shortUrl to the trash, keeping its clicks, or returns
+	// ErrNotFound if it has already been deleted. Short URLs in the trash
+	// aren't found or listed, except by FindDeletedBySlug and a ListQuery for
+	// Deleted ones.
+	Delete(shortUrl models.ShortUrl) error
+	FindDeletedBySlug(slug string) (models.ShortUrl, error)
+	// Restore takes shortUrl out of the trash. It returns ErrNotFound if it
+	// isn't in the trash, or ErrDuplicateLongUrl if its long URL has been
+	// shortened again since it was deleted.
+	Restore(shortUrl *models.ShortUrl) error
+	// Purge permanently deletes every short URL that was moved to the trash
+	// at or before cutoff, along with its clicks, returning what they were.
+	Purge(cutoff time.Time) ([]models.ShortUrl, error)
+	// DeleteExpired permanently deletes every short URL that expired at or
+	// before now, returning what they were. Short URLs in the trash are left
+	// for Purge.
+	DeleteExpired(now time.Time) ([]models.ShortUrl, error)
+	List(query ListQuery) ([]ListedShortUrl, error)
+	// Each calls fn with every short URL owned by ownerId, or every short URL
+	// if ownerId is nil, in order of id, stopping at the first error. Short
+	// URLs are read as they're needed rather than all at once.
+	Each(ownerId *string, fn func(shortUrl ListedShortUrl) error) error
+}
+
+type ClickRepository interface {
+	// Create inserts clicks together.
+	Create(clicks []models.Click) error
+	// Count counts the clicks on a short URL in [start, end). Either bound may
+	// be the zero time to leave that side of the range open.
+	Count(shortUrlId int64, start time.Time, end time.Time) (int64, error)
+	// Points returns when the clicks on a short URL in [start, end) were
+	// made, in no particular order.
+	Points(shortUrlId int64, start time.Time, end time.Time) ([]ClickPoint, error)
+	// Breakdown counts the clicks on a short URL in [start, end) grouped by
+	// dimension, from most to least common. Clicks that have been pruned
+	// aren't included.
+	Breakdown(shortUrlId int64, dimension enums.ClickDimension, start time.Time, end time.Time) ([]ClickCount, error)
+	CreateRollup(rollup models.ClickRollup) error
+	// Rollup adds up to batchSize clicks that haven't been rolled up yet to
+	// the hourly rollups, returning the number of rollups that changed.
+	Rollup(batchSize int) (int64, error)
+	// Prune deletes clicks made before cutoff that have been rolled up,
+	// returning how many there were.
+	Prune(cutoff time.Time) (int64, error)
+}
+
+type ApiKeyRepository interface {
+	Create(apiKey *models.ApiKey) error
+	FindByKeyHash(keyHash string) (models.ApiKey, error)
+}
+
+type NamespaceRepository interface {
+	// Create inserts namespace, returning ErrDuplicatePrefix if its prefix is
+	// already taken.
+	Create(namespace *models.Namespace) error
+	FindByPrefix(prefix string) (models.Namespace, error)
+	// List returns every namespace in order of prefix.
+	List() ([]models.Namespace, error)
+	// Update saves the owner of namespace.
+	Update(namespace *models.Namespace) error
+	// Delete deletes namespace, or returns ErrNotFound if it has already been
+	// deleted. The short URLs in it are left alone.
+	Delete(namespace models.Namespace) error
+}
+
+type AuditEventRepository interface {
+	// Create inserts events together.
+	Create(events []models.AuditEvent) error
+	// List returns the events matching query, newest first.
+	List(query AuditQuery) ([]models.AuditEvent, error)
+}
+
+// ListQuery selects a page of short URLs. Zero values leave a filter out.
+type ListQuery struct {
+	OwnerId         *string
+	SlugPrefix      string
+	LongUrlContains string
+	CreatedAfter    time.Time
+	CreatedBefore   time.Time
+	ExpiresBefore   time.Time
+	HasExpiry       *bool
+	// Deleted lists the short URLs in the trash instead of the others.
+	Deleted bool
+
+	Sort  enums.ShortUrlSort
+	Order enums.SortOrder
+	// After continues the list from just after this short URL, which only
+	// needs its id and the field being sorted by.
+	After *ListedShortUrl
+	Limit int
+}
+
+// AuditQuery selects a page of audit events. Zero values leave a filter out.
+type AuditQuery struct {
+	ShortUrlId    int64
+	Slug          string
+	Actor         *string
+	Action        string
+	RequestId     string
+	CreatedAfter  time.Time
+	CreatedBefore time.Time
+
+	// BeforeId continues the list from just before the event with this id.
+	BeforeId int64
+	Limit    int
+}
+
+type ListedShortUrl struct {
+	models.ShortUrl
+	// ClickCount is only filled in when sorting by clicks, and when exporting.
+	ClickCount int64
+}
+
+// ClickPoint is a number of clicks made at a point in time: either a single
+// raw click, or an hourly rollup counted at the start of its hour.
+type ClickPoint struct {
+	Time  time.Time
+	Count int64
+}
+
+type ClickCount struct {
+	Value string
+	Count int64
+}
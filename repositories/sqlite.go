@@ -0,0 +1,92 @@
+package repositories
+
+import (
+	"errors"
+	"strings"
+	"url-shortener/db"
+
+	"github.com/mattn/go-sqlite3"
+	"gorm.io/gorm"
+)
+
+// NewSqliteStore stores everything in a database opened by db.ConnectSqlite.
+func NewSqliteStore(gormDB *gorm.DB) Store {
+	return &gormStore{db: gormDB, dialect: sqliteDialect{}}
+}
+
+type sqliteDialect struct{}
+
+// SQLite doesn't report the name of the index behind a unique constraint, only
+// the columns in it, e.g. "UNIQUE constraint failed: short_urls.slug".
+func (sqliteDialect) uniqueConstraint(err error) db.UniqueConstraintName {
+	var sqliteErr sqlite3.Error
+
+	if !errors.As(err, &sqliteErr) || sqliteErr.ExtendedCode != sqlite3.ErrConstraintUnique {
+		return db.None
+	}
+
+	switch {
+	case strings.HasSuffix(sqliteErr.Error(), "short_urls.slug"):
+		return db.DuplicateSlug
+	case strings.Contains(sqliteErr.Error(), "short_urls.long_url,"):
+		return db.DuplicateLongUrl
+	case strings.HasSuffix(sqliteErr.Error(), "namespaces.prefix"):
+		return db.DuplicatePrefix
+	}
+
+	return db.None
+}
+
+// rollupClicks can't update and insert in a single statement like Postgres
+// does, but SQLite only has one writer at a time, so doing both in a
+// transaction is just as safe. Hours are formatted the same way the driver
+// formats times in UTC.
+func (sqliteDialect) rollupClicks(tx *gorm.DB, batchSize int) (int64, error) {
+	var rollups int64
+
+	err := tx.Transaction(func(tx *gorm.DB) error {
+		var lastId int64
+
+		err := tx.Raw(`
+				SELECT COALESCE(MAX(id), 0)
+				FROM (
+					SELECT id
+					FROM clicks
+					WHERE rolled_up = FALSE
+					ORDER BY id
+					LIMIT ?
+				)
+		`, batchSize).Scan(&lastId).Error
+
+		if err != nil || lastId == 0 {
+			return err
+		}
+
+		result := tx.Exec(`
+				INSERT INTO click_rollups (short_url_id, hour, count)
+				SELECT
+					short_url_id,
+					strftime('%Y-%m-%d %H:00:00+00:00', created_at),
+					COUNT(*)
+				FROM clicks
+				WHERE rolled_up = FALSE AND id <= ?
+				GROUP BY 1, 2
+				ON CONFLICT (short_url_id, hour)
+					DO UPDATE SET count = click_rollups.count + excluded.count
+		`, lastId)
+
+		if result.Error != nil {
+			return result.Error
+		}
+
+		rollups = result.RowsAffected
+
+		return tx.Exec(`
+				UPDATE clicks
+				SET rolled_up = TRUE
+				WHERE rolled_up = FALSE AND id <= ?
+		`, lastId).Error
+	})
+
+	return rollups, err
+}
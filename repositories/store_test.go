@@ -0,0 +1,459 @@
+package repositories
+
+import (
+	"errors"
+	"testing"
+	"time"
+	"url-shortener/db"
+	"url-shortener/enums"
+	"url-shortener/models"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/guregu/null.v4"
+)
+
+func newSqliteStore(t *testing.T) Store {
+	gormDB, err := db.ConnectSqlite(":memory:")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return NewSqliteStore(gormDB)
+}
+
+// eachStore runs test against every store that doesn't need Docker.
+func eachStore(t *testing.T, test func(t *testing.T, store Store)) {
+	t.Run("sqlite", func(t *testing.T) {
+		test(t, newSqliteStore(t))
+	})
+
+	t.Run("memory", func(t *testing.T) {
+		test(t, NewMemoryStore())
+	})
+}
+
+func newShortUrl(slug string, longUrl string) *models.ShortUrl {
+	shortUrl := &models.ShortUrl{}
+	shortUrl.Slug = slug
+	shortUrl.LongUrl = longUrl
+
+	return shortUrl
+}
+
+func TestReportsDuplicates(t *testing.T) {
+	eachStore(t, func(t *testing.T, store Store) {
+		assert.Nil(t, store.ShortUrls().Create(newShortUrl("google", "https://www.google.com")))
+
+		err := store.Transaction(func(tx Store) error {
+			assert.ErrorIs(t, tx.ShortUrls().Create(newShortUrl("google", "https://www.github.com")), ErrDuplicateSlug)
+			assert.ErrorIs(t, tx.ShortUrls().Create(newShortUrl("other", "https://www.google.com")), ErrDuplicateLongUrl)
+
+			// The transaction is still usable after a duplicate.
+			return tx.ShortUrls().Create(newShortUrl("github", "https://www.github.com"))
+		})
+
+		assert.Nil(t, err)
+
+		_, err = store.ShortUrls().FindBySlug("github")
+		assert.Nil(t, err)
+
+		github, _ := store.ShortUrls().FindBySlug("github")
+		github.Slug = "google"
+
+		assert.ErrorIs(t, store.ShortUrls().Update(&github), ErrDuplicateSlug)
+	})
+}
+
+func TestAllowsLongUrlOncePerUtmTagging(t *testing.T) {
+	eachStore(t, func(t *testing.T, store Store) {
+		spring := models.ShortUrlUtmFields{UtmSource: "newsletter", UtmCampaign: "spring"}
+
+		untagged := newShortUrl("google", "https://www.google.com")
+		tagged := newShortUrl("google-spring", "https://www.google.com")
+		tagged.ShortUrlUtmFields = spring
+		duplicate := newShortUrl("google-spring-2", "https://www.google.com")
+		duplicate.ShortUrlUtmFields = spring
+
+		assert.Nil(t, store.ShortUrls().Create(untagged))
+		assert.Nil(t, store.ShortUrls().Create(tagged))
+		assert.ErrorIs(t, store.ShortUrls().Create(duplicate), ErrDuplicateLongUrl)
+
+		found, err := store.ShortUrls().FindByLongUrl("https://www.google.com", models.ShortUrlUtmFields{})
+		assert.Nil(t, err)
+		assert.Equal(t, "google", found.Slug)
+
+		found, err = store.ShortUrls().FindByLongUrl("https://www.google.com", spring)
+		assert.Nil(t, err)
+		assert.Equal(t, "google-spring", found.Slug)
+
+		_, err = store.ShortUrls().FindByLongUrl("https://www.google.com", models.ShortUrlUtmFields{UtmCampaign: "spring"})
+		assert.ErrorIs(t, err, ErrNotFound)
+
+		untagged.ShortUrlUtmFields = spring
+		assert.ErrorIs(t, store.ShortUrls().Update(untagged), ErrDuplicateLongUrl)
+	})
+}
+
+func TestKeepsClicksOfDeletedShortUrlUntilPurged(t *testing.T) {
+	eachStore(t, func(t *testing.T, store Store) {
+		shortUrl := newShortUrl("google", "https://www.google.com")
+		assert.Nil(t, store.ShortUrls().Create(shortUrl))
+		assert.Nil(t, store.Clicks().Create([]models.Click{{ShortUrlId: shortUrl.Id}, {ShortUrlId: shortUrl.Id}}))
+
+		assert.Nil(t, store.ShortUrls().Delete(*shortUrl))
+		assert.ErrorIs(t, store.ShortUrls().Delete(*shortUrl), ErrNotFound)
+
+		count, err := store.Clicks().Count(shortUrl.Id, time.Time{}, time.Time{})
+
+		assert.Nil(t, err)
+		assert.Equal(t, int64(2), count)
+
+		purged, err := store.ShortUrls().Purge(time.Now().Add(-time.Hour))
+
+		assert.Nil(t, err)
+		assert.Empty(t, purged)
+
+		purged, err = store.ShortUrls().Purge(time.Now())
+
+		assert.Nil(t, err)
+		if assert.Len(t, purged, 1) {
+			assert.Equal(t, "google", purged[0].Slug)
+		}
+
+		count, err = store.Clicks().Count(shortUrl.Id, time.Time{}, time.Time{})
+
+		assert.Nil(t, err)
+		assert.Equal(t, int64(0), count)
+
+		_, err = store.ShortUrls().FindDeletedBySlug("google")
+		assert.ErrorIs(t, err, ErrNotFound)
+	})
+}
+
+func TestTrashKeepsSlugsAndFreesLongUrls(t *testing.T) {
+	eachStore(t, func(t *testing.T, store Store) {
+		deleted := newShortUrl("google", "https://www.google.com")
+		assert.Nil(t, store.ShortUrls().Create(deleted))
+		assert.Nil(t, store.ShortUrls().Delete(*deleted))
+
+		_, err := store.ShortUrls().FindBySlug("google")
+		assert.ErrorIs(t, err, ErrNotFound)
+
+		_, err = store.ShortUrls().FindByLongUrl("https://www.google.com", models.ShortUrlUtmFields{})
+		assert.ErrorIs(t, err, ErrNotFound)
+
+		found, err := store.ShortUrls().FindDeletedBySlug("google")
+		assert.Nil(t, err)
+		assert.True(t, found.DeletedAt.Valid)
+
+		assert.ErrorIs(t, store.ShortUrls().Create(newShortUrl("google", "https://www.github.com")), ErrDuplicateSlug)
+
+		replacement := newShortUrl("google-2", "https://www.google.com")
+		assert.Nil(t, store.ShortUrls().Create(replacement))
+		assert.ErrorIs(t, store.ShortUrls().Restore(&found), ErrDuplicateLongUrl)
+
+		listed, err := store.ShortUrls().List(ListQuery{Deleted: true})
+		assert.Nil(t, err)
+		assert.Len(t, listed, 1)
+		assert.Equal(t, "google", listed[0].Slug)
+
+		listed, err = store.ShortUrls().List(ListQuery{})
+		assert.Nil(t, err)
+		assert.Len(t, listed, 1)
+		assert.Equal(t, "google-2", listed[0].Slug)
+
+		assert.Nil(t, store.ShortUrls().Delete(*replacement))
+		assert.Nil(t, store.ShortUrls().Restore(&found))
+		assert.ErrorIs(t, store.ShortUrls().Restore(&found), ErrNotFound)
+
+		restored, err := store.ShortUrls().FindBySlug("google")
+		assert.Nil(t, err)
+		assert.False(t, restored.DeletedAt.Valid)
+	})
+}
+
+func TestSupersededShortUrlsFreeTheirLongUrls(t *testing.T) {
+	eachStore(t, func(t *testing.T, store Store) {
+		superseded := newShortUrl("google", "https://www.google.com")
+		assert.Nil(t, store.ShortUrls().Create(superseded))
+
+		superseded.SupersededAt = null.TimeFrom(time.Now())
+		assert.Nil(t, store.ShortUrls().Update(superseded))
+
+		_, err := store.ShortUrls().FindByLongUrl("https://www.google.com", models.ShortUrlUtmFields{})
+		assert.ErrorIs(t, err, ErrNotFound)
+
+		found, err := store.ShortUrls().FindBySlug("google")
+		assert.Nil(t, err)
+		assert.True(t, found.SupersededAt.Valid)
+
+		assert.Nil(t, store.ShortUrls().Create(newShortUrl("google-2", "https://www.google.com")))
+
+		found.SupersededAt = null.Time{}
+		assert.ErrorIs(t, store.ShortUrls().Update(&found), ErrDuplicateLongUrl)
+	})
+}
+
+func TestDeletesExpiredShortUrlsAcrossTimeZones(t *testing.T) {
+	eachStore(t, func(t *testing.T, store Store) {
+		now := time.Date(2022, 5, 10, 12, 0, 0, 0, time.UTC)
+		tokyo := time.FixedZone("Asia/Tokyo", 9*60*60)
+
+		// 20:30 in Tokyo is 11:30 UTC, so this has expired even though its wall
+		// clock time is after now's.
+		expired := newShortUrl("expired", "https://expired.example.com")
+		expired.ExpiresOn = null.TimeFrom(time.Date(2022, 5, 10, 20, 30, 0, 0, tokyo))
+
+		current := newShortUrl("current", "https://current.example.com")
+		current.ExpiresOn = null.TimeFrom(time.Date(2022, 5, 10, 12, 30, 0, 0, time.UTC))
+
+		for _, shortUrl := range []*models.ShortUrl{expired, current, newShortUrl("forever", "https://forever.example.com")} {
+			assert.Nil(t, store.ShortUrls().Create(shortUrl))
+		}
+
+		deleted, err := store.ShortUrls().DeleteExpired(now)
+
+		assert.Nil(t, err)
+		if assert.Len(t, deleted, 1) {
+			assert.Equal(t, expired.Id, deleted[0].Id)
+		}
+
+		_, err = store.ShortUrls().FindBySlug("expired")
+		assert.ErrorIs(t, err, ErrNotFound)
+	})
+}
+
+func TestRollsUpAndPrunesClicks(t *testing.T) {
+	eachStore(t, func(t *testing.T, store Store) {
+		shortUrl := newShortUrl("google", "https://www.google.com")
+		assert.Nil(t, store.ShortUrls().Create(shortUrl))
+
+		hour := time.Date(2022, 5, 10, 12, 0, 0, 0, time.UTC)
+		clicks := []models.Click{
+			{ShortUrlId: shortUrl.Id, CreatedAt: hour.Add(10 * time.Minute)},
+			{ShortUrlId: shortUrl.Id, CreatedAt: hour.Add(50 * time.Minute)},
+			{ShortUrlId: shortUrl.Id, CreatedAt: hour.Add(70 * time.Minute)},
+		}
+
+		assert.Nil(t, store.Clicks().Create(clicks))
+
+		rollups, err := store.Clicks().Rollup(2)
+
+		assert.Nil(t, err)
+		assert.Equal(t, int64(1), rollups)
+
+		// The third click lands in the next hour's rollup, and a later click in
+		// an existing hour adds to it.
+		assert.Nil(t, store.Clicks().Create([]models.Click{{ShortUrlId: shortUrl.Id, CreatedAt: hour.Add(20 * time.Minute)}}))
+
+		rollups, err = store.Clicks().Rollup(10)
+
+		assert.Nil(t, err)
+		assert.Equal(t, int64(2), rollups)
+
+		pruned, err := store.Clicks().Prune(hour.Add(2 * time.Hour))
+
+		assert.Nil(t, err)
+		assert.Equal(t, int64(4), pruned)
+
+		count, err := store.Clicks().Count(shortUrl.Id, hour, hour.Add(time.Hour))
+
+		assert.Nil(t, err)
+		assert.Equal(t, int64(3), count)
+
+		points, err := store.Clicks().Points(shortUrl.Id, time.Time{}, time.Time{})
+
+		assert.Nil(t, err)
+		assert.ElementsMatch(t, []int64{3, 1}, []int64{points[0].Count, points[1].Count})
+	})
+}
+
+func TestListsShortUrlsAfterPosition(t *testing.T) {
+	eachStore(t, func(t *testing.T, store Store) {
+		createdAt := time.Date(2022, 5, 11, 11, 30, 0, 0, time.UTC)
+
+		for i, slug := range []string{"my_a", "my_b", "myc", "other"} {
+			shortUrl := newShortUrl(slug, "https://"+slug+".example.com")
+			shortUrl.CreatedAt = createdAt.Add(time.Duration(i) * time.Minute)
+
+			assert.Nil(t, store.ShortUrls().Create(shortUrl))
+		}
+
+		query := ListQuery{
+			SlugPrefix: "my_",
+			Order:      enums.SortOrderDescending,
+			Limit:      1,
+		}
+
+		page, err := store.ShortUrls().List(query)
+
+		assert.Nil(t, err)
+		assert.Len(t, page, 1)
+		assert.Equal(t, "my_b", page[0].Slug)
+
+		query.After = &page[0]
+		query.Limit = 10
+
+		page, err = store.ShortUrls().List(query)
+
+		assert.Nil(t, err)
+		assert.Len(t, page, 1)
+		assert.Equal(t, "my_a", page[0].Slug)
+	})
+}
+
+func TestRollsBackNestedTransaction(t *testing.T) {
+	eachStore(t, func(t *testing.T, store Store) {
+		rollback := errors.New("rollback")
+
+		err := store.Transaction(func(tx Store) error {
+			assert.Nil(t, tx.ShortUrls().Create(newShortUrl("kept", "https://kept.example.com")))
+
+			err := tx.Transaction(func(tx Store) error {
+				assert.Nil(t, tx.ShortUrls().Create(newShortUrl("dropped", "https://dropped.example.com")))
+
+				return rollback
+			})
+
+			assert.ErrorIs(t, err, rollback)
+
+			_, err = tx.ShortUrls().FindBySlug("dropped")
+			assert.ErrorIs(t, err, ErrNotFound)
+
+			return nil
+		})
+
+		assert.Nil(t, err)
+
+		_, err = store.ShortUrls().FindBySlug("kept")
+		assert.Nil(t, err)
+
+		err = store.Transaction(func(tx Store) error {
+			assert.Nil(t, tx.ShortUrls().Create(newShortUrl("dropped", "https://dropped.example.com")))
+
+			return rollback
+		})
+
+		assert.ErrorIs(t, err, rollback)
+
+		_, err = store.ShortUrls().FindBySlug("dropped")
+		assert.ErrorIs(t, err, ErrNotFound)
+	})
+}
+
+func TestUpdatesEverythingButIdOwnerAndCreationTime(t *testing.T) {
+	eachStore(t, func(t *testing.T, store Store) {
+		createdAt := time.Date(2022, 5, 11, 11, 30, 0, 0, time.UTC)
+
+		shortUrl := newShortUrl("google", "https://www.google.com")
+		shortUrl.OwnerId = "platform-team"
+		shortUrl.CreatedAt = createdAt
+		assert.Nil(t, store.ShortUrls().Create(shortUrl))
+
+		redirectType := 307
+		cacheControl := "public"
+
+		changed := *shortUrl
+		changed.Slug = "search"
+		changed.RedirectType = &redirectType
+		changed.CacheControl = &cacheControl
+		changed.OwnerId = "someone-else"
+		changed.CreatedAt = createdAt.Add(time.Hour)
+		assert.Nil(t, store.ShortUrls().Update(&changed))
+
+		saved, err := store.ShortUrls().FindById(shortUrl.Id)
+
+		assert.Nil(t, err)
+		assert.Equal(t, "search", saved.Slug)
+		assert.Equal(t, &redirectType, saved.RedirectType)
+		assert.Equal(t, &cacheControl, saved.CacheControl)
+		assert.Nil(t, saved.CacheMaxAge)
+		assert.Equal(t, "platform-team", saved.OwnerId)
+		assert.True(t, createdAt.Equal(saved.CreatedAt))
+	})
+}
+
+func TestListsNamespacesByPrefixAndReportsDuplicates(t *testing.T) {
+	eachStore(t, func(t *testing.T, store Store) {
+		for _, prefix := range []string{"eng/sre", "eng", "sales"} {
+			namespace := &models.Namespace{}
+			namespace.Prefix = prefix
+			namespace.OwnerId = prefix + "-team"
+
+			assert.Nil(t, store.Namespaces().Create(namespace))
+		}
+
+		duplicate := &models.Namespace{}
+		duplicate.Prefix = "eng"
+		duplicate.OwnerId = "someone-else"
+
+		assert.ErrorIs(t, store.Namespaces().Create(duplicate), ErrDuplicatePrefix)
+
+		eng, err := store.Namespaces().FindByPrefix("eng")
+		assert.Nil(t, err)
+
+		eng.OwnerId = "platform-team"
+		assert.Nil(t, store.Namespaces().Update(&eng))
+
+		sales, _ := store.Namespaces().FindByPrefix("sales")
+		assert.Nil(t, store.Namespaces().Delete(sales))
+		assert.ErrorIs(t, store.Namespaces().Delete(sales), ErrNotFound)
+
+		namespaces, err := store.Namespaces().List()
+
+		assert.Nil(t, err)
+		assert.Len(t, namespaces, 2)
+		assert.Equal(t, "eng", namespaces[0].Prefix)
+		assert.Equal(t, "platform-team", namespaces[0].OwnerId)
+		assert.Equal(t, "eng/sre", namespaces[1].Prefix)
+
+		_, err = store.Namespaces().FindByPrefix("sales")
+		assert.ErrorIs(t, err, ErrNotFound)
+	})
+}
+
+func TestListsAuditEventsNewestFirst(t *testing.T) {
+	eachStore(t, func(t *testing.T, store Store) {
+		alice := "alice"
+		events := []models.AuditEvent{
+			{ActorType: models.AuditActorUser, Actor: alice, Action: models.AuditActionCreate, ShortUrlId: 1, Slug: "a"},
+			{ActorType: models.AuditActorUser, Actor: "bob", Action: models.AuditActionCreate, ShortUrlId: 2, Slug: "b"},
+			{ActorType: models.AuditActorUser, Actor: alice, Action: models.AuditActionUpdate, ShortUrlId: 1, Slug: "c"},
+			{ActorType: models.AuditActorSystem, Action: models.AuditActionExpire, ShortUrlId: 1, Slug: "c"},
+		}
+
+		assert.Nil(t, store.AuditEvents().Create(events))
+		assert.Nil(t, store.AuditEvents().Create(nil))
+
+		listed, err := store.AuditEvents().List(AuditQuery{ShortUrlId: 1, Limit: 10})
+
+		assert.Nil(t, err)
+
+		if assert.Len(t, listed, 3) {
+			assert.Equal(t, []int64{events[3].Id, events[2].Id, events[0].Id}, []int64{listed[0].Id, listed[1].Id, listed[2].Id})
+			assert.Equal(t, models.AuditActionExpire, listed[0].Action)
+		}
+
+		listed, err = store.AuditEvents().List(AuditQuery{Actor: &alice, BeforeId: events[2].Id, Limit: 10})
+
+		assert.Nil(t, err)
+
+		if assert.Len(t, listed, 1) {
+			assert.Equal(t, events[0].Id, listed[0].Id)
+		}
+
+		listed, err = store.AuditEvents().List(AuditQuery{Action: models.AuditActionCreate, Slug: "b", Limit: 10})
+
+		assert.Nil(t, err)
+
+		if assert.Len(t, listed, 1) {
+			assert.Equal(t, "bob", listed[0].Actor)
+		}
+
+		listed, err = store.AuditEvents().List(AuditQuery{CreatedAfter: time.Now().Add(time.Hour), Limit: 10})
+
+		assert.Nil(t, err)
+		assert.Empty(t, listed)
+	})
+}
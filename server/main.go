@@ -1,26 +1,68 @@
 package server
 
 import (
+	"net/http"
+	"url-shortener/cache"
 	"url-shortener/controllers"
+	"url-shortener/controllers/api/v1/apikeys"
+	"url-shortener/controllers/api/v1/audit"
+	"url-shortener/controllers/api/v1/namespaces"
 	"url-shortener/controllers/api/v1/shorturls"
 	"url-shortener/controllers/api/v1/shorturls/clicks"
 	_ "url-shortener/docs"
+	"url-shortener/enums"
+	"url-shortener/middleware"
+	"url-shortener/repositories"
 	"url-shortener/services"
 
 	"github.com/gin-gonic/gin"
 	"github.com/swaggo/gin-swagger"
 	"github.com/swaggo/gin-swagger/swaggerFiles"
-	"gorm.io/gorm"
 )
 
 type ServerConfig struct {
-	DB *gorm.DB
+	Store        repositories.Store
+	IpAnonymizer services.IpAnonymizer
+	// ClickRecorder defaults to recording clicks synchronously.
+	ClickRecorder services.ClickRecorder
+	// RequireAuthForWrites rejects anonymous requests that would create,
+	// change, or delete anything. Reads are always allowed anonymously.
+	RequireAuthForWrites bool
+	// JwtAuthenticator is set to also accept JWTs from an identity provider.
+	JwtAuthenticator *services.JwtAuthenticator
+	// SlugCache is set to cache the short URLs that slugs redirect to. Writes
+	// made outside the server, like the scheduled jobs', must go through a
+	// store wrapped with cache.NewInvalidatingStore.
+	SlugCache       cache.SlugCache
+	SlugCacheConfig services.SlugCacheConfig
+	// RedirectDefaults default to permanent redirects that browsers must
+	// revalidate, so that every click is counted.
+	RedirectDefaults controllers.RedirectDefaults
+	// SlugPolicy defaults to a maximum length of DefaultSlugMaxLength and
+	// the DefaultReservedWords.
+	SlugPolicy services.SlugPolicy
+	// SlugGenerator defaults to 8 character nanoids, which are lowercase if
+	// the slug policy lowercases slugs.
+	SlugGenerator services.SlugGenerator
+	// Clock decides when short URLs expire, and defaults to the system's.
+	Clock services.Clock
 }
 
 func SetupServer(cfg *ServerConfig) *gin.Engine {
 	r := gin.Default()
+	// Slugs with slashes are passed to the API with them escaped, as in
+	// /api/v1/shorturls/eng%2Foncall, so routes are matched before
+	// unescaping.
+	r.UseRawPath = true
 
-	controllers := BuildControllers(cfg.DB)
+	authenticator := &services.BearerAuthenticator{
+		ApiKeys: &services.ApiKeyService{Store: cfg.Store},
+		Jwt:     cfg.JwtAuthenticator,
+	}
+
+	r.Use(middleware.Authenticate(authenticator, cfg.RequireAuthForWrites))
+
+	controllers := BuildControllers(cfg)
 
 	for _, c := range controllers {
 		c.Register(r)
@@ -31,41 +73,232 @@ func SetupServer(cfg *ServerConfig) *gin.Engine {
 	return r
 }
 
-func BuildControllers(db *gorm.DB) []controllers.RegistrableController {
-	createShortUrlService := &services.CreateShortUrlService{DB: db}
-	deleteShortUrlService := &services.DeleteShortUrlService{DB: db}
-	getClicksService := &services.GetClicksService{DB: db, Clock: services.SystemClock{}}
+func BuildControllers(cfg *ServerConfig) []controllers.RegistrableController {
+	store := cfg.Store
+
+	if cfg.SlugCache != nil {
+		store = cache.NewInvalidatingStore(store, cfg.SlugCache)
+	}
+
+	slugPolicy := cfg.SlugPolicy
+
+	if slugPolicy.MaxLength == 0 {
+		slugPolicy.MaxLength = services.DefaultSlugMaxLength
+	}
+
+	if slugPolicy.ReservedWords == nil {
+		slugPolicy.ReservedWords = services.DefaultReservedWords
+	}
+
+	slugGenerator := cfg.SlugGenerator
+
+	if slugGenerator == nil {
+		slugGenerator = services.DefaultSlugGenerator(slugPolicy)
+	}
+
+	clock := cfg.Clock
+
+	if clock == nil {
+		clock = services.SystemClock{}
+	}
+
+	createShortUrlService := &services.CreateShortUrlService{
+		Store:         store,
+		SlugPolicy:    slugPolicy,
+		SlugGenerator: slugGenerator,
+		Clock:         clock,
+	}
+	deleteShortUrlService := &services.DeleteShortUrlService{Store: store}
+	restoreShortUrlService := &services.RestoreShortUrlService{Store: store}
+	updateShortUrlService := &services.UpdateShortUrlService{Store: store, SlugPolicy: slugPolicy, Clock: clock}
+	getClicksService := &services.GetClicksService{Store: store, Clock: clock}
+	apiKeyService := &services.ApiKeyService{Store: store}
+	listShortUrlsService := &services.ListShortUrlsService{Store: store}
+	batchShortUrlService := &services.BatchShortUrlService{
+		Store:         store,
+		SlugPolicy:    slugPolicy,
+		SlugGenerator: slugGenerator,
+		Clock:         clock,
+	}
+	exportShortUrlsService := &services.ExportShortUrlsService{Store: store}
+	importShortUrlsService := &services.ImportShortUrlsService{Store: store, SlugPolicy: slugPolicy}
+	namespaceService := &services.NamespaceService{Store: store, SlugPolicy: slugPolicy}
+	auditService := &services.AuditService{Store: store}
 
 	createShortUrlController := shorturls.CreateShortUrlController{
 		CreateShortUrlService: createShortUrlService,
+		Clock:                 clock,
 	}
 
 	deleteShortUrlController := shorturls.DeleteShortUrlController{
 		DeleteShortUrlService: deleteShortUrlService,
 	}
 
+	restoreShortUrlController := shorturls.RestoreShortUrlController{
+		RestoreShortUrlService: restoreShortUrlService,
+		Clock:                  clock,
+	}
+
+	updateShortUrlController := shorturls.UpdateShortUrlController{
+		UpdateShortUrlService: updateShortUrlService,
+		Clock:                 clock,
+	}
+
+	batchCreateShortUrlsController := shorturls.BatchCreateShortUrlsController{
+		BatchShortUrlService: batchShortUrlService,
+		Clock:                clock,
+	}
+
+	batchDeleteShortUrlsController := shorturls.BatchDeleteShortUrlsController{
+		BatchShortUrlService: batchShortUrlService,
+	}
+
+	exportShortUrlsController := shorturls.ExportShortUrlsController{
+		ExportShortUrlsService: exportShortUrlsService,
+	}
+
+	importShortUrlsController := shorturls.ImportShortUrlsController{
+		ImportShortUrlsService: importShortUrlsService,
+	}
+
 	getShortUrlController := shorturls.GetShortUrlController{
-		DB: db,
+		ShortUrls: store.ShortUrls(),
+		Clock:     clock,
+	}
+
+	getShortUrlQrCodeController := shorturls.GetShortUrlQrCodeController{
+		ShortUrls: store.ShortUrls(),
 	}
 
 	listShortUrlsController := shorturls.ListShortUrlsController{
-		DB: db,
+		ListShortUrlsService: listShortUrlsService,
+		Clock:                clock,
+	}
+
+	listTrashController := shorturls.ListTrashController{
+		ListShortUrlsController: listShortUrlsController,
 	}
 
 	getShortUrlClicksController := clicks.GetShortUrlClicksController{
 		GetClicksService: getClicksService,
 	}
 
+	getShortUrlClickSeriesController := clicks.GetShortUrlClickSeriesController{
+		GetClicksService: getClicksService,
+	}
+
+	getShortUrlReferrersController := clicks.GetShortUrlClickBreakdownController{
+		GetClicksService: getClicksService,
+		Dimension:        enums.ClickDimensionReferrer,
+	}
+
+	getShortUrlUserAgentsController := clicks.GetShortUrlClickBreakdownController{
+		GetClicksService: getClicksService,
+		Dimension:        enums.ClickDimensionUserAgent,
+	}
+
+	getShortUrlLanguagesController := clicks.GetShortUrlClickBreakdownController{
+		GetClicksService: getClicksService,
+		Dimension:        enums.ClickDimensionLanguage,
+	}
+
+	getShortUrlCampaignsController := clicks.GetShortUrlClickBreakdownController{
+		GetClicksService: getClicksService,
+		Dimension:        enums.ClickDimensionCampaign,
+	}
+
+	getShortUrlSourcesController := clicks.GetShortUrlClickBreakdownController{
+		GetClicksService: getClicksService,
+		Dimension:        enums.ClickDimensionSource,
+	}
+
+	createApiKeyController := apikeys.CreateApiKeyController{
+		ApiKeyService: apiKeyService,
+	}
+
+	listNamespacesController := namespaces.ListNamespacesController{
+		NamespaceService: namespaceService,
+	}
+
+	createNamespaceController := namespaces.CreateNamespaceController{
+		NamespaceService: namespaceService,
+	}
+
+	updateNamespaceController := namespaces.UpdateNamespaceController{
+		NamespaceService: namespaceService,
+	}
+
+	deleteNamespaceController := namespaces.DeleteNamespaceController{
+		NamespaceService: namespaceService,
+	}
+
+	listAuditEventsController := audit.ListAuditEventsController{
+		AuditService: auditService,
+	}
+
+	getShortUrlHistoryController := audit.GetShortUrlHistoryController{
+		AuditService: auditService,
+	}
+
+	clickRecorder := cfg.ClickRecorder
+
+	if clickRecorder == nil {
+		clickRecorder = &services.SynchronousClickRecorder{Clicks: store.Clicks()}
+	}
+
+	slugResolver := &services.SlugResolver{
+		ShortUrls: store.ShortUrls(),
+		Cache:     cfg.SlugCache,
+		Clock:     clock,
+		Config:    cfg.SlugCacheConfig,
+		FoldCase:  slugPolicy.Case == enums.SlugCaseLower,
+	}
+
+	redirectDefaults := cfg.RedirectDefaults
+
+	if redirectDefaults.RedirectType == 0 {
+		redirectDefaults.RedirectType = http.StatusMovedPermanently
+	}
+
+	if redirectDefaults.CacheControl == "" {
+		redirectDefaults.CacheControl = "no-cache"
+	}
+
 	accessShortUrlController := controllers.AccessShortUrlController{
-		DB: db,
+		SlugResolver:     slugResolver,
+		IpAnonymizer:     cfg.IpAnonymizer,
+		ClickRecorder:    clickRecorder,
+		RedirectDefaults: redirectDefaults,
+		Clock:            clock,
 	}
 
 	return []controllers.RegistrableController{
 		&createShortUrlController,
 		&deleteShortUrlController,
+		&restoreShortUrlController,
+		&updateShortUrlController,
+		&batchCreateShortUrlsController,
+		&batchDeleteShortUrlsController,
+		&exportShortUrlsController,
+		&importShortUrlsController,
 		&accessShortUrlController,
 		&getShortUrlClicksController,
+		&getShortUrlClickSeriesController,
+		&getShortUrlReferrersController,
+		&getShortUrlUserAgentsController,
+		&getShortUrlLanguagesController,
+		&getShortUrlCampaignsController,
+		&getShortUrlSourcesController,
 		&getShortUrlController,
+		&getShortUrlQrCodeController,
 		&listShortUrlsController,
+		&listTrashController,
+		&createApiKeyController,
+		&listNamespacesController,
+		&createNamespaceController,
+		&updateNamespaceController,
+		&deleteNamespaceController,
+		&listAuditEventsController,
+		&getShortUrlHistoryController,
 	}
 }
@@ -0,0 +1,99 @@
+package services
+
+import (
+	"encoding/json"
+	"time"
+	"url-shortener/models"
+	"url-shortener/repositories"
+
+	"gopkg.in/guregu/null.v4"
+)
+
+// Origin is where a request that changes short URLs came from, as recorded
+// in the audit log.
+type Origin struct {
+	RequestId string
+	IpAddress string
+}
+
+// shortUrlSnapshot is how short URLs are recorded in audit events. Unlike
+// their API representation, it includes their owner and when they were
+// deleted or superseded.
+type shortUrlSnapshot struct {
+	OwnerId string `json:"owner_id,omitempty"`
+	models.ShortUrlReadFields
+	DeletedAt    *time.Time `json:"deleted_at,omitempty"`
+	SupersededAt *time.Time `json:"superseded_at,omitempty"`
+}
+
+// newAuditEvent records that principal changed a short URL from before to
+// after, either of which is nil if it didn't exist.
+func newAuditEvent(action string, principal *Principal, origin Origin, before *models.ShortUrl, after *models.ShortUrl) models.AuditEvent {
+	event := models.AuditEvent{
+		ActorType: models.AuditActorAnonymous,
+		Action:    action,
+		Before:    snapshot(before),
+		After:     snapshot(after),
+		RequestId: origin.RequestId,
+		IpAddress: origin.IpAddress,
+	}
+
+	if principal != nil {
+		event.ActorType = models.AuditActorUser
+		event.Actor = principal.OwnerId
+	}
+
+	subject := after
+
+	if subject == nil {
+		subject = before
+	}
+
+	event.ShortUrlId = subject.Id
+	event.Slug = subject.Slug
+
+	return event
+}
+
+// recordAudit records that principal changed a short URL, in tx so that the
+// event is only kept along with the change.
+func recordAudit(tx repositories.Store, action string, principal *Principal, origin Origin, before *models.ShortUrl, after *models.ShortUrl) error {
+	return tx.AuditEvents().Create([]models.AuditEvent{newAuditEvent(action, principal, origin, before, after)})
+}
+
+// RecordSystemDeletions records that a scheduled job permanently deleted
+// shortUrls, with action AuditActionExpire or AuditActionPurge.
+func RecordSystemDeletions(tx repositories.Store, action string, shortUrls []models.ShortUrl) error {
+	events := make([]models.AuditEvent, len(shortUrls))
+
+	for i := range shortUrls {
+		events[i] = newAuditEvent(action, nil, Origin{}, &shortUrls[i], nil)
+		events[i].ActorType = models.AuditActorSystem
+	}
+
+	return tx.AuditEvents().Create(events)
+}
+
+func snapshot(shortUrl *models.ShortUrl) null.String {
+	if shortUrl == nil {
+		return null.String{}
+	}
+
+	s := shortUrlSnapshot{
+		OwnerId:            shortUrl.OwnerId,
+		ShortUrlReadFields: shortUrl.ShortUrlReadFields,
+	}
+
+	if shortUrl.DeletedAt.Valid {
+		s.DeletedAt = &shortUrl.DeletedAt.Time
+	}
+
+	if shortUrl.SupersededAt.Valid {
+		s.SupersededAt = &shortUrl.SupersededAt.Time
+	}
+
+	// Short URLs are made of plain fields, so marshaling them can't fail.
+	b, _ := json.Marshal(s)
+
+	return null.StringFrom(string(b))
+}
@@ -0,0 +1,141 @@
+package services
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"time"
+	"url-shortener/enums"
+	"url-shortener/models"
+	"url-shortener/repositories"
+)
+
+type AuditService struct {
+	Store repositories.Store
+}
+
+// AuditQuery selects a page of audit events. Zero values leave a filter out.
+type AuditQuery struct {
+	Slug          string
+	Actor         *string
+	Action        string
+	RequestId     string
+	CreatedAfter  time.Time
+	CreatedBefore time.Time
+
+	Limit int
+	// Cursor is the NextCursor of the previous page, or empty for the first
+	// page.
+	Cursor string
+}
+
+type AuditResult struct {
+	Status  enums.AuditStatus
+	Records []models.AuditEvent
+	// NextCursor is empty when there are no more pages.
+	NextCursor string
+	Error      error
+}
+
+// auditCursor is the id of the last event on a page.
+type auditCursor struct {
+	Id int64 `json:"id"`
+}
+
+// List returns the audit log, newest first. Only admins may read all of it.
+func (s *AuditService) List(principal *Principal, query AuditQuery) AuditResult {
+	if principal == nil || !principal.Admin {
+		return AuditResult{Status: enums.AuditResultForbidden}
+	}
+
+	return s.list(repositories.AuditQuery{Slug: query.Slug}, query)
+}
+
+// History returns the audit events of the short URL with slug, newest first,
+// including the ones from before its slug was changed. Short URLs in the
+// trash have a history too. Only the people who may modify a short URL may
+// read its history, and never anonymously, since events record the IP
+// addresses that changes came from.
+func (s *AuditService) History(principal *Principal, slug string, query AuditQuery) AuditResult {
+	shortUrl, err := s.Store.ShortUrls().FindBySlug(slug)
+
+	if errors.Is(err, repositories.ErrNotFound) {
+		shortUrl, err = s.Store.ShortUrls().FindDeletedBySlug(slug)
+	}
+
+	if errors.Is(err, repositories.ErrNotFound) {
+		return AuditResult{Status: enums.AuditResultNotFound}
+	}
+
+	if err != nil {
+		return AuditResult{Status: enums.AuditResultUnknownError, Error: err}
+	}
+
+	if principal == nil || !principal.CanModify(shortUrl) {
+		return AuditResult{Status: enums.AuditResultForbidden}
+	}
+
+	return s.list(repositories.AuditQuery{ShortUrlId: shortUrl.Id}, query)
+}
+
+// list pages through the events matching repositoryQuery and query's
+// filters with keyset pagination, like the list of short URLs.
+func (s *AuditService) list(repositoryQuery repositories.AuditQuery, query AuditQuery) AuditResult {
+	repositoryQuery.Actor = query.Actor
+	repositoryQuery.Action = query.Action
+	repositoryQuery.RequestId = query.RequestId
+	repositoryQuery.CreatedAfter = query.CreatedAfter
+	repositoryQuery.CreatedBefore = query.CreatedBefore
+	// Fetching one extra event tells us whether there's another page.
+	repositoryQuery.Limit = query.Limit + 1
+
+	if query.Cursor != "" {
+		cursor, ok := decodeAuditCursor(query.Cursor)
+
+		if !ok {
+			return AuditResult{Status: enums.AuditResultInvalidCursor}
+		}
+
+		repositoryQuery.BeforeId = cursor.Id
+	}
+
+	events, err := s.Store.AuditEvents().List(repositoryQuery)
+
+	if err != nil {
+		return AuditResult{Status: enums.AuditResultUnknownError, Error: err}
+	}
+
+	result := AuditResult{Status: enums.AuditResultSuccessful}
+
+	if len(events) > query.Limit {
+		events = events[:query.Limit]
+		result.NextCursor = encodeAuditCursor(events[len(events)-1])
+	}
+
+	result.Records = events
+
+	return result
+}
+
+func encodeAuditCursor(last models.AuditEvent) string {
+	// Marshaling a struct of plain fields can't fail.
+	b, _ := json.Marshal(auditCursor{Id: last.Id})
+
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodeAuditCursor(s string) (auditCursor, bool) {
+	var cursor auditCursor
+
+	b, err := base64.RawURLEncoding.DecodeString(s)
+
+	if err != nil {
+		return cursor, false
+	}
+
+	if err := json.Unmarshal(b, &cursor); err != nil || cursor.Id <= 0 {
+		return cursor, false
+	}
+
+	return cursor, true
+}
@@ -0,0 +1,136 @@
+package services
+
+import (
+	"encoding/json"
+	"testing"
+	"url-shortener/enums"
+	"url-shortener/models"
+	"url-shortener/repositories"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/guregu/null.v4"
+)
+
+func TestRecordsChangesInTheAuditLog(t *testing.T) {
+	store := repositories.NewMemoryStore()
+	alice := &Principal{OwnerId: "alice"}
+	origin := Origin{RequestId: "req-1", IpAddress: "192.0.2.1"}
+
+	createShortUrlService := CreateShortUrlService{Store: store, Clock: TestClock{}}
+	updateShortUrlService := UpdateShortUrlService{Store: store, Clock: TestClock{}}
+	deleteShortUrlService := DeleteShortUrlService{Store: store}
+	restoreShortUrlService := RestoreShortUrlService{Store: store}
+	subject := AuditService{Store: store}
+
+	request := newShortUrlRequest("https://www.google.com")
+	request.Slug = "before"
+	request.OwnerId = alice.OwnerId
+
+	assert.Equal(t, enums.CreationResultCreated, createShortUrlService.Create(alice, origin, request).Status)
+	createShortUrl(t, store, "taken", null.Time{})
+
+	// A change that fails isn't recorded.
+	taken := "taken"
+	failed := updateShortUrlService.Update(alice, origin, "before", models.ShortUrlUpdateFields{Slug: &taken})
+	assert.Equal(t, enums.UpdateResultDuplicateSlug, failed.Status)
+
+	after := "after"
+	updated := updateShortUrlService.Update(alice, origin, "before", models.ShortUrlUpdateFields{Slug: &after})
+	assert.Equal(t, enums.UpdateResultSuccessful, updated.Status)
+
+	assert.Equal(t, enums.DeleteResultSuccessful, deleteShortUrlService.Delete(alice, Origin{}, "after").Status)
+	assert.Equal(t, enums.RestoreResultForbidden, restoreShortUrlService.Restore(nil, Origin{}, "after").Status)
+
+	result := subject.History(alice, "after", AuditQuery{Limit: 10})
+
+	assert.Nil(t, result.Error)
+
+	if !assert.Len(t, result.Records, 3) {
+		return
+	}
+
+	deleted, update, created := result.Records[0], result.Records[1], result.Records[2]
+
+	assert.Equal(t, models.AuditActionDelete, deleted.Action)
+	assert.False(t, deleted.After.Valid)
+	assert.Equal(t, "after", deleted.Slug)
+
+	assert.Equal(t, models.AuditActionUpdate, update.Action)
+	assert.Equal(t, "before", snapshotField(t, update.Before.String, "slug"))
+	assert.Equal(t, "after", snapshotField(t, update.After.String, "slug"))
+	assert.Equal(t, "alice", snapshotField(t, update.After.String, "owner_id"))
+
+	assert.Equal(t, models.AuditActionCreate, created.Action)
+	assert.Equal(t, models.AuditActorUser, created.ActorType)
+	assert.Equal(t, "alice", created.Actor)
+	assert.Equal(t, "req-1", created.RequestId)
+	assert.Equal(t, "192.0.2.1", created.IpAddress)
+	assert.False(t, created.Before.Valid)
+}
+
+func TestRecordsRestorationsInTheAuditLog(t *testing.T) {
+	store := repositories.NewMemoryStore()
+	shortUrl := createShortUrl(t, store, "restored", null.Time{})
+	assert.Nil(t, store.ShortUrls().Delete(shortUrl))
+
+	restoreShortUrlService := RestoreShortUrlService{Store: store}
+	subject := AuditService{Store: store}
+	admin := &Principal{OwnerId: "root", Admin: true}
+
+	result := restoreShortUrlService.Restore(admin, Origin{}, "restored")
+	assert.Equal(t, enums.RestoreResultSuccessful, result.Status)
+
+	// Events record IP addresses, so they're never shown anonymously.
+	assert.Equal(t, enums.AuditResultForbidden, subject.History(nil, "restored", AuditQuery{Limit: 10}).Status)
+
+	events := subject.History(admin, "restored", AuditQuery{Limit: 10})
+
+	if assert.Len(t, events.Records, 1) {
+		restored := events.Records[0]
+
+		assert.Equal(t, models.AuditActionRestore, restored.Action)
+		assert.Equal(t, models.AuditActorUser, restored.ActorType)
+		assert.Equal(t, "root", restored.Actor)
+		assert.NotNil(t, snapshotField(t, restored.Before.String, "deleted_at"))
+		assert.Nil(t, snapshotField(t, restored.After.String, "deleted_at"))
+	}
+}
+
+func TestPagesThroughTheAuditLog(t *testing.T) {
+	store := repositories.NewMemoryStore()
+	admin := &Principal{OwnerId: "root", Admin: true}
+	createShortUrlService := CreateShortUrlService{Store: store, Clock: TestClock{}}
+	subject := AuditService{Store: store}
+
+	for _, longUrl := range []string{"https://a.example.com", "https://b.example.com", "https://c.example.com"} {
+		assert.Equal(t, enums.CreationResultCreated, createShortUrlService.Create(nil, Origin{}, newShortUrlRequest(longUrl)).Status)
+	}
+
+	assert.Equal(t, enums.AuditResultForbidden, subject.List(nil, AuditQuery{Limit: 2}).Status)
+	assert.Equal(t, enums.AuditResultForbidden, subject.List(&Principal{OwnerId: "alice"}, AuditQuery{Limit: 2}).Status)
+
+	first := subject.List(admin, AuditQuery{Limit: 2})
+
+	assert.Equal(t, enums.AuditResultSuccessful, first.Status)
+	assert.Len(t, first.Records, 2)
+	assert.NotEmpty(t, first.NextCursor)
+
+	second := subject.List(admin, AuditQuery{Limit: 2, Cursor: first.NextCursor})
+
+	assert.Equal(t, enums.AuditResultSuccessful, second.Status)
+	assert.Empty(t, second.NextCursor)
+
+	if assert.Len(t, second.Records, 1) {
+		assert.Less(t, second.Records[0].Id, first.Records[1].Id)
+	}
+
+	assert.Equal(t, enums.AuditResultInvalidCursor, subject.List(admin, AuditQuery{Limit: 2, Cursor: "nope"}).Status)
+}
+
+func snapshotField(t *testing.T, snapshot string, field string) interface{} {
+	var fields map[string]interface{}
+
+	assert.Nil(t, json.Unmarshal([]byte(snapshot), &fields))
+
+	return fields[field]
+}
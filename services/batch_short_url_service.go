@@ -0,0 +1,145 @@
+package services
+
+import (
+	"errors"
+	"url-shortener/enums"
+	"url-shortener/models"
+	"url-shortener/repositories"
+)
+
+// errBatchAborted rolls back an atomic batch in which an item failed.
+var errBatchAborted = errors.New("batch aborted")
+
+type BatchShortUrlService struct {
+	Store         repositories.Store
+	SlugPolicy    SlugPolicy
+	SlugGenerator SlugGenerator
+	Clock         Clock
+}
+
+type BatchCreateResult struct {
+	Results []CreationResult
+	// Committed is false when an atomic batch was rolled back, in which case
+	// none of the results were kept.
+	Committed bool
+	Error     error
+}
+
+type BatchDeleteResult struct {
+	Results   []DeleteResult
+	Committed bool
+	Error     error
+}
+
+// Create creates each short URL in requests on behalf of principal, returning
+// a result for each in the same order. In atomic mode, the batch is rolled
+// back unless every short URL was either created or already existed. Every
+// item is attempted either way, so the results report all of the failures at
+// once. Each item is recorded in the audit log like a single creation.
+func (s *BatchShortUrlService) Create(principal *Principal, origin Origin, requests []models.ShortUrl, mode enums.BatchMode) BatchCreateResult {
+	results := make([]CreationResult, len(requests))
+
+	createAll := func(tx repositories.Store) error {
+		createShortUrlService := CreateShortUrlService{
+			Store:         tx,
+			SlugPolicy:    s.SlugPolicy,
+			SlugGenerator: s.SlugGenerator,
+			Clock:         s.Clock,
+		}
+		failed := false
+
+		for i := range requests {
+			results[i] = createShortUrlService.Create(principal, origin, &requests[i])
+
+			if results[i].Error != nil && mode == enums.BatchModeAtomic {
+				return results[i].Error
+			}
+
+			switch results[i].Status {
+			case enums.CreationResultCreated, enums.CreationResultAlreadyExists:
+			default:
+				failed = true
+			}
+		}
+
+		if failed && mode == enums.BatchModeAtomic {
+			return errBatchAborted
+		}
+
+		return nil
+	}
+
+	if mode == enums.BatchModeBestEffort {
+		createAll(s.Store)
+
+		return BatchCreateResult{
+			Results:   results,
+			Committed: true,
+		}
+	}
+
+	err := s.Store.Transaction(createAll)
+
+	if err != nil && !errors.Is(err, errBatchAborted) {
+		return BatchCreateResult{
+			Error: err,
+		}
+	}
+
+	return BatchCreateResult{
+		Results:   results,
+		Committed: err == nil,
+	}
+}
+
+// Delete deletes each short URL in slugs on behalf of principal, returning a
+// result for each in the same order. In atomic mode, the batch is rolled back
+// unless every short URL was deleted.
+func (s *BatchShortUrlService) Delete(principal *Principal, origin Origin, slugs []string, mode enums.BatchMode) BatchDeleteResult {
+	results := make([]DeleteResult, len(slugs))
+
+	deleteAll := func(tx repositories.Store) error {
+		deleteShortUrlService := DeleteShortUrlService{Store: tx}
+		failed := false
+
+		for i, slug := range slugs {
+			results[i] = deleteShortUrlService.Delete(principal, origin, slug)
+
+			if results[i].Error != nil && mode == enums.BatchModeAtomic {
+				return results[i].Error
+			}
+
+			if results[i].Status != enums.DeleteResultSuccessful {
+				failed = true
+			}
+		}
+
+		if failed && mode == enums.BatchModeAtomic {
+			return errBatchAborted
+		}
+
+		return nil
+	}
+
+	if mode == enums.BatchModeBestEffort {
+		deleteAll(s.Store)
+
+		return BatchDeleteResult{
+			Results:   results,
+			Committed: true,
+		}
+	}
+
+	err := s.Store.Transaction(deleteAll)
+
+	if err != nil && !errors.Is(err, errBatchAborted) {
+		return BatchDeleteResult{
+			Error: err,
+		}
+	}
+
+	return BatchDeleteResult{
+		Results:   results,
+		Committed: err == nil,
+	}
+}
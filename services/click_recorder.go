@@ -0,0 +1,166 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+	"url-shortener/enums"
+	"url-shortener/models"
+	"url-shortener/repositories"
+)
+
+var ErrClickBufferFull = errors.New("click buffer is full")
+var ErrClickRecorderStopped = errors.New("click recorder has been shut down")
+
+type ClickRecorder interface {
+	Record(click models.Click) error
+}
+
+// SynchronousClickRecorder inserts every click as soon as it's recorded.
+type SynchronousClickRecorder struct {
+	Clicks repositories.ClickRepository
+}
+
+func (r *SynchronousClickRecorder) Record(click models.Click) error {
+	return r.Clicks.Create([]models.Click{click})
+}
+
+type BufferedClickRecorderConfig struct {
+	// BufferSize is the number of clicks that can be waiting to be written
+	// before the overflow policy kicks in.
+	BufferSize int
+	// BatchSize is the largest number of clicks written in a single INSERT.
+	// A batch is written as soon as it's full.
+	BatchSize int
+	// FlushInterval is the longest a click will wait in a partial batch.
+	FlushInterval  time.Duration
+	OverflowPolicy enums.ClickOverflowPolicy
+}
+
+// BufferedClickRecorder keeps click inserts off of the redirect path. Clicks
+// are queued on a bounded channel and written by a single background
+// goroutine in multi-row INSERTs, whenever a batch fills up or the flush
+// interval passes. When the buffer is full, clicks are either dropped or the
+// caller waits for room, depending on the overflow policy.
+type BufferedClickRecorder struct {
+	Clicks repositories.ClickRepository
+	Clock  Clock
+	config BufferedClickRecorderConfig
+
+	clicks  chan models.Click
+	stopped chan struct{}
+	dropped int64
+
+	// mu guards closing the clicks channel against concurrent sends.
+	mu     sync.RWMutex
+	closed bool
+}
+
+func NewBufferedClickRecorder(clicks repositories.ClickRepository, clock Clock, config BufferedClickRecorderConfig) *BufferedClickRecorder {
+	return &BufferedClickRecorder{
+		Clicks:  clicks,
+		Clock:   clock,
+		config:  config,
+		clicks:  make(chan models.Click, config.BufferSize),
+		stopped: make(chan struct{}),
+	}
+}
+
+func (r *BufferedClickRecorder) Record(click models.Click) error {
+	// The click is written later, so it needs to be timestamped now.
+	if click.CreatedAt.IsZero() {
+		click.CreatedAt = r.Clock.Now()
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if r.closed {
+		return ErrClickRecorderStopped
+	}
+
+	if r.config.OverflowPolicy == enums.ClickOverflowPolicyBlock {
+		r.clicks <- click
+		return nil
+	}
+
+	select {
+	case r.clicks <- click:
+		return nil
+	default:
+		atomic.AddInt64(&r.dropped, 1)
+		return ErrClickBufferFull
+	}
+}
+
+// Start begins writing recorded clicks in the background.
+func (r *BufferedClickRecorder) Start() {
+	go r.run()
+}
+
+// Shutdown stops accepting clicks and waits for everything already recorded
+// to be written, or for ctx to be done.
+func (r *BufferedClickRecorder) Shutdown(ctx context.Context) error {
+	r.mu.Lock()
+
+	if !r.closed {
+		r.closed = true
+		close(r.clicks)
+	}
+
+	r.mu.Unlock()
+
+	select {
+	case <-r.stopped:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (r *BufferedClickRecorder) run() {
+	defer close(r.stopped)
+
+	ticker := time.NewTicker(r.config.FlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]models.Click, 0, r.config.BatchSize)
+
+	for {
+		select {
+		case click, ok := <-r.clicks:
+			if !ok {
+				r.flush(batch)
+				return
+			}
+
+			batch = append(batch, click)
+
+			if len(batch) >= r.config.BatchSize {
+				batch = r.flush(batch)
+			}
+		case <-ticker.C:
+			batch = r.flush(batch)
+		}
+	}
+}
+
+// flush writes the batch and returns it emptied for reuse.
+func (r *BufferedClickRecorder) flush(batch []models.Click) []models.Click {
+	if dropped := atomic.SwapInt64(&r.dropped, 0); dropped > 0 {
+		log.Printf("dropped %d clicks because the click buffer was full", dropped)
+	}
+
+	if len(batch) == 0 {
+		return batch
+	}
+
+	if err := r.Clicks.Create(batch); err != nil {
+		log.Printf("encountered error recording %d clicks: %v", len(batch), err)
+	}
+
+	return batch[:0]
+}
@@ -0,0 +1,82 @@
+package services
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+	"url-shortener/db"
+	"url-shortener/enums"
+	"url-shortener/models"
+	"url-shortener/repositories"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBufferedClickRecorderWritesBatches(t *testing.T) {
+	sqlDB, mock, err := sqlmock.New()
+
+	assert.Nil(t, err)
+	defer sqlDB.Close()
+
+	insert := regexp.QuoteMeta(`INSERT INTO "clicks"`)
+
+	// Three clicks with a batch size of two are written as a full batch of
+	// two, followed by a batch of one when the recorder is shut down.
+	mock.ExpectBegin()
+	mock.ExpectQuery(insert + `.*VALUES \([^)]*\),\([^)]*\) RETURNING`).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1).AddRow(2))
+	mock.ExpectCommit()
+	mock.ExpectBegin()
+	mock.ExpectQuery(insert + `.*VALUES \([^)]*\) RETURNING`).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(3))
+	mock.ExpectCommit()
+
+	gormDB, err := db.ConnectDatabaseWithoutMigrating(sqlDB)
+	assert.Nil(t, err)
+
+	subject := NewBufferedClickRecorder(repositories.NewPostgresStore(gormDB).Clicks(), SystemClock{}, BufferedClickRecorderConfig{
+		BufferSize:    10,
+		BatchSize:     2,
+		FlushInterval: time.Hour,
+	})
+
+	subject.Start()
+
+	for i := 0; i < 3; i++ {
+		assert.Nil(t, subject.Record(models.Click{ShortUrlId: 1}))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	assert.Nil(t, subject.Shutdown(ctx))
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+
+	assert.Equal(t, ErrClickRecorderStopped, subject.Record(models.Click{ShortUrlId: 1}))
+}
+
+func TestBufferedClickRecorderDropsClicksWhenFull(t *testing.T) {
+	sqlDB, _, err := sqlmock.New()
+
+	assert.Nil(t, err)
+	defer sqlDB.Close()
+
+	gormDB, err := db.ConnectDatabaseWithoutMigrating(sqlDB)
+	assert.Nil(t, err)
+
+	// Without being started, nothing drains the buffer.
+	subject := NewBufferedClickRecorder(repositories.NewPostgresStore(gormDB).Clicks(), SystemClock{}, BufferedClickRecorderConfig{
+		BufferSize:     1,
+		BatchSize:      1,
+		FlushInterval:  time.Hour,
+		OverflowPolicy: enums.ClickOverflowPolicyDrop,
+	})
+
+	assert.Nil(t, subject.Record(models.Click{ShortUrlId: 1}))
+	assert.Equal(t, ErrClickBufferFull, subject.Record(models.Click{ShortUrlId: 1}))
+}
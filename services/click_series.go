@@ -0,0 +1,149 @@
+package services
+
+import (
+	"errors"
+	"sort"
+	"time"
+	"url-shortener/enums"
+	"url-shortener/repositories"
+)
+
+// MaxClickSeriesBuckets bounds the size of a time series response so that a
+// wide range with a small interval can't be used to build an enormous one.
+const MaxClickSeriesBuckets = 1000
+
+type ClickSeriesBucket struct {
+	Start time.Time `json:"start"`
+	Count int64     `json:"count"`
+}
+
+type GetClickSeriesResult struct {
+	Status  enums.GetClicksStatus
+	Buckets []ClickSeriesBucket
+	Error   error
+}
+
+// GetClickSeries counts the clicks on a short URL in zero-filled buckets of
+// the given interval, from the bucket that from falls in up to the bucket
+// that to (exclusive) falls in. Buckets begin on hour, midnight, or Monday
+// midnight boundaries in the given location, so days and weeks follow the
+// caller's calendar (including daylight saving time transitions) rather than
+// UTC. Every bucket is counted in full, even when from or to is partway
+// through it.
+func (s *GetClicksService) GetClickSeries(
+	slug string,
+	from time.Time,
+	to time.Time,
+	interval enums.ClickSeriesInterval,
+	location *time.Location,
+) GetClickSeriesResult {
+	buckets := clickSeriesBuckets(from, to, interval, location)
+
+	if len(buckets) > MaxClickSeriesBuckets {
+		return GetClickSeriesResult{
+			Status: enums.GetClicksResultRangeTooLarge,
+		}
+	}
+
+	shortUrl, err := s.Store.ShortUrls().FindBySlug(slug)
+
+	if errors.Is(err, repositories.ErrNotFound) {
+		return GetClickSeriesResult{
+			Status: enums.GetClicksResultNotFound,
+		}
+	}
+
+	if err != nil {
+		return GetClickSeriesResult{
+			Error:  err,
+			Status: enums.GetClicksResultUnknownError,
+		}
+	}
+
+	// A bucket cut short at either end of the range would look quieter than
+	// it was, so the range is widened to whole buckets.
+	if len(buckets) > 0 {
+		from = buckets[0].Start
+		to = nextInterval(buckets[len(buckets)-1].Start, interval)
+	}
+
+	points, err := s.Store.Clicks().Points(shortUrl.Id, from, to)
+
+	if err != nil {
+		return GetClickSeriesResult{
+			Error:  err,
+			Status: enums.GetClicksResultUnknownError,
+		}
+	}
+
+	// Buckets are at least an hour long and start on the hour, so a rollup
+	// falls into a single bucket, except in time zones offset from UTC by a
+	// fraction of an hour, where it counts towards the bucket its hour starts
+	// in.
+	for _, point := range points {
+		// Find the last bucket starting at or before the point.
+		i := sort.Search(len(buckets), func(i int) bool {
+			return buckets[i].Start.After(point.Time)
+		}) - 1
+
+		if i >= 0 {
+			buckets[i].Count += point.Count
+		}
+	}
+
+	return GetClickSeriesResult{
+		Buckets: buckets,
+		Status:  enums.GetClicksResultSuccessful,
+	}
+}
+
+// clickSeriesBuckets returns empty buckets covering [from, to). It stops
+// early once there are more than MaxClickSeriesBuckets of them.
+func clickSeriesBuckets(
+	from time.Time,
+	to time.Time,
+	interval enums.ClickSeriesInterval,
+	location *time.Location,
+) []ClickSeriesBucket {
+	buckets := []ClickSeriesBucket{}
+
+	start := truncateToInterval(from.In(location), interval)
+
+	for start.Before(to) && len(buckets) <= MaxClickSeriesBuckets {
+		buckets = append(buckets, ClickSeriesBucket{Start: start})
+		start = nextInterval(start, interval)
+	}
+
+	return buckets
+}
+
+func truncateToInterval(t time.Time, interval enums.ClickSeriesInterval) time.Time {
+	year, month, day := t.Date()
+
+	switch interval {
+	case enums.ClickSeriesIntervalHour:
+		return time.Date(year, month, day, t.Hour(), 0, 0, 0, t.Location())
+	case enums.ClickSeriesIntervalWeek:
+		// Weeks start on Monday, as in ISO 8601.
+		daysSinceMonday := (int(t.Weekday()) + 6) % 7
+		return time.Date(year, month, day-daysSinceMonday, 0, 0, 0, 0, t.Location())
+	}
+
+	return time.Date(year, month, day, 0, 0, 0, 0, t.Location())
+}
+
+func nextInterval(t time.Time, interval enums.ClickSeriesInterval) time.Time {
+	year, month, day := t.Date()
+
+	switch interval {
+	case enums.ClickSeriesIntervalHour:
+		// Adding an absolute hour (rather than bumping the wall clock) keeps
+		// the repeated hour at the end of daylight saving time as its own
+		// bucket.
+		return t.Add(time.Hour)
+	case enums.ClickSeriesIntervalWeek:
+		return time.Date(year, month, day+7, 0, 0, 0, 0, t.Location())
+	}
+
+	return time.Date(year, month, day+1, 0, 0, 0, 0, t.Location())
+}
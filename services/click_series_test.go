@@ -0,0 +1,136 @@
+package services
+
+import (
+	"testing"
+	"time"
+	"url-shortener/db"
+	"url-shortener/enums"
+	"url-shortener/models"
+	"url-shortener/repositories"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClickSeriesBuckets(t *testing.T) {
+	chicago, err := time.LoadLocation("America/Chicago")
+	assert.Nil(t, err)
+
+	type test struct {
+		from           time.Time
+		to             time.Time
+		interval       enums.ClickSeriesInterval
+		location       *time.Location
+		expectedStarts []time.Time
+	}
+
+	tests := []test{
+		{
+			from:     time.Date(2022, 5, 10, 12, 30, 0, 0, time.UTC),
+			to:       time.Date(2022, 5, 10, 15, 0, 0, 0, time.UTC),
+			interval: enums.ClickSeriesIntervalHour,
+			location: time.UTC,
+			expectedStarts: []time.Time{
+				time.Date(2022, 5, 10, 12, 0, 0, 0, time.UTC),
+				time.Date(2022, 5, 10, 13, 0, 0, 0, time.UTC),
+				time.Date(2022, 5, 10, 14, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			// Days in Chicago start at 05:00 UTC during daylight saving time
+			from:     time.Date(2022, 5, 10, 3, 0, 0, 0, time.UTC),
+			to:       time.Date(2022, 5, 11, 12, 0, 0, 0, time.UTC),
+			interval: enums.ClickSeriesIntervalDay,
+			location: chicago,
+			expectedStarts: []time.Time{
+				time.Date(2022, 5, 9, 0, 0, 0, 0, chicago),
+				time.Date(2022, 5, 10, 0, 0, 0, 0, chicago),
+				time.Date(2022, 5, 11, 0, 0, 0, 0, chicago),
+			},
+		},
+		{
+			// The day that daylight saving time ends is 25 hours long
+			from:     time.Date(2022, 11, 6, 0, 0, 0, 0, chicago),
+			to:       time.Date(2022, 11, 7, 0, 0, 0, 0, chicago),
+			interval: enums.ClickSeriesIntervalHour,
+			location: chicago,
+		},
+		{
+			// 2022-05-10 was a Tuesday
+			from:     time.Date(2022, 5, 10, 12, 0, 0, 0, time.UTC),
+			to:       time.Date(2022, 5, 17, 12, 0, 0, 0, time.UTC),
+			interval: enums.ClickSeriesIntervalWeek,
+			location: time.UTC,
+			expectedStarts: []time.Time{
+				time.Date(2022, 5, 9, 0, 0, 0, 0, time.UTC),
+				time.Date(2022, 5, 16, 0, 0, 0, 0, time.UTC),
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		buckets := clickSeriesBuckets(tc.from, tc.to, tc.interval, tc.location)
+
+		if tc.expectedStarts == nil {
+			assert.Len(t, buckets, 25)
+			continue
+		}
+
+		starts := []time.Time{}
+
+		for _, bucket := range buckets {
+			assert.Equal(t, int64(0), bucket.Count)
+			starts = append(starts, bucket.Start)
+		}
+
+		assert.Equal(t, tc.expectedStarts, starts)
+	}
+}
+
+func TestClickSeriesBucketsStopsAfterMaximum(t *testing.T) {
+	buckets := clickSeriesBuckets(
+		time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC),
+		enums.ClickSeriesIntervalHour,
+		time.UTC,
+	)
+
+	assert.Len(t, buckets, MaxClickSeriesBuckets+1)
+}
+
+func TestGetClickSeriesCountsBucketsInFull(t *testing.T) {
+	gormDB, err := db.ConnectSqlite(":memory:")
+	assert.Nil(t, err)
+
+	store := repositories.NewSqliteStore(gormDB)
+	subject := GetClicksService{Store: store, Clock: TestClock{}}
+
+	shortUrl := models.ShortUrl{}
+	shortUrl.Slug = "slug"
+	shortUrl.LongUrl = "https://www.cloudflare.com"
+
+	assert.Nil(t, store.ShortUrls().Create(&shortUrl))
+
+	for _, createdAt := range []time.Time{
+		time.Date(2022, 5, 10, 12, 10, 0, 0, time.UTC),
+		time.Date(2022, 5, 10, 12, 45, 0, 0, time.UTC),
+		time.Date(2022, 5, 10, 13, 20, 0, 0, time.UTC),
+	} {
+		assert.Nil(t, store.Clicks().Create([]models.Click{{CreatedAt: createdAt, ShortUrlId: shortUrl.Id}}))
+	}
+
+	// from and to are partway through the first and last buckets, but the
+	// clicks before from and after to still count towards them.
+	result := subject.GetClickSeries(
+		"slug",
+		time.Date(2022, 5, 10, 12, 30, 0, 0, time.UTC),
+		time.Date(2022, 5, 10, 13, 15, 0, 0, time.UTC),
+		enums.ClickSeriesIntervalHour,
+		time.UTC,
+	)
+
+	assert.Equal(t, enums.GetClicksResultSuccessful, result.Status)
+	assert.Equal(t, []ClickSeriesBucket{
+		{Start: time.Date(2022, 5, 10, 12, 0, 0, 0, time.UTC), Count: 2},
+		{Start: time.Date(2022, 5, 10, 13, 0, 0, 0, time.UTC), Count: 1},
+	}, result.Buckets)
+}
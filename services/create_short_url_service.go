@@ -3,47 +3,119 @@ package services
 import (
 	"errors"
 	"net/url"
+	"url-shortener/db"
+	"url-shortener/e"
 	"url-shortener/enums"
 	"url-shortener/models"
 
-	"github.com/jackc/pgconn"
-	"github.com/jackc/pgerrcode"
+	"url-shortener/repositories"
+
 	"golang.org/x/exp/slices"
-	"gorm.io/gorm"
 )
 
+const (
+	// maxSlugAttempts bounds how many generated slugs are tried for one
+	// short URL, and slugs grow a character longer after every
+	// collisionsPerLength collisions.
+	maxSlugAttempts     = 10
+	collisionsPerLength = 2
+)
+
+var errNoAvailableSlug = errors.New("unable to generate a slug that's allowed and not taken")
+
 type CreateShortUrlService struct {
-	DB *gorm.DB
+	Store      repositories.Store
+	SlugPolicy SlugPolicy
+	// SlugGenerator defaults to DefaultSlugGenerator.
+	SlugGenerator SlugGenerator
+	// Clock decides whether the short URL that already has a long URL has
+	// expired, in which case a new one is created.
+	Clock Clock
 }
 
 type CreationResult struct {
 	Status enums.CreationStatus
 	Record *models.ShortUrl
-	Error  error
+	// ValidationErrors are why the slug is invalid.
+	ValidationErrors []e.ValidationError
+	Error            error
 }
 
-func (s *CreateShortUrlService) Create(request *models.ShortUrl) CreationResult {
-	if request.Slug == "" {
-		randomSlug, err := GenerateSlug()
+// Create creates a short URL on behalf of principal, who must be allowed to
+// use the namespace its slug is in, if any. Chosen slugs have to follow the
+// slug policy, and generated ones always do. Generated slugs that are
+// already taken are generated again. A long URL whose short URL has expired
+// gets a new one, which supersedes the expired one. The creation, and any
+// superseding, is recorded in the audit log, along with origin.
+func (s *CreateShortUrlService) Create(principal *Principal, origin Origin, request *models.ShortUrl) CreationResult {
+	generated := request.Slug == ""
+
+	if !generated {
+		request.Slug = s.SlugPolicy.Normalize(request.Slug)
+
+		if errs := s.SlugPolicy.Validate(request.Slug); errs != nil {
+			return CreationResult{
+				Status:           enums.CreationResultInvalidSlug,
+				ValidationErrors: errs,
+			}
+		}
+
+		check, err := checkSlug(s.Store, principal, request.Slug)
 
 		if err != nil {
 			return CreationResult{
 				Error: err,
 			}
 		}
-		request.Slug = randomSlug
+
+		switch check {
+		case slugOutsideNamespace:
+			return CreationResult{Status: enums.CreationResultSlugOutsideNamespace}
+		case slugForbidden:
+			return CreationResult{Status: enums.CreationResultForbiddenSlug}
+		}
 	}
 
-	validUrl, err := validateLongUrl(request.LongUrl)
+	validUrl, err := validateLongUrl(request.LongUrl, IsTemplate(request.ShortUrlRedirectFields))
 
 	if !validUrl {
+		status := enums.CreationResultInvalidLongUrl
+
+		if IsTemplate(request.ShortUrlRedirectFields) {
+			status = enums.CreationResultInvalidTemplate
+		}
+
 		return CreationResult{
-			Status: enums.CreationResultInvalidLongUrl,
+			Status: status,
 			Error:  err,
 		}
 	}
 
-	err = s.DB.Create(&request).Error
+	if !validFallbackUrl(request.FallbackUrl) {
+		return CreationResult{
+			Status: enums.CreationResultInvalidFallbackUrl,
+		}
+	}
+
+	err = s.Store.Transaction(func(tx repositories.Store) error {
+		if err := supersedeExpired(tx, principal, origin, request, s.Clock.Now()); err != nil {
+			return err
+		}
+
+		var err error
+
+		if generated {
+			err = s.createWithGeneratedSlug(tx, request)
+		} else {
+			err = tx.ShortUrls().Create(request)
+		}
+
+		if err != nil {
+			return err
+		}
+
+		return recordAudit(tx, models.AuditActionCreate, principal, origin, nil, request)
+	})
 
 	if err == nil {
 		return CreationResult{
@@ -58,14 +130,7 @@ func (s *CreateShortUrlService) Create(request *models.ShortUrl) CreationResult
 		}
 	}
 
-	var existing models.ShortUrl
-
-	whereClause := models.ShortUrl{}
-	whereClause.LongUrl = request.LongUrl
-
-	err = s.DB.
-		Where(&whereClause).
-		First(&existing).Error
+	existing, err := s.Store.ShortUrls().FindByLongUrl(request.LongUrl, request.ShortUrlUtmFields)
 
 	if err == nil {
 		return CreationResult{
@@ -74,7 +139,7 @@ func (s *CreateShortUrlService) Create(request *models.ShortUrl) CreationResult
 		}
 	}
 
-	if err == gorm.ErrRecordNotFound {
+	if errors.Is(err, repositories.ErrNotFound) {
 		return CreationResult{
 			Status: enums.CreationResultDuplicateSlug,
 		}
@@ -85,13 +150,120 @@ func (s *CreateShortUrlService) Create(request *models.ShortUrl) CreationResult
 	}
 }
 
+// createWithGeneratedSlug creates a short URL in store, generating its slug.
+// Slugs derived from ids are generated once the short URL has been saved with
+// a placeholder slug, in the same transaction.
+func (s *CreateShortUrlService) createWithGeneratedSlug(store repositories.Store, request *models.ShortUrl) error {
+	generator := s.slugGenerator()
+
+	if !generator.NeedsId() {
+		return s.retryGeneratedSlug(generator, 0, func(slug string) error {
+			request.Slug = slug
+			return store.ShortUrls().Create(request)
+		})
+	}
+
+	return store.Transaction(func(tx repositories.Store) error {
+		placeholder, err := NanoidSlugGenerator{}.Generate(0, 21)
+
+		if err != nil {
+			return err
+		}
+
+		request.Slug = placeholder
+
+		if err := tx.ShortUrls().Create(request); err != nil {
+			return err
+		}
+
+		return s.retryGeneratedSlug(generator, request.Id, func(slug string) error {
+			request.Slug = slug
+			return tx.ShortUrls().Update(request)
+		})
+	})
+}
+
+// retryGeneratedSlug saves generated slugs until one of them isn't taken.
+// Slugs the policy doesn't allow aren't saved at all. Slugs are lengthened
+// as they collide, since collisions mean that short URLs are filling up the
+// slugs of the current length.
+func (s *CreateShortUrlService) retryGeneratedSlug(generator SlugGenerator, id int64, save func(slug string) error) error {
+	length := generator.Length()
+
+	if length < s.SlugPolicy.MinLength {
+		length = s.SlugPolicy.MinLength
+	}
+
+	if s.SlugPolicy.MaxLength > 0 && length > s.SlugPolicy.MaxLength {
+		length = s.SlugPolicy.MaxLength
+	}
+
+	collisions := 0
+
+	for attempt := 1; attempt <= maxSlugAttempts; attempt++ {
+		slug, err := generator.Generate(id, length)
+
+		if err != nil {
+			return err
+		}
+
+		slug = s.SlugPolicy.Normalize(slug)
+		collided := false
+
+		if s.SlugPolicy.Validate(slug) == nil {
+			err = save(slug)
+
+			if !errors.Is(err, repositories.ErrDuplicateSlug) {
+				return err
+			}
+
+			collisions++
+			collided = true
+		}
+
+		// Slugs derived from ids would only be generated again at the same
+		// length. Other slugs the policy doesn't allow are just drawn again.
+		grow := generator.NeedsId() || (collided && collisions%collisionsPerLength == 0)
+
+		if grow && (s.SlugPolicy.MaxLength == 0 || length < s.SlugPolicy.MaxLength) {
+			length++
+		}
+	}
+
+	return errNoAvailableSlug
+}
+
+func (s *CreateShortUrlService) slugGenerator() SlugGenerator {
+	if s.SlugGenerator == nil {
+		return DefaultSlugGenerator(s.SlugPolicy)
+	}
+
+	return s.SlugGenerator
+}
+
 func isUniqueConstraintViolation(err error) bool {
-	var pgErr *pgconn.PgError
+	return violatedUniqueConstraint(err) != db.None
+}
 
-	return errors.As(err, &pgErr) && pgErr.Code == pgerrcode.UniqueViolation
+func violatedUniqueConstraint(err error) db.UniqueConstraintName {
+	switch {
+	case errors.Is(err, repositories.ErrDuplicateSlug):
+		return db.DuplicateSlug
+	case errors.Is(err, repositories.ErrDuplicateLongUrl):
+		return db.DuplicateLongUrl
+	}
+
+	return db.None
 }
 
-func validateLongUrl(longUrl string) (bool, error) {
+// validateLongUrl checks that longUrl is a URL that can be redirected to. The
+// long URL of a template short URL is checked with its placeholders filled
+// in, and they can't be in its scheme or host.
+func validateLongUrl(longUrl string, template bool) (bool, error) {
+	if template {
+		return parseLongUrlTemplate(longUrl).valid()
+	}
+
 	u, err := url.Parse(longUrl)
 
 	if err != nil {
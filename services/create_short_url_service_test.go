@@ -0,0 +1,157 @@
+package services
+
+import (
+	"testing"
+	"time"
+	"url-shortener/enums"
+	"url-shortener/models"
+	"url-shortener/repositories"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/guregu/null.v4"
+)
+
+// scriptedSlugGenerator hands out slugs in order, recording the lengths it
+// was asked for.
+type scriptedSlugGenerator struct {
+	slugs   []string
+	lengths []int
+}
+
+func (g *scriptedSlugGenerator) Generate(id int64, length int) (string, error) {
+	slug := g.slugs[len(g.lengths)]
+	g.lengths = append(g.lengths, length)
+
+	return slug, nil
+}
+
+func (g *scriptedSlugGenerator) Length() int {
+	return 2
+}
+
+func (g *scriptedSlugGenerator) NeedsId() bool {
+	return false
+}
+
+func newShortUrlRequest(longUrl string) *models.ShortUrl {
+	request := &models.ShortUrl{}
+	request.LongUrl = longUrl
+
+	return request
+}
+
+func TestRetriesAndLengthensGeneratedSlugsThatCollide(t *testing.T) {
+	store := repositories.NewMemoryStore()
+
+	for _, slug := range []string{"ab", "cd", "efg"} {
+		createShortUrl(t, store, slug, null.Time{})
+	}
+
+	generator := &scriptedSlugGenerator{slugs: []string{"ab", "cd", "api", "efg", "hijk"}}
+	subject := CreateShortUrlService{
+		Store:         store,
+		SlugPolicy:    SlugPolicy{ReservedWords: DefaultReservedWords},
+		SlugGenerator: generator,
+		Clock:         TestClock{},
+	}
+
+	result := subject.Create(nil, Origin{}, newShortUrlRequest("https://www.google.com"))
+
+	assert.Nil(t, result.Error)
+	assert.Equal(t, enums.CreationResultCreated, result.Status)
+	assert.Equal(t, "hijk", result.Record.Slug)
+	// api is reserved rather than taken, so it doesn't count as a collision.
+	assert.Equal(t, []int{2, 2, 3, 3, 3}, generator.lengths)
+}
+
+func TestGivesUpOnGeneratedSlugsEventually(t *testing.T) {
+	store := repositories.NewMemoryStore()
+	createShortUrl(t, store, "ab", null.Time{})
+
+	slugs := make([]string, maxSlugAttempts)
+
+	for i := range slugs {
+		slugs[i] = "ab"
+	}
+
+	subject := CreateShortUrlService{
+		Store:         store,
+		SlugGenerator: &scriptedSlugGenerator{slugs: slugs},
+		Clock:         TestClock{},
+	}
+
+	result := subject.Create(nil, Origin{}, newShortUrlRequest("https://www.google.com"))
+
+	assert.ErrorIs(t, result.Error, errNoAvailableSlug)
+}
+
+func TestGeneratesSequentialSlugsFromIds(t *testing.T) {
+	store := repositories.NewMemoryStore()
+	subject := CreateShortUrlService{
+		Store:         store,
+		SlugGenerator: SequentialSlugGenerator{},
+		Clock:         TestClock{},
+	}
+
+	// The second generated slug would be 3, which was chosen by the short
+	// URL with id 2, so it's padded.
+	first := subject.Create(nil, Origin{}, newShortUrlRequest("https://www.google.com"))
+	createShortUrl(t, store, "3", null.Time{})
+	second := subject.Create(nil, Origin{}, newShortUrlRequest("https://www.github.com"))
+
+	assert.Equal(t, "1", first.Record.Slug)
+	assert.Equal(t, "03", second.Record.Slug)
+
+	// A long URL that's already shortened doesn't use up an id's slug.
+	again := subject.Create(nil, Origin{}, newShortUrlRequest("https://www.google.com"))
+
+	assert.Equal(t, enums.CreationResultAlreadyExists, again.Status)
+	assert.Equal(t, "1", again.Record.Slug)
+}
+
+func TestSupersedesExpiredShortUrlOfSameLongUrl(t *testing.T) {
+	store := repositories.NewMemoryStore()
+	expired := createShortUrl(t, store, "google", null.TimeFrom(TestClock{}.Now()))
+	createShortUrl(t, store, "github", null.TimeFrom(TestClock{}.Now().Add(time.Hour)))
+
+	subject := CreateShortUrlService{Store: store, Clock: TestClock{}}
+	alice := &Principal{OwnerId: "alice"}
+	origin := Origin{RequestId: "req-1", IpAddress: "192.0.2.1"}
+
+	result := subject.Create(alice, origin, newShortUrlRequest(expired.LongUrl))
+
+	assert.Equal(t, enums.CreationResultCreated, result.Status)
+	assert.NotEqual(t, "google", result.Record.Slug)
+
+	superseded, err := store.ShortUrls().FindBySlug("google")
+
+	assert.Nil(t, err)
+	assert.True(t, superseded.SupersededAt.Valid)
+
+	// Superseding is recorded along with the creation it made way for.
+	events, err := store.AuditEvents().List(repositories.AuditQuery{Slug: "google", Limit: 10})
+
+	assert.Nil(t, err)
+
+	if assert.Len(t, events, 1) {
+		assert.Equal(t, models.AuditActionUpdate, events[0].Action)
+		assert.Equal(t, "alice", events[0].Actor)
+		assert.Equal(t, "req-1", events[0].RequestId)
+		assert.Nil(t, snapshotField(t, events[0].Before.String, "superseded_at"))
+		assert.Equal(t, "2022-05-10T12:00:00Z", snapshotField(t, events[0].After.String, "superseded_at"))
+	}
+
+	// A short URL that hasn't expired yet is still the one.
+	result = subject.Create(nil, Origin{}, newShortUrlRequest("https://github.example.com"))
+
+	assert.Equal(t, enums.CreationResultAlreadyExists, result.Status)
+	assert.Equal(t, "github", result.Record.Slug)
+
+	// Nor can the superseded short URL take its long URL back.
+	updateShortUrlService := UpdateShortUrlService{Store: store, Clock: TestClock{}}
+	update := updateShortUrlService.Update(&Principal{Admin: true}, Origin{}, "google", models.ShortUrlUpdateFields{
+		ExpiresOn: models.OptionalTime{Set: true},
+	})
+
+	assert.Equal(t, enums.UpdateResultDuplicateLongUrl, update.Status)
+}
@@ -1,14 +1,14 @@
 package services
 
 import (
+	"errors"
 	"url-shortener/enums"
 	"url-shortener/models"
-
-	"gorm.io/gorm"
+	"url-shortener/repositories"
 )
 
 type DeleteShortUrlService struct {
-	DB *gorm.DB
+	Store repositories.Store
 }
 
 type DeleteResult struct {
@@ -17,27 +17,48 @@ type DeleteResult struct {
 	Error  error
 }
 
-func (s *DeleteShortUrlService) Delete(slug string) DeleteResult {
-	var shortUrl models.ShortUrl
+// Delete moves the short URL with slug to the trash on behalf of principal,
+// recording it in the audit log along with origin.
+func (s *DeleteShortUrlService) Delete(principal *Principal, origin Origin, slug string) DeleteResult {
+	shortUrl, err := s.Store.ShortUrls().FindBySlug(slug)
 
-	whereClause := models.ShortUrl{}
-	whereClause.Slug = slug
+	if errors.Is(err, repositories.ErrNotFound) {
+		return DeleteResult{
+			Status: enums.DeleteResultNotFound,
+		}
+	}
 
-	res := s.DB.
-		Where(&whereClause).
-		Delete(&shortUrl)
+	if err != nil {
+		return DeleteResult{
+			Status: enums.DeleteResultUnknownError,
+			Error:  err,
+		}
+	}
 
-	response := DeleteResult{}
+	if !principal.CanModify(shortUrl) {
+		return DeleteResult{
+			Status: enums.DeleteResultForbidden,
+		}
+	}
 
-	if res.Error == nil {
-		if res.RowsAffected == 1 {
-			response.Status = enums.DeleteResultSuccessful
-		} else {
-			response.Status = enums.DeleteResultNotFound
+	err = s.Store.Transaction(func(tx repositories.Store) error {
+		if err := tx.ShortUrls().Delete(shortUrl); err != nil {
+			return err
 		}
-	} else {
+
+		return recordAudit(tx, models.AuditActionDelete, principal, origin, &shortUrl, nil)
+	})
+
+	response := DeleteResult{}
+
+	switch {
+	case err == nil:
+		response.Status = enums.DeleteResultSuccessful
+	case errors.Is(err, repositories.ErrNotFound):
+		response.Status = enums.DeleteResultNotFound
+	default:
 		response.Status = enums.DeleteResultUnknownError
-		response.Error = res.Error
+		response.Error = err
 	}
 
 	return response
@@ -0,0 +1,56 @@
+package services
+
+import (
+	"errors"
+	"time"
+	"url-shortener/models"
+	"url-shortener/repositories"
+
+	"gopkg.in/guregu/null.v4"
+)
+
+// IsExpired reports whether shortUrl had expired by now. Expired short URLs
+// stop redirecting straight away, but they're kept as tombstones until the
+// cleanup job deletes them at the end of their grace period, so that their
+// slugs answer 410 Gone rather than 404 Not Found until then.
+func IsExpired(shortUrl models.ShortUrl, now time.Time) bool {
+	return shortUrl.ExpiresOn.Valid && !shortUrl.ExpiresOn.Time.After(now)
+}
+
+// supersedeExpired frees the long URL and UTM parameters of shortUrl for it,
+// if the short URL that has them expired by now. A link that's over
+// shouldn't be handed out again just because it hasn't been deleted yet.
+// Superseding is recorded in the audit log as an update by principal, along
+// with origin.
+func supersedeExpired(tx repositories.Store, principal *Principal, origin Origin, shortUrl *models.ShortUrl, now time.Time) error {
+	existing, err := tx.ShortUrls().FindByLongUrl(shortUrl.LongUrl, shortUrl.ShortUrlUtmFields)
+
+	if errors.Is(err, repositories.ErrNotFound) || (err == nil && !IsExpired(existing, now)) {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	before := existing
+	existing.SupersededAt = null.TimeFrom(now)
+
+	if err := tx.ShortUrls().Update(&existing); err != nil {
+		return err
+	}
+
+	return recordAudit(tx, models.AuditActionUpdate, principal, origin, &before, &existing)
+}
+
+// validFallbackUrl checks that a short URL's fallback URL, if it has one, can
+// be redirected to.
+func validFallbackUrl(fallbackUrl *string) bool {
+	if fallbackUrl == nil {
+		return true
+	}
+
+	valid, _ := validateLongUrl(*fallbackUrl, false)
+
+	return valid
+}
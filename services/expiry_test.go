@@ -0,0 +1,42 @@
+package services
+
+import (
+	"testing"
+	"time"
+	"url-shortener/models"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/guregu/null.v4"
+)
+
+func TestIsExpired(t *testing.T) {
+	now := time.Date(2022, 5, 10, 12, 0, 0, 0, time.UTC)
+
+	type test struct {
+		expiresOn null.Time
+		expected  bool
+	}
+
+	tests := []test{
+		{expiresOn: null.Time{}, expected: false},
+		{expiresOn: null.TimeFrom(now.Add(time.Second)), expected: false},
+		{expiresOn: null.TimeFrom(now), expected: true},
+		{expiresOn: null.TimeFrom(now.Add(-time.Second)), expected: true},
+	}
+
+	for _, tc := range tests {
+		shortUrl := models.ShortUrl{}
+		shortUrl.ExpiresOn = tc.expiresOn
+
+		assert.Equal(t, tc.expected, IsExpired(shortUrl, now))
+	}
+}
+
+func TestValidFallbackUrl(t *testing.T) {
+	valid := "https://www.cloudflare.com"
+	invalid := "javascript:alert(1)"
+
+	assert.True(t, validFallbackUrl(nil))
+	assert.True(t, validFallbackUrl(&valid))
+	assert.False(t, validFallbackUrl(&invalid))
+}
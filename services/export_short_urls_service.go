@@ -0,0 +1,74 @@
+package services
+
+import (
+	"url-shortener/repositories"
+)
+
+// exportFlushInterval is how many records are written between flushes, so
+// that an export reaches the client steadily instead of all at the end.
+const exportFlushInterval = 500
+
+type ExportShortUrlsService struct {
+	Store repositories.Store
+}
+
+// Export writes the short URLs principal may export, with their total number
+// of clicks, to w. Admins export every short URL, and anyone else only the
+// short URLs they own. Short URLs are read from the store one at a time, so
+// the whole table is never held in memory.
+func (s *ExportShortUrlsService) Export(principal *Principal, w ShortUrlRecordWriter) error {
+	var ownerId *string
+
+	if !principal.Admin {
+		ownerId = &principal.OwnerId
+	}
+
+	written := 0
+
+	err := s.Store.ShortUrls().Each(ownerId, func(shortUrl repositories.ListedShortUrl) error {
+		// Exports are written in UTC, whatever time zone the database
+		// connection uses.
+		if shortUrl.ExpiresOn.Valid {
+			shortUrl.ExpiresOn.Time = shortUrl.ExpiresOn.Time.UTC()
+		}
+
+		err := w.Write(ShortUrlRecord{
+			Slug:      shortUrl.Slug,
+			LongUrl:   shortUrl.LongUrl,
+			ExpiresOn: shortUrl.ExpiresOn,
+			CreatedAt: shortUrl.CreatedAt.UTC(),
+			OwnerId:   shortUrl.OwnerId,
+			Clicks:    shortUrl.ClickCount,
+
+			RedirectType: shortUrl.RedirectType,
+			CacheControl: shortUrl.CacheControl,
+			CacheMaxAge:  shortUrl.CacheMaxAge,
+
+			QueryPassthrough: shortUrl.QueryPassthrough,
+			PathPassthrough:  shortUrl.PathPassthrough,
+
+			ShortUrlUtmFields: shortUrl.ShortUrlUtmFields,
+
+			LinkType:    shortUrl.LinkType,
+			FallbackUrl: shortUrl.FallbackUrl,
+		})
+
+		if err != nil {
+			return err
+		}
+
+		written++
+
+		if written%exportFlushInterval == 0 {
+			return w.Flush()
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return err
+	}
+
+	return w.Flush()
+}
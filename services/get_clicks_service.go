@@ -1,14 +1,14 @@
 package services
 
 import (
+	"errors"
 	"time"
 	"url-shortener/enums"
-
-	"gorm.io/gorm"
+	"url-shortener/repositories"
 )
 
 type GetClicksService struct {
-	DB    *gorm.DB
+	Store repositories.Store
 	Clock Clock
 }
 
@@ -18,72 +18,102 @@ type GetClicksResult struct {
 	Error  error
 }
 
-func (s *GetClicksService) GetClicks(slug string, timePeriod enums.GetClicksTimePeriod) GetClicksResult {
+type ClickBreakdownEntry struct {
+	Value string `json:"value"`
+	Count int64  `json:"count"`
+}
 
-	var query *gorm.DB
+type GetClickBreakdownResult struct {
+	Status    enums.GetClicksStatus
+	Breakdown []ClickBreakdownEntry
+	Error     error
+}
 
-	now := s.Clock.Now()
+func (s *GetClicksService) GetClicks(slug string, timePeriod enums.GetClicksTimePeriod) GetClicksResult {
+	return s.GetClicksInRange(slug, s.TimePeriodRange(timePeriod, time.UTC))
+}
 
-	// These are flawed calculations in anything but UTC, but close
-	// enough for now.
-	twentyFourHours := time.Duration(time.Hour * 24)
-	oneWeek := time.Duration(twentyFourHours * 7)
+// TimePeriodRange resolves a named time period to the range of time it
+// currently covers in the given location.
+func (s *GetClicksService) TimePeriodRange(timePeriod enums.GetClicksTimePeriod, location *time.Location) TimeRange {
+	return timePeriodRange(timePeriod, s.Clock.Now().In(location))
+}
 
-	switch timePeriod {
-	case enums.GetClicksTimePeriodAllTime:
-		query = s.AllClicks(slug)
-	case enums.GetClicksTimePeriodPastWeek:
-		time := now.Add(-oneWeek)
-		query = s.ClicksAfter(slug, time)
-	case enums.GetClicksTimePeriod24Hours:
-		time := now.Add(-twentyFourHours)
-		query = s.ClicksAfter(slug, time)
-	}
+func (s *GetClicksService) GetClicksInRange(slug string, timeRange TimeRange) GetClicksResult {
+	shortUrl, err := s.Store.ShortUrls().FindBySlug(slug)
 
-	var count int64
-	countResult := query.Scan(&count)
+	if errors.Is(err, repositories.ErrNotFound) {
+		return GetClicksResult{
+			Status: enums.GetClicksResultNotFound,
+		}
+	}
 
-	if countResult.Error != nil {
+	if err != nil {
 		return GetClicksResult{
-			Error:  countResult.Error,
+			Error:  err,
 			Status: enums.GetClicksResultUnknownError,
 		}
 	}
 
-	if countResult.RowsAffected == 1 {
+	count, err := s.Store.Clicks().Count(shortUrl.Id, timeRange.Start, timeRange.End)
+
+	if err != nil {
 		return GetClicksResult{
-			Count:  count,
-			Status: enums.GetClicksResultSuccessful,
+			Error:  err,
+			Status: enums.GetClicksResultUnknownError,
 		}
 	}
 
 	return GetClicksResult{
-		Status: enums.GetClicksResultNotFound,
+		Count:  count,
+		Status: enums.GetClicksResultSuccessful,
 	}
 }
 
-func (s *GetClicksService) AllClicks(slug string) *gorm.DB {
-	return s.DB.Raw(`
-			SELECT COUNT(*)
-			FROM
-				short_urls
-				LEFT OUTER JOIN clicks ON clicks.short_url_id = short_urls.id
-			WHERE
-				short_urls.slug = ?
-			GROUP BY short_urls.id
-	`, slug)
-}
+// GetClickBreakdown counts clicks in the given time range grouped by one of
+// the request attributes captured when the short URL was accessed. Rollups
+// don't keep those attributes, so clicks that have been pruned aren't
+// included.
+func (s *GetClicksService) GetClickBreakdown(
+	slug string,
+	dimension enums.ClickDimension,
+	timeRange TimeRange,
+) GetClickBreakdownResult {
+	shortUrl, err := s.Store.ShortUrls().FindBySlug(slug)
+
+	if errors.Is(err, repositories.ErrNotFound) {
+		return GetClickBreakdownResult{
+			Status: enums.GetClicksResultNotFound,
+		}
+	}
+
+	if err != nil {
+		return GetClickBreakdownResult{
+			Error:  err,
+			Status: enums.GetClicksResultUnknownError,
+		}
+	}
 
-func (s *GetClicksService) ClicksAfter(slug string, startTime time.Time) *gorm.DB {
-	return s.DB.Raw(`
-			SELECT COUNT(*)
-			FROM
-				short_urls
-				LEFT OUTER JOIN clicks ON
-					clicks.short_url_id = short_urls.id AND
-					clicks.created_at >= ?
-			WHERE
-				short_urls.slug = ?
-			GROUP BY short_urls.id
-	`, startTime, slug)
+	counts, err := s.Store.Clicks().Breakdown(shortUrl.Id, dimension, timeRange.Start, timeRange.End)
+
+	if err != nil {
+		return GetClickBreakdownResult{
+			Error:  err,
+			Status: enums.GetClicksResultUnknownError,
+		}
+	}
+
+	breakdown := []ClickBreakdownEntry{}
+
+	for _, count := range counts {
+		breakdown = append(breakdown, ClickBreakdownEntry{
+			Value: count.Value,
+			Count: count.Count,
+		})
+	}
+
+	return GetClickBreakdownResult{
+		Breakdown: breakdown,
+		Status:    enums.GetClicksResultSuccessful,
+	}
 }
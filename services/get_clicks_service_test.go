@@ -1,14 +1,13 @@
 package services
 
 import (
-	"context"
 	"regexp"
 	"testing"
 	"time"
 	"url-shortener/db"
 	"url-shortener/enums"
 	"url-shortener/models"
-	"url-shortener/test/helpers"
+	"url-shortener/repositories"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/maxatome/go-testdeep/td"
@@ -17,12 +16,14 @@ import (
 
 func TestGetClicks(t *testing.T) {
 	type test struct {
+		mockFound       bool
 		mockCountResult interface{}
 		expectedResult  td.StructFields
 	}
 
 	tests := []test{
 		{
+			mockFound:       true,
 			mockCountResult: 12,
 			expectedResult: td.StructFields{
 				"Error":  nil,
@@ -31,6 +32,7 @@ func TestGetClicks(t *testing.T) {
 			},
 		},
 		{
+			mockFound:       true,
 			mockCountResult: "boom",
 			expectedResult: td.StructFields{
 				"Error":  td.HasPrefix("sql: Scan error"),
@@ -39,7 +41,7 @@ func TestGetClicks(t *testing.T) {
 			},
 		},
 		{
-			mockCountResult: nil,
+			mockFound: false,
 			expectedResult: td.StructFields{
 				"Error":  nil,
 				"Status": enums.GetClicksResultNotFound,
@@ -54,22 +56,28 @@ func TestGetClicks(t *testing.T) {
 	defer sqlDB.Close()
 
 	for _, tc := range tests {
-		rows := sqlmock.NewRows([]string{"count(*)"})
+		shortUrlRows := sqlmock.NewRows([]string{"id", "slug"})
 
-		if tc.mockCountResult != nil {
-			rows.AddRow(tc.mockCountResult)
+		if tc.mockFound {
+			shortUrlRows.AddRow(1, "slug")
 		}
 
-		mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(*) FROM short_urls")).
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "short_urls" WHERE "short_urls"."slug" = $1`)).
 			WithArgs("slug").
-			WillReturnRows(rows)
+			WillReturnRows(shortUrlRows)
+
+		if tc.mockFound {
+			mock.ExpectQuery(regexp.QuoteMeta("AS count")).
+				WithArgs(1, 1).
+				WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(tc.mockCountResult))
+		}
 
 		gormDB, err := db.ConnectDatabaseWithoutMigrating(sqlDB)
 
 		assert.Nil(t, err)
 
 		subject := GetClicksService{
-			DB: gormDB, Clock: SystemClock{},
+			Store: repositories.NewPostgresStore(gormDB), Clock: SystemClock{},
 		}
 
 		result := subject.GetClicks("slug", enums.GetClicksTimePeriodAllTime)
@@ -83,27 +91,15 @@ func TestGetClicks(t *testing.T) {
 }
 
 func TestGetClicksFunctional(t *testing.T) {
-	// Spins up an actual DB, inserts some data, and tests the query
-	if testing.Short() {
-		t.Skip()
-	}
-
-	ctx := context.Background()
-	container, sqlDB, err := helpers.CreateTestContainer(ctx, "clicksdb")
-	if err != nil {
-		t.Fatal(err)
-	}
-
-	defer container.Terminate(ctx)
-	defer sqlDB.Close()
-
-	gormDB, err := db.ConnectDatabase(sqlDB)
+	// Runs the queries against an actual (SQLite) database
+	gormDB, err := db.ConnectSqlite(":memory:")
 	assert.Nil(t, err)
 
+	store := repositories.NewSqliteStore(gormDB)
 	clock := TestClock{}
 
 	subject := GetClicksService{
-		DB:    gormDB,
+		Store: store,
 		Clock: clock,
 	}
 
@@ -121,15 +117,15 @@ func TestGetClicksFunctional(t *testing.T) {
 	shortUrl.Slug = "slug"
 	shortUrl.LongUrl = "https://www.cloudflare.com"
 
-	err = gormDB.Create(&shortUrl).Error
+	err = store.ShortUrls().Create(&shortUrl)
 
 	assert.Nil(t, err)
 
 	for _, time := range times {
-		err = gormDB.Create(&models.Click{
+		err = store.Clicks().Create([]models.Click{{
 			CreatedAt:  time,
 			ShortUrlId: shortUrl.Id,
-		}).Error
+		}})
 
 		assert.Nil(t, err)
 	}
@@ -149,6 +145,15 @@ func TestGetClicksFunctional(t *testing.T) {
 		actual := subject.GetClicks("slug", tc.timePeriod)
 		assert.Equal(t, tc.expectedCount, actual.Count)
 	}
+
+	// Counts stay the same once the clicks have been rolled up.
+	_, err = store.Clicks().Rollup(100)
+	assert.Nil(t, err)
+
+	for _, tc := range tests {
+		actual := subject.GetClicks("slug", tc.timePeriod)
+		assert.Equal(t, tc.expectedCount, actual.Count)
+	}
 }
 
 type TestClock struct{}
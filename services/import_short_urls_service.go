@@ -0,0 +1,315 @@
+package services
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"time"
+	"url-shortener/db"
+	"url-shortener/enums"
+	"url-shortener/models"
+	"url-shortener/repositories"
+
+	"golang.org/x/exp/slices"
+)
+
+// maxImportErrors bounds how many row errors are reported for one import.
+const maxImportErrors = 100
+
+// errImportRolledBack rolls back an import that failed or was a dry run.
+var errImportRolledBack = errors.New("import rolled back")
+
+type ImportShortUrlsService struct {
+	Store      repositories.Store
+	SlugPolicy SlugPolicy
+}
+
+type ImportOptions struct {
+	ConflictStrategy enums.ConflictStrategy
+	// DryRun reports what an import would do without keeping any of it.
+	DryRun bool
+}
+
+type ImportRowError struct {
+	Line   int    `json:"line"`
+	Slug   string `json:"slug,omitempty"`
+	Reason string `json:"reason"`
+}
+
+type ImportResult struct {
+	Created int
+	Updated int
+	Skipped int
+	// Errors lists the rows that couldn't be imported (at most 100 of them).
+	// An import with any errors is rolled back.
+	Errors []ImportRowError
+	// Conflict is set when the fail conflict strategy stopped the import.
+	Conflict  bool
+	Committed bool
+	Error     error
+}
+
+// Import creates the short URLs read from r on behalf of principal. Rows that
+// clash with an existing short URL's slug or long URL are handled according
+// to the conflict strategy. The import runs in a single transaction, which is
+// only committed if every row was imported (or skipped) and it isn't a dry
+// run. Only admins keep the owners in the import; anyone else becomes the
+// owner of the short URLs they import, and anonymous imports are left without
+// an owner. Every short URL that's created or overwritten is recorded in the
+// audit log, along with origin.
+//
+// Click totals can't be turned back into individual clicks, so the clicks on
+// a newly created short URL are restored as a single hourly rollup at the
+// time it was created.
+func (s *ImportShortUrlsService) Import(principal *Principal, origin Origin, r ShortUrlRecordReader, options ImportOptions) ImportResult {
+	result := ImportResult{Errors: []ImportRowError{}}
+
+	err := s.Store.Transaction(func(tx repositories.Store) error {
+		for {
+			record, line, err := r.Read()
+
+			if err == io.EOF {
+				break
+			}
+
+			if err != nil {
+				result.addError(ImportRowError{Line: line, Slug: record.Slug, Reason: err.Error()})
+
+				// A reader can't be trusted to find the next row after a
+				// malformed one.
+				break
+			}
+
+			rowErr, err := s.importRecord(tx, principal, origin, record, options, &result)
+
+			if err != nil {
+				return err
+			}
+
+			if rowErr != "" {
+				result.addError(ImportRowError{Line: line, Slug: record.Slug, Reason: rowErr})
+
+				if result.Conflict {
+					break
+				}
+			}
+		}
+
+		if len(result.Errors) > 0 || options.DryRun {
+			return errImportRolledBack
+		}
+
+		return nil
+	})
+
+	if err != nil && !errors.Is(err, errImportRolledBack) {
+		return ImportResult{Error: err}
+	}
+
+	result.Committed = err == nil
+
+	return result
+}
+
+// importRecord imports a single record, returning the reason it couldn't be
+// imported, if any. Errors are only returned for unexpected database
+// failures.
+func (s *ImportShortUrlsService) importRecord(
+	tx repositories.Store,
+	principal *Principal,
+	origin Origin,
+	record ShortUrlRecord,
+	options ImportOptions,
+	result *ImportResult,
+) (string, error) {
+	if record.Slug == "" {
+		return "slug is required", nil
+	}
+
+	record.Slug = s.SlugPolicy.Normalize(record.Slug)
+
+	if reasons := s.SlugPolicy.violations(record.Slug, MaxSlugSegments); reasons != nil {
+		return "slug " + strings.Join(reasons, ", "), nil
+	}
+
+	check, err := checkSlug(tx, principal, record.Slug)
+
+	if err != nil {
+		return "", err
+	}
+
+	switch check {
+	case slugOutsideNamespace:
+		return "slug must be in a namespace to contain a slash", nil
+	case slugForbidden:
+		return "not allowed to use the namespace of this slug", nil
+	}
+
+	if reason := redirectSettingsViolation(record); reason != "" {
+		return reason, nil
+	}
+
+	template := IsTemplate(models.ShortUrlRedirectFields{LinkType: record.LinkType})
+
+	if validUrl, _ := validateLongUrl(record.LongUrl, template); !validUrl {
+		if template {
+			return "long_url must be an http or https url with placeholders only in its path, query string, or fragment", nil
+		}
+
+		return "long_url must be an http or https url", nil
+	}
+
+	shortUrl := models.ShortUrl{OwnerId: record.OwnerId}
+	shortUrl.Slug = record.Slug
+	shortUrl.LongUrl = record.LongUrl
+	shortUrl.ShortUrlUtmFields = record.ShortUrlUtmFields
+	shortUrl.ExpiresOn = record.ExpiresOn
+	shortUrl.CreatedAt = record.CreatedAt
+	record.copyRedirectFields(&shortUrl.ShortUrlRedirectFields)
+
+	if principal == nil {
+		shortUrl.OwnerId = ""
+	} else if !principal.Admin {
+		shortUrl.OwnerId = principal.OwnerId
+	}
+
+	err = tx.ShortUrls().Create(&shortUrl)
+
+	if err == nil {
+		result.Created++
+
+		if err := recordAudit(tx, models.AuditActionCreate, principal, origin, nil, &shortUrl); err != nil {
+			return "", err
+		}
+
+		return "", s.restoreClicks(tx, shortUrl, record.Clicks)
+	}
+
+	constraint := violatedUniqueConstraint(err)
+
+	if constraint == db.None {
+		return "", err
+	}
+
+	switch options.ConflictStrategy {
+	case enums.ConflictStrategySkip:
+		result.Skipped++
+		return "", nil
+	case enums.ConflictStrategyFail:
+		result.Conflict = true
+
+		if constraint == db.DuplicateSlug {
+			return "slug is already used by another short url", nil
+		}
+
+		return "long_url is already used by another short url", nil
+	}
+
+	// Overwrite the short URL that's in the way.
+	var existing models.ShortUrl
+
+	if constraint == db.DuplicateSlug {
+		existing, err = tx.ShortUrls().FindBySlug(record.Slug)
+	} else {
+		existing, err = tx.ShortUrls().FindByLongUrl(record.LongUrl, record.ShortUrlUtmFields)
+	}
+
+	// Slugs stay taken while their short URLs are in the trash, and those
+	// are for their owners to restore or leave to be purged.
+	if errors.Is(err, repositories.ErrNotFound) && constraint == db.DuplicateSlug {
+		if _, err := tx.ShortUrls().FindDeletedBySlug(record.Slug); err == nil {
+			return "slug is used by a short url in the trash", nil
+		}
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	if !principal.CanModify(existing) {
+		return "not allowed to overwrite the existing short url", nil
+	}
+
+	before := existing
+	existing.Slug = record.Slug
+	existing.LongUrl = record.LongUrl
+	existing.ShortUrlUtmFields = record.ShortUrlUtmFields
+	existing.ExpiresOn = record.ExpiresOn
+	record.copyRedirectFields(&existing.ShortUrlRedirectFields)
+
+	err = tx.ShortUrls().Update(&existing)
+
+	if err == nil {
+		result.Updated++
+		return "", recordAudit(tx, models.AuditActionUpdate, principal, origin, &before, &existing)
+	}
+
+	if violatedUniqueConstraint(err) != db.None {
+		// The slug and the long URL each belong to a different short URL.
+		return "conflicts with more than one existing short url", nil
+	}
+
+	return "", err
+}
+
+// redirectSettingsViolation checks a record's redirect settings the same way
+// creating a short URL through the API does, returning what's wrong with
+// them, if anything.
+func redirectSettingsViolation(record ShortUrlRecord) string {
+	if record.LinkType != nil && !slices.Contains([]string{"standard", "template"}, *record.LinkType) {
+		return "link_type must be standard or template"
+	}
+
+	if record.RedirectType != nil && !slices.Contains([]int{301, 302, 307, 308}, *record.RedirectType) {
+		return "redirect_type must be one of 301, 302, 307 or 308"
+	}
+
+	if record.CacheControl != nil && !slices.Contains([]string{"no-cache", "no-store", "private", "public"}, *record.CacheControl) {
+		return "cache_control must be one of no-cache, no-store, private or public"
+	}
+
+	if record.CacheMaxAge != nil && *record.CacheMaxAge < 0 {
+		return "cache_max_age must not be negative"
+	}
+
+	if record.QueryPassthrough != nil && !slices.Contains([]string{"none", "prefer_incoming", "prefer_destination", "append"}, *record.QueryPassthrough) {
+		return "query_passthrough must be one of none, prefer_incoming, prefer_destination or append"
+	}
+
+	if !validFallbackUrl(record.FallbackUrl) {
+		return "fallback_url must be an http or https url"
+	}
+
+	return ""
+}
+
+// copyRedirectFields sets a short URL's redirect settings to the record's.
+// Settings the record doesn't have are cleared, so that an overwritten short
+// URL follows the server's defaults again.
+func (record ShortUrlRecord) copyRedirectFields(fields *models.ShortUrlRedirectFields) {
+	fields.RedirectType = record.RedirectType
+	fields.CacheControl = record.CacheControl
+	fields.CacheMaxAge = record.CacheMaxAge
+	fields.QueryPassthrough = record.QueryPassthrough
+	fields.PathPassthrough = record.PathPassthrough
+	fields.LinkType = record.LinkType
+	fields.FallbackUrl = record.FallbackUrl
+}
+
+func (s *ImportShortUrlsService) restoreClicks(tx repositories.Store, shortUrl models.ShortUrl, clicks int64) error {
+	if clicks <= 0 {
+		return nil
+	}
+
+	return tx.Clicks().CreateRollup(models.ClickRollup{
+		ShortUrlId: shortUrl.Id,
+		Hour:       shortUrl.CreatedAt.UTC().Truncate(time.Hour),
+		Count:      clicks,
+	})
+}
+
+func (result *ImportResult) addError(rowErr ImportRowError) {
+	if len(result.Errors) < maxImportErrors {
+		result.Errors = append(result.Errors, rowErr)
+	}
+}
@@ -0,0 +1,37 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net"
+	"url-shortener/enums"
+)
+
+// IpAnonymizer makes client IP addresses safe to store alongside clicks.
+// Truncation keeps the network portion of the address (a /24 for IPv4 and a
+// /48 for IPv6), which is still useful for rough grouping. Hashing keeps
+// addresses distinguishable from one another without revealing them.
+type IpAnonymizer struct {
+	Mode enums.IpAnonymizationMode
+	Salt string
+}
+
+func (a IpAnonymizer) Anonymize(ip string) string {
+	parsed := net.ParseIP(ip)
+
+	if parsed == nil {
+		return ""
+	}
+
+	switch a.Mode {
+	case enums.IpAnonymizationHash:
+		sum := sha256.Sum256([]byte(a.Salt + parsed.String()))
+		return hex.EncodeToString(sum[:])
+	default:
+		if ipv4 := parsed.To4(); ipv4 != nil {
+			return ipv4.Mask(net.CIDRMask(24, 32)).String()
+		}
+
+		return parsed.Mask(net.CIDRMask(48, 128)).String()
+	}
+}
@@ -0,0 +1,31 @@
+package services
+
+import (
+	"testing"
+	"url-shortener/enums"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIpAnonymizer(t *testing.T) {
+	type test struct {
+		anonymizer IpAnonymizer
+		ip         string
+		expected   string
+	}
+
+	tests := []test{
+		{anonymizer: IpAnonymizer{}, ip: "203.0.113.195", expected: "203.0.113.0"},
+		{anonymizer: IpAnonymizer{}, ip: "2001:db8:85a3:8d3:1319:8a2e:370:7348", expected: "2001:db8:85a3::"},
+		{anonymizer: IpAnonymizer{}, ip: "not an ip", expected: ""},
+		{
+			anonymizer: IpAnonymizer{Mode: enums.IpAnonymizationHash, Salt: "salt"},
+			ip:         "203.0.113.195",
+			expected:   "6183642b41afa29ca406f03a7f82323bc5fe62734b064eb6cfc31cb99a70979c",
+		},
+	}
+
+	for _, tc := range tests {
+		assert.Equal(t, tc.expected, tc.anonymizer.Anonymize(tc.ip))
+	}
+}
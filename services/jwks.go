@@ -0,0 +1,277 @@
+package services
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// jwksRefetchInterval limits how often a JWKS URL is fetched again because a
+// token was signed with a key we haven't seen, so that tokens with made up
+// key ids can't be used to hammer the identity provider.
+const jwksRefetchInterval = time.Minute
+
+var errUnknownSigningKey = errors.New("unknown signing key")
+
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	// RSA keys.
+	N string `json:"n"`
+	E string `json:"e"`
+	// EC keys.
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+type jsonWebKeySet struct {
+	Keys []jsonWebKey `json:"keys"`
+}
+
+// JwksKeySource looks up token signing keys by key id in a JSON Web Key Set,
+// read either from a local file or from a URL. Keys read from a URL are
+// fetched again when a token refers to a key id that isn't in the set, which
+// picks up keys rotated in by the identity provider.
+type JwksKeySource struct {
+	location string
+	client   *http.Client
+	clock    Clock
+
+	mu          sync.Mutex
+	keys        map[string]crypto.PublicKey
+	lastFetched time.Time
+	// refreshing is closed once the fetch in progress finishes, or is nil if
+	// there isn't one.
+	refreshing chan struct{}
+}
+
+// NewJwksKeySource loads the key set at location, which is either a path to a
+// file or an http(s) URL.
+func NewJwksKeySource(location string, clock Clock) (*JwksKeySource, error) {
+	source := &JwksKeySource{
+		location: location,
+		client:   &http.Client{Timeout: 10 * time.Second},
+		clock:    clock,
+	}
+
+	keys, err := source.load()
+
+	if err != nil {
+		return nil, err
+	}
+
+	source.keys = keys
+	source.lastFetched = clock.Now()
+
+	return source, nil
+}
+
+// Key returns the public key with the given key id. The key set is fetched
+// without holding the lock, so that a slow identity provider doesn't hold up
+// tokens signed with keys we already have, and callers that want a key while
+// a fetch is in progress wait for it instead of fetching again.
+func (s *JwksKeySource) Key(kid string) (crypto.PublicKey, error) {
+	s.mu.Lock()
+
+	if key, ok := s.keys[kid]; ok {
+		s.mu.Unlock()
+		return key, nil
+	}
+
+	if refreshing := s.refreshing; refreshing != nil {
+		s.mu.Unlock()
+		<-refreshing
+		return s.cachedKey(kid)
+	}
+
+	if !s.isRemote() || s.clock.Now().Sub(s.lastFetched) < jwksRefetchInterval {
+		s.mu.Unlock()
+		return nil, errUnknownSigningKey
+	}
+
+	s.lastFetched = s.clock.Now()
+	refreshing := make(chan struct{})
+	s.refreshing = refreshing
+	s.mu.Unlock()
+
+	keys, err := s.load()
+
+	s.mu.Lock()
+
+	if err == nil {
+		s.keys = keys
+	}
+
+	s.refreshing = nil
+	close(refreshing)
+	s.mu.Unlock()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return s.cachedKey(kid)
+}
+
+func (s *JwksKeySource) cachedKey(kid string) (crypto.PublicKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if key, ok := s.keys[kid]; ok {
+		return key, nil
+	}
+
+	return nil, errUnknownSigningKey
+}
+
+func (s *JwksKeySource) isRemote() bool {
+	return strings.HasPrefix(s.location, "http://") || strings.HasPrefix(s.location, "https://")
+}
+
+func (s *JwksKeySource) load() (map[string]crypto.PublicKey, error) {
+	var body []byte
+	var err error
+
+	if s.isRemote() {
+		body, err = s.fetch()
+	} else {
+		body, err = os.ReadFile(s.location)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("error reading jwks from %s: %w", s.location, err)
+	}
+
+	return parseJwks(body)
+}
+
+func (s *JwksKeySource) fetch() ([]byte, error) {
+	res, err := s.client.Get(s.location)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s", res.Status)
+	}
+
+	return io.ReadAll(res.Body)
+}
+
+// parseJwks returns the RSA and EC signing keys in a key set by key id. Keys
+// of other types, or meant for encryption, are skipped.
+func parseJwks(body []byte) (map[string]crypto.PublicKey, error) {
+	var set jsonWebKeySet
+
+	if err := json.Unmarshal(body, &set); err != nil {
+		return nil, fmt.Errorf("error parsing jwks: %w", err)
+	}
+
+	keys := map[string]crypto.PublicKey{}
+
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+
+		var key crypto.PublicKey
+		var err error
+
+		switch jwk.Kty {
+		case "RSA":
+			key, err = jwk.rsaPublicKey()
+		case "EC":
+			key, err = jwk.ecdsaPublicKey()
+		default:
+			continue
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("error parsing jwk %q: %w", jwk.Kid, err)
+		}
+
+		keys[jwk.Kid] = key
+	}
+
+	return keys, nil
+}
+
+func (jwk jsonWebKey) rsaPublicKey() (*rsa.PublicKey, error) {
+	n, err := decodeJwkInt(jwk.N)
+
+	if err != nil {
+		return nil, err
+	}
+
+	e, err := decodeJwkInt(jwk.E)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !e.IsInt64() || e.Int64() > 1<<31-1 {
+		return nil, errors.New("rsa exponent is too large")
+	}
+
+	return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+}
+
+func (jwk jsonWebKey) ecdsaPublicKey() (*ecdsa.PublicKey, error) {
+	var curve elliptic.Curve
+
+	switch jwk.Crv {
+	case "P-256":
+		curve = elliptic.P256()
+	case "P-384":
+		curve = elliptic.P384()
+	case "P-521":
+		curve = elliptic.P521()
+	default:
+		return nil, fmt.Errorf("unsupported curve %q", jwk.Crv)
+	}
+
+	x, err := decodeJwkInt(jwk.X)
+
+	if err != nil {
+		return nil, err
+	}
+
+	y, err := decodeJwkInt(jwk.Y)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !curve.IsOnCurve(x, y) {
+		return nil, errors.New("point is not on the curve")
+	}
+
+	return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+}
+
+func decodeJwkInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return new(big.Int).SetBytes(b), nil
+}
@@ -0,0 +1,73 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/exp/slices"
+)
+
+var ErrInvalidToken = errors.New("invalid token")
+
+type JwtAuthenticatorConfig struct {
+	Issuer   string
+	Audience string
+	// AdminGroup is the group whose members are admins. Leave it empty to
+	// make nobody an admin through a token.
+	AdminGroup string
+}
+
+// JwtAuthenticator authenticates RS256 and ES256 JWTs issued by an identity
+// provider. Tokens must be signed by a key in the key source and carry the
+// configured issuer and audience and an expiration time in the future.
+type JwtAuthenticator struct {
+	Keys   *JwksKeySource
+	Clock  Clock
+	Config JwtAuthenticatorConfig
+}
+
+type jwtClaims struct {
+	Email  string   `json:"email"`
+	Groups []string `json:"groups"`
+	jwt.RegisteredClaims
+}
+
+// Authenticate returns the principal for a token. The subject of the token
+// becomes the owner of anything created with it. Errors for tokens that
+// aren't valid wrap ErrInvalidToken.
+func (a *JwtAuthenticator) Authenticate(token string) (*Principal, error) {
+	var claims jwtClaims
+
+	_, err := jwt.ParseWithClaims(
+		token,
+		&claims,
+		a.key,
+		jwt.WithValidMethods([]string{jwt.SigningMethodRS256.Alg(), jwt.SigningMethodES256.Alg()}),
+		jwt.WithIssuer(a.Config.Issuer),
+		jwt.WithAudience(a.Config.Audience),
+		jwt.WithExpirationRequired(),
+		jwt.WithTimeFunc(a.Clock.Now),
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	if claims.Subject == "" {
+		return nil, fmt.Errorf("%w: token has no subject", ErrInvalidToken)
+	}
+
+	return &Principal{
+		OwnerId: claims.Subject,
+		Email:   claims.Email,
+		Groups:  claims.Groups,
+		Admin:   a.Config.AdminGroup != "" && slices.Contains(claims.Groups, a.Config.AdminGroup),
+	}, nil
+}
+
+func (a *JwtAuthenticator) key(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+
+	return a.Keys.Key(kid)
+}
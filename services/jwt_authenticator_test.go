@@ -0,0 +1,246 @@
+package services
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/maxatome/go-testdeep/td"
+	"github.com/stretchr/testify/assert"
+)
+
+type fixedClock struct {
+	now time.Time
+}
+
+func (c fixedClock) Now() time.Time {
+	return c.now
+}
+
+func TestJwtAuthenticator(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.Nil(t, err)
+
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.Nil(t, err)
+
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.Nil(t, err)
+
+	jwks, err := json.Marshal(jsonObject{"keys": []jsonObject{
+		{
+			"kty": "RSA",
+			"kid": "rsa",
+			"use": "sig",
+			"n":   encodeJwkInt(rsaKey.N),
+			"e":   encodeJwkInt(big.NewInt(int64(rsaKey.E))),
+		},
+		{
+			"kty": "EC",
+			"kid": "ec",
+			"crv": "P-256",
+			"x":   encodeJwkInt(ecKey.X),
+			"y":   encodeJwkInt(ecKey.Y),
+		},
+	}})
+	assert.Nil(t, err)
+
+	jwksPath := filepath.Join(t.TempDir(), "jwks.json")
+	assert.Nil(t, os.WriteFile(jwksPath, jwks, 0o600))
+
+	clock := fixedClock{now: time.Date(2022, 5, 10, 12, 0, 0, 0, time.UTC)}
+
+	keys, err := NewJwksKeySource(jwksPath, clock)
+	assert.Nil(t, err)
+
+	subject := JwtAuthenticator{
+		Keys:  keys,
+		Clock: clock,
+		Config: JwtAuthenticatorConfig{
+			Issuer:     "https://idp.example.com",
+			Audience:   "url-shortener",
+			AdminGroup: "admins",
+		},
+	}
+
+	validClaims := func() jwt.MapClaims {
+		return jwt.MapClaims{
+			"iss":    "https://idp.example.com",
+			"aud":    "url-shortener",
+			"sub":    "alice",
+			"email":  "alice@example.com",
+			"groups": []string{"engineering"},
+			"exp":    clock.now.Add(time.Hour).Unix(),
+		}
+	}
+
+	sign := func(method jwt.SigningMethod, kid string, key interface{}, claims jwt.MapClaims) string {
+		token := jwt.NewWithClaims(method, claims)
+		token.Header["kid"] = kid
+
+		signed, err := token.SignedString(key)
+		assert.Nil(t, err)
+
+		return signed
+	}
+
+	type test struct {
+		name     string
+		token    string
+		expected interface{}
+	}
+
+	adminClaims := validClaims()
+	adminClaims["groups"] = []string{"engineering", "admins"}
+
+	wrongIssuer := validClaims()
+	wrongIssuer["iss"] = "https://evil.example.com"
+
+	wrongAudience := validClaims()
+	wrongAudience["aud"] = "something-else"
+
+	expired := validClaims()
+	expired["exp"] = clock.now.Add(-time.Minute).Unix()
+
+	noExpiration := validClaims()
+	delete(noExpiration, "exp")
+
+	tests := []test{
+		{
+			name:  "RS256",
+			token: sign(jwt.SigningMethodRS256, "rsa", rsaKey, validClaims()),
+			expected: &Principal{
+				OwnerId: "alice",
+				Email:   "alice@example.com",
+				Groups:  []string{"engineering"},
+			},
+		},
+		{
+			name:  "ES256",
+			token: sign(jwt.SigningMethodES256, "ec", ecKey, validClaims()),
+			expected: &Principal{
+				OwnerId: "alice",
+				Email:   "alice@example.com",
+				Groups:  []string{"engineering"},
+			},
+		},
+		{
+			name:     "admin group",
+			token:    sign(jwt.SigningMethodRS256, "rsa", rsaKey, adminClaims),
+			expected: td.Struct(&Principal{OwnerId: "alice", Admin: true}, nil),
+		},
+		{name: "wrong issuer", token: sign(jwt.SigningMethodRS256, "rsa", rsaKey, wrongIssuer)},
+		{name: "wrong audience", token: sign(jwt.SigningMethodRS256, "rsa", rsaKey, wrongAudience)},
+		{name: "expired", token: sign(jwt.SigningMethodRS256, "rsa", rsaKey, expired)},
+		{name: "no expiration", token: sign(jwt.SigningMethodRS256, "rsa", rsaKey, noExpiration)},
+		{name: "unknown key", token: sign(jwt.SigningMethodRS256, "other", otherKey, validClaims())},
+		{name: "wrong key", token: sign(jwt.SigningMethodRS256, "rsa", otherKey, validClaims())},
+		{name: "HS256", token: sign(jwt.SigningMethodHS256, "rsa", []byte("secret"), validClaims())},
+		{name: "garbage", token: "not.a.token"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			principal, err := subject.Authenticate(tc.token)
+
+			if tc.expected == nil {
+				assert.ErrorIs(t, err, ErrInvalidToken)
+				td.Cmp(t, principal, td.Nil())
+				return
+			}
+
+			td.CmpNoError(t, err)
+			td.Cmp(t, principal, tc.expected)
+		})
+	}
+}
+
+func TestJwksKeySourceRefetch(t *testing.T) {
+	firstKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.Nil(t, err)
+
+	rotatedKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.Nil(t, err)
+
+	rsaJwk := func(kid string, key *rsa.PrivateKey) jsonObject {
+		return jsonObject{
+			"kty": "RSA",
+			"kid": kid,
+			"n":   encodeJwkInt(key.N),
+			"e":   encodeJwkInt(big.NewInt(int64(key.E))),
+		}
+	}
+
+	var fetches int32
+	fetching := make(chan struct{})
+	release := make(chan struct{})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		keys := []jsonObject{rsaJwk("first", firstKey)}
+
+		if atomic.AddInt32(&fetches, 1) > 1 {
+			close(fetching)
+			<-release
+			keys = append(keys, rsaJwk("rotated", rotatedKey))
+		}
+
+		assert.Nil(t, json.NewEncoder(w).Encode(jsonObject{"keys": keys}))
+	}))
+	defer server.Close()
+
+	clock := fixedClock{now: time.Date(2022, 5, 10, 12, 0, 0, 0, time.UTC)}
+
+	subject, err := NewJwksKeySource(server.URL, clock)
+	assert.Nil(t, err)
+
+	subject.lastFetched = clock.now.Add(-jwksRefetchInterval)
+
+	var wg sync.WaitGroup
+	rotated := make([]interface{}, 2)
+
+	for i := range rotated {
+		wg.Add(1)
+
+		go func(i int) {
+			defer wg.Done()
+
+			key, err := subject.Key("rotated")
+			td.CmpNoError(t, err)
+			rotated[i] = key
+		}(i)
+
+		if i == 0 {
+			<-fetching
+		}
+	}
+
+	// Keys we already have don't wait for the fetch in progress.
+	key, err := subject.Key("first")
+	td.CmpNoError(t, err)
+	td.Cmp(t, key, &firstKey.PublicKey)
+
+	close(release)
+	wg.Wait()
+
+	td.Cmp(t, rotated, []interface{}{&rotatedKey.PublicKey, &rotatedKey.PublicKey})
+	td.Cmp(t, atomic.LoadInt32(&fetches), int32(2))
+}
+
+type jsonObject map[string]interface{}
+
+func encodeJwkInt(i *big.Int) string {
+	return base64.RawURLEncoding.EncodeToString(i.Bytes())
+}
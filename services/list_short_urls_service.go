@@ -0,0 +1,175 @@
+package services
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"time"
+	"url-shortener/enums"
+	"url-shortener/models"
+	"url-shortener/repositories"
+)
+
+type ListShortUrlsService struct {
+	Store repositories.Store
+}
+
+// ListShortUrlsQuery selects a page of short URLs. Zero values leave a
+// filter out.
+type ListShortUrlsQuery struct {
+	// OwnerId only lists short URLs with this owner, if it isn't nil.
+	OwnerId *string
+	// Namespace only lists the short URLs under a namespace's prefix, and
+	// SlugPrefix is then relative to it.
+	Namespace       string
+	SlugPrefix      string
+	LongUrlContains string
+	CreatedAfter    time.Time
+	CreatedBefore   time.Time
+	ExpiresBefore   time.Time
+	// HasExpiry only lists short URLs with (or without) an expiration date,
+	// if it isn't nil.
+	HasExpiry *bool
+	// Deleted lists the trash instead.
+	Deleted bool
+
+	Sort  enums.ShortUrlSort
+	Order enums.SortOrder
+	Limit int
+	// Cursor is the NextCursor of the previous page, or empty for the first
+	// page.
+	Cursor string
+}
+
+type ListShortUrlsResult struct {
+	Status  enums.ListStatus
+	Records []models.ShortUrl
+	// NextCursor is empty when there are no more pages.
+	NextCursor string
+	Error      error
+}
+
+// listCursor is the position of the last short URL on a page. The sort,
+// order, and whether it's in the trash are included so that a cursor can't be
+// used with a different list than the one it came from.
+type listCursor struct {
+	Sort      enums.ShortUrlSort `json:"s"`
+	Order     enums.SortOrder    `json:"o"`
+	Deleted   bool               `json:"d,omitempty"`
+	CreatedAt time.Time          `json:"c,omitempty"`
+	Slug      string             `json:"sl,omitempty"`
+	Clicks    int64              `json:"n,omitempty"`
+	Id        int64              `json:"id"`
+}
+
+// List returns short URLs using keyset pagination: each page continues from
+// the sort key and id of the last short URL on the previous one, so pages stay
+// consistent while short URLs are created and deleted, and deep pages are as
+// cheap as the first one.
+func (s *ListShortUrlsService) List(query ListShortUrlsQuery) ListShortUrlsResult {
+	repositoryQuery := repositories.ListQuery{
+		OwnerId:         query.OwnerId,
+		SlugPrefix:      query.SlugPrefix,
+		LongUrlContains: query.LongUrlContains,
+		CreatedAfter:    query.CreatedAfter,
+		CreatedBefore:   query.CreatedBefore,
+		ExpiresBefore:   query.ExpiresBefore,
+		HasExpiry:       query.HasExpiry,
+		Deleted:         query.Deleted,
+		Sort:            query.Sort,
+		Order:           query.Order,
+		// Fetching one extra short URL tells us whether there's another
+		// page.
+		Limit: query.Limit + 1,
+	}
+
+	if query.Namespace != "" {
+		repositoryQuery.SlugPrefix = query.Namespace + "/" + query.SlugPrefix
+	}
+
+	if query.Cursor != "" {
+		cursor, ok := decodeListCursor(query.Cursor)
+
+		if !ok || cursor.Sort != query.Sort || cursor.Order != query.Order || cursor.Deleted != query.Deleted {
+			return ListShortUrlsResult{
+				Status: enums.ListResultInvalidCursor,
+			}
+		}
+
+		repositoryQuery.After = cursor.position()
+	}
+
+	rows, err := s.Store.ShortUrls().List(repositoryQuery)
+
+	if err != nil {
+		return ListShortUrlsResult{
+			Status: enums.ListResultUnknownError,
+			Error:  err,
+		}
+	}
+
+	result := ListShortUrlsResult{
+		Status:  enums.ListResultSuccessful,
+		Records: []models.ShortUrl{},
+	}
+
+	if len(rows) > query.Limit {
+		rows = rows[:query.Limit]
+		result.NextCursor = encodeListCursor(query, rows[len(rows)-1])
+	}
+
+	for _, row := range rows {
+		result.Records = append(result.Records, row.ShortUrl)
+	}
+
+	return result
+}
+
+// position is the short URL the cursor points at, as far as the list needs
+// to know.
+func (cursor listCursor) position() *repositories.ListedShortUrl {
+	position := &repositories.ListedShortUrl{ClickCount: cursor.Clicks}
+	position.Id = cursor.Id
+	position.Slug = cursor.Slug
+	position.CreatedAt = cursor.CreatedAt
+
+	return position
+}
+
+func encodeListCursor(query ListShortUrlsQuery, last repositories.ListedShortUrl) string {
+	cursor := listCursor{
+		Sort:    query.Sort,
+		Order:   query.Order,
+		Deleted: query.Deleted,
+		Id:      last.Id,
+	}
+
+	switch query.Sort {
+	case enums.ShortUrlSortCreatedAt:
+		cursor.CreatedAt = last.CreatedAt
+	case enums.ShortUrlSortSlug:
+		cursor.Slug = last.Slug
+	case enums.ShortUrlSortClicks:
+		cursor.Clicks = last.ClickCount
+	}
+
+	// Marshaling a struct of plain fields can't fail.
+	b, _ := json.Marshal(cursor)
+
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodeListCursor(s string) (listCursor, bool) {
+	var cursor listCursor
+
+	b, err := base64.RawURLEncoding.DecodeString(s)
+
+	if err != nil {
+		return cursor, false
+	}
+
+	if err := json.Unmarshal(b, &cursor); err != nil {
+		return cursor, false
+	}
+
+	return cursor, true
+}
@@ -0,0 +1,115 @@
+package services
+
+import (
+	"regexp"
+	"testing"
+	"time"
+	"url-shortener/db"
+	"url-shortener/enums"
+	"url-shortener/repositories"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/maxatome/go-testdeep/td"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListShortUrlsPaginatesWithCursor(t *testing.T) {
+	sqlDB, mock, err := sqlmock.New()
+
+	assert.Nil(t, err)
+	defer sqlDB.Close()
+
+	gormDB, err := db.ConnectDatabaseWithoutMigrating(sqlDB)
+
+	assert.Nil(t, err)
+
+	subject := ListShortUrlsService{Store: repositories.NewPostgresStore(gormDB)}
+
+	createdAt := time.Date(2022, 5, 11, 11, 30, 0, 0, time.UTC)
+	columns := []string{"id", "slug", "long_url", "created_at"}
+
+	mock.ExpectQuery(regexp.QuoteMeta(
+		`SELECT * FROM "short_urls" WHERE short_urls.deleted_at IS NULL AND short_urls.slug LIKE $1 ESCAPE '\' ` +
+			`ORDER BY short_urls.created_at DESC,short_urls.id DESC LIMIT 3`,
+	)).
+		WithArgs(`my\_%`).
+		WillReturnRows(
+			sqlmock.NewRows(columns).
+				AddRow(3, "my_c", "https://c.example.com", createdAt.Add(2*time.Minute)).
+				AddRow(2, "my_b", "https://b.example.com", createdAt.Add(time.Minute)).
+				AddRow(1, "my_a", "https://a.example.com", createdAt),
+		)
+
+	query := ListShortUrlsQuery{
+		SlugPrefix: "my_",
+		Order:      enums.SortOrderDescending,
+		Limit:      2,
+	}
+
+	firstPage := subject.List(query)
+
+	td.Cmp(t, firstPage, td.Struct(ListShortUrlsResult{
+		Status: enums.ListResultSuccessful,
+	}, td.StructFields{
+		"Records":    td.Len(2),
+		"NextCursor": td.NotEmpty(),
+	}))
+
+	mock.ExpectQuery(regexp.QuoteMeta(
+		`(short_urls.created_at, short_urls.id) < ($2, $3)`,
+	)).
+		WithArgs(`my\_%`, createdAt.Add(time.Minute), 2).
+		WillReturnRows(
+			sqlmock.NewRows(columns).
+				AddRow(1, "my_a", "https://a.example.com", createdAt),
+		)
+
+	query.Cursor = firstPage.NextCursor
+
+	secondPage := subject.List(query)
+
+	td.Cmp(t, secondPage, td.Struct(ListShortUrlsResult{
+		Status:     enums.ListResultSuccessful,
+		NextCursor: "",
+	}, td.StructFields{
+		"Records": td.Len(1),
+	}))
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestListShortUrlsRejectsCursorFromAnotherSort(t *testing.T) {
+	subject := ListShortUrlsService{}
+
+	cursor := encodeListCursor(ListShortUrlsQuery{Sort: enums.ShortUrlSortSlug}, repositories.ListedShortUrl{})
+
+	result := subject.List(ListShortUrlsQuery{
+		Sort:   enums.ShortUrlSortCreatedAt,
+		Cursor: cursor,
+		Limit:  10,
+	})
+
+	assert.Equal(t, enums.ListResultInvalidCursor, result.Status)
+
+	result = subject.List(ListShortUrlsQuery{
+		Cursor: "not a cursor",
+		Limit:  10,
+	})
+
+	assert.Equal(t, enums.ListResultInvalidCursor, result.Status)
+}
+
+func TestListShortUrlsRejectsCursorFromTrash(t *testing.T) {
+	subject := ListShortUrlsService{}
+
+	cursor := encodeListCursor(ListShortUrlsQuery{Deleted: true}, repositories.ListedShortUrl{})
+
+	result := subject.List(ListShortUrlsQuery{
+		Cursor: cursor,
+		Limit:  10,
+	})
+
+	assert.Equal(t, enums.ListResultInvalidCursor, result.Status)
+}
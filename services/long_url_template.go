@@ -0,0 +1,186 @@
+package services
+
+import (
+	"errors"
+	"net/url"
+	"regexp"
+	"strings"
+	"url-shortener/models"
+)
+
+// ErrTemplateMismatch means a request for a template short URL didn't supply
+// a value for every placeholder, or supplied more path segments than there
+// are placeholders.
+var ErrTemplateMismatch = errors.New("request doesn't match the template")
+
+var placeholderPattern = regexp.MustCompile(`\{([A-Za-z][A-Za-z0-9_]*)\}`)
+
+type urlSection int
+
+const (
+	sectionAuthority urlSection = iota
+	sectionPath
+	sectionQuery
+	sectionFragment
+)
+
+// longUrlTemplate is the long URL of a template short URL, such as
+// https://jira.corp/browse/{ticket}. Placeholders can be in its path, query
+// string, or fragment, and values are escaped for the section they're in.
+type longUrlTemplate struct {
+	raw string
+	// placeholders are the positions of each placeholder in raw, as returned
+	// by FindAllStringSubmatchIndex.
+	placeholders [][]int
+	// names are the distinct placeholder names in the order they first
+	// appear, which is the order path segments fill them in.
+	names []string
+
+	pathStart     int
+	queryStart    int
+	fragmentStart int
+}
+
+// IsTemplate is whether a short URL's long URL is a template.
+func IsTemplate(fields models.ShortUrlRedirectFields) bool {
+	return fields.LinkType != nil && *fields.LinkType == "template"
+}
+
+func parseLongUrlTemplate(raw string) longUrlTemplate {
+	template := longUrlTemplate{
+		raw:          raw,
+		placeholders: placeholderPattern.FindAllStringSubmatchIndex(raw, -1),
+	}
+
+	seen := map[string]bool{}
+
+	for _, placeholder := range template.placeholders {
+		name := raw[placeholder[2]:placeholder[3]]
+
+		if !seen[name] {
+			seen[name] = true
+			template.names = append(template.names, name)
+		}
+	}
+
+	// The authority follows the scheme, up to the first of "/", "?" or "#".
+	template.pathStart = len(raw)
+
+	if schemeEnd := strings.Index(raw, "://"); schemeEnd >= 0 {
+		if end := strings.IndexAny(raw[schemeEnd+3:], "/?#"); end >= 0 {
+			template.pathStart = schemeEnd + 3 + end
+		}
+	}
+
+	template.fragmentStart = len(raw)
+
+	if start := strings.Index(raw[template.pathStart:], "#"); start >= 0 {
+		template.fragmentStart = template.pathStart + start
+	}
+
+	template.queryStart = template.fragmentStart
+
+	if start := strings.Index(raw[template.pathStart:template.fragmentStart], "?"); start >= 0 {
+		template.queryStart = template.pathStart + start
+	}
+
+	return template
+}
+
+func (t longUrlTemplate) section(offset int) urlSection {
+	switch {
+	case offset < t.pathStart:
+		return sectionAuthority
+	case offset < t.queryStart:
+		return sectionPath
+	case offset < t.fragmentStart:
+		return sectionQuery
+	}
+
+	return sectionFragment
+}
+
+// valid checks that the template has placeholders, that none of them can
+// change the scheme or host, and that it's a valid long URL once they're
+// filled in.
+func (t longUrlTemplate) valid() (bool, error) {
+	if len(t.placeholders) == 0 {
+		return false, nil
+	}
+
+	values := map[string]string{}
+
+	for _, placeholder := range t.placeholders {
+		if t.section(placeholder[0]) == sectionAuthority {
+			return false, nil
+		}
+
+		values[t.raw[placeholder[2]:placeholder[3]]] = "value"
+	}
+
+	return validateLongUrl(t.fill(values), false)
+}
+
+func (t longUrlTemplate) fill(values map[string]string) string {
+	var filled strings.Builder
+	last := 0
+
+	for _, placeholder := range t.placeholders {
+		filled.WriteString(t.raw[last:placeholder[0]])
+
+		value := values[t.raw[placeholder[2]:placeholder[3]]]
+
+		if t.section(placeholder[0]) == sectionQuery {
+			filled.WriteString(url.QueryEscape(value))
+		} else {
+			filled.WriteString(url.PathEscape(value))
+		}
+
+		last = placeholder[1]
+	}
+
+	filled.WriteString(t.raw[last:])
+
+	return filled.String()
+}
+
+// fillTemplate fills the placeholders of a template short URL's long URL with
+// the segments of rest, in order, and then with the query parameters named
+// after the placeholders that are left. It returns the filled long URL along
+// with the query parameters that weren't used.
+func fillTemplate(longUrl string, rest string, query url.Values) (string, url.Values, error) {
+	template := parseLongUrlTemplate(longUrl)
+
+	var segments []string
+
+	if trimmed := strings.Trim(rest, "/"); trimmed != "" {
+		segments = strings.Split(trimmed, "/")
+	}
+
+	if len(segments) > len(template.names) {
+		return "", nil, ErrTemplateMismatch
+	}
+
+	values := map[string]string{}
+	unused := url.Values{}
+
+	for key, value := range query {
+		unused[key] = value
+	}
+
+	for i, name := range template.names {
+		if i < len(segments) {
+			values[name] = segments[i]
+		} else {
+			values[name] = query.Get(name)
+			unused.Del(name)
+		}
+
+		// Path segments can't be climbed out of, even escaped.
+		if values[name] == "" || values[name] == "." || values[name] == ".." {
+			return "", nil, ErrTemplateMismatch
+		}
+	}
+
+	return template.fill(values), unused, nil
+}
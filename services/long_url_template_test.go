@@ -0,0 +1,75 @@
+package services
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidatesLongUrlTemplates(t *testing.T) {
+	tests := map[string]bool{
+		"https://jira.corp/browse/{ticket}":                     true,
+		"https://github.com/org/{repo}/issues/{issue}":          true,
+		"https://www.google.com/search?q={query}#{section}":     true,
+		"https://docs.example.com/{version}/{version}/index":    true,
+		"https://jira.corp/browse/":                             false,
+		"https://{host}/browse":                                 false,
+		"https://jira.{domain}/browse/{ticket}":                 false,
+		"https://{user}@jira.corp/browse":                       false,
+		"{scheme}://jira.corp/browse/{ticket}":                  false,
+		"ftp://files.example.com/{file}":                        false,
+		"https://jira.corp/browse/{1ticket}":                    false,
+		"https://jira.corp?ticket={ticket}":                     true,
+		"https://jira.corp#/browse/{ticket}":                    true,
+		"https://jira.corp/browse/{ticket}?utm_source={source}": true,
+	}
+
+	for template, expected := range tests {
+		valid, _ := validateLongUrl(template, true)
+
+		assert.Equal(t, expected, valid, template)
+	}
+}
+
+func TestFillsTemplateFromPathThenQuery(t *testing.T) {
+	longUrl, unused, err := fillTemplate(
+		"https://github.com/org/{repo}/issues/{issue}",
+		"/url-shortener",
+		url.Values{"issue": {"42"}, "lang": {"en"}},
+	)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "https://github.com/org/url-shortener/issues/42", longUrl)
+	assert.Equal(t, url.Values{"lang": {"en"}}, unused)
+}
+
+func TestEscapesTemplateValuesForTheirSection(t *testing.T) {
+	longUrl, _, err := fillTemplate(
+		"https://www.google.com/{page}?q={query}#{section}",
+		"",
+		url.Values{"page": {"a/b?c"}, "query": {"x&y=z #"}, "section": {"d e"}},
+	)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "https://www.google.com/a%2Fb%3Fc?q=x%26y%3Dz+%23#d%20e", longUrl)
+}
+
+func TestRejectsRequestsThatDontMatchTemplate(t *testing.T) {
+	tests := []struct {
+		rest  string
+		query url.Values
+	}{
+		{"", nil},
+		{"/ABC-1/extra", nil},
+		{"/..", nil},
+		{"", url.Values{"ticket": {"."}}},
+		{"", url.Values{"ticket": {""}}},
+	}
+
+	for _, test := range tests {
+		_, _, err := fillTemplate("https://jira.corp/browse/{ticket}", test.rest, test.query)
+
+		assert.ErrorIs(t, err, ErrTemplateMismatch, test.rest)
+	}
+}
@@ -0,0 +1,208 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"url-shortener/e"
+	"url-shortener/enums"
+	"url-shortener/models"
+	"url-shortener/repositories"
+)
+
+// MaxSlugSegments is the most "/"-separated segments a slug can have, which
+// bounds how many slugs a request's path is looked up as.
+const MaxSlugSegments = 5
+
+type NamespaceService struct {
+	Store repositories.Store
+	// SlugPolicy applies to prefixes as well as slugs.
+	SlugPolicy SlugPolicy
+}
+
+type NamespaceResult struct {
+	Status enums.NamespaceStatus
+	Record *models.Namespace
+	// ValidationErrors are why the prefix is invalid.
+	ValidationErrors []e.ValidationError
+	Error            error
+}
+
+type slugCheck int
+
+const (
+	slugAllowed slugCheck = iota
+	slugOutsideNamespace
+	slugForbidden
+)
+
+func (s *NamespaceService) List() ([]models.Namespace, error) {
+	return s.Store.Namespaces().List()
+}
+
+// Create creates a namespace. Only admins may, which is up to the caller to
+// check.
+func (s *NamespaceService) Create(fields models.NamespaceCreateFields) NamespaceResult {
+	fields.Prefix = s.SlugPolicy.Normalize(fields.Prefix)
+
+	if errs := s.SlugPolicy.validatePrefix(fields.Prefix); errs != nil {
+		return NamespaceResult{
+			Status:           enums.NamespaceResultInvalidPrefix,
+			ValidationErrors: errs,
+		}
+	}
+
+	namespace := models.Namespace{NamespaceCreateFields: fields}
+	err := s.Store.Namespaces().Create(&namespace)
+
+	switch {
+	case err == nil:
+		return NamespaceResult{
+			Status: enums.NamespaceResultSuccessful,
+			Record: &namespace,
+		}
+	case errors.Is(err, repositories.ErrDuplicatePrefix):
+		return NamespaceResult{
+			Status: enums.NamespaceResultDuplicatePrefix,
+		}
+	}
+
+	return NamespaceResult{
+		Status: enums.NamespaceResultUnknownError,
+		Error:  err,
+	}
+}
+
+// Update hands a namespace over to a new owner on behalf of principal.
+func (s *NamespaceService) Update(principal *Principal, prefix string, fields models.NamespaceUpdateFields) NamespaceResult {
+	namespace, result := s.find(principal, prefix)
+
+	if result.Status != enums.NamespaceResultSuccessful {
+		return result
+	}
+
+	if fields.OwnerId != nil {
+		namespace.OwnerId = *fields.OwnerId
+	}
+
+	if err := s.Store.Namespaces().Update(&namespace); err != nil {
+		return NamespaceResult{
+			Status: enums.NamespaceResultUnknownError,
+			Error:  err,
+		}
+	}
+
+	return NamespaceResult{
+		Status: enums.NamespaceResultSuccessful,
+		Record: &namespace,
+	}
+}
+
+// Delete deletes a namespace on behalf of principal. The short URLs in it
+// keep working, but no new ones can be created under its prefix.
+func (s *NamespaceService) Delete(principal *Principal, prefix string) NamespaceResult {
+	namespace, result := s.find(principal, prefix)
+
+	if result.Status != enums.NamespaceResultSuccessful {
+		return result
+	}
+
+	err := s.Store.Namespaces().Delete(namespace)
+
+	switch {
+	case err == nil:
+		return NamespaceResult{
+			Status: enums.NamespaceResultSuccessful,
+			Record: &namespace,
+		}
+	case errors.Is(err, repositories.ErrNotFound):
+		return NamespaceResult{
+			Status: enums.NamespaceResultNotFound,
+		}
+	}
+
+	return NamespaceResult{
+		Status: enums.NamespaceResultUnknownError,
+		Error:  err,
+	}
+}
+
+func (s *NamespaceService) find(principal *Principal, prefix string) (models.Namespace, NamespaceResult) {
+	namespace, err := s.Store.Namespaces().FindByPrefix(s.SlugPolicy.Normalize(prefix))
+
+	switch {
+	case errors.Is(err, repositories.ErrNotFound):
+		return namespace, NamespaceResult{Status: enums.NamespaceResultNotFound}
+	case err != nil:
+		return namespace, NamespaceResult{Status: enums.NamespaceResultUnknownError, Error: err}
+	case !principal.CanUseNamespace(namespace):
+		return namespace, NamespaceResult{Status: enums.NamespaceResultForbidden}
+	}
+
+	return namespace, NamespaceResult{Status: enums.NamespaceResultSuccessful}
+}
+
+// checkSlug checks that principal may give a short URL slug, which must
+// already follow the slug policy. Slugs with a "/" are hierarchical: they
+// must be in the namespace with the longest prefix that they start with (or
+// equal), and only its owner may use them. A plain slug that's also a
+// namespace's prefix belongs to the namespace too.
+func checkSlug(store repositories.Store, principal *Principal, slug string) (slugCheck, error) {
+	hierarchical := strings.Contains(slug, "/")
+	namespace, err := findNamespace(store, slug)
+
+	if errors.Is(err, repositories.ErrNotFound) {
+		if hierarchical {
+			return slugOutsideNamespace, nil
+		}
+
+		return slugAllowed, nil
+	}
+
+	if err != nil {
+		return slugAllowed, err
+	}
+
+	if !principal.CanUseNamespace(namespace) {
+		return slugForbidden, nil
+	}
+
+	return slugAllowed, nil
+}
+
+// findNamespace returns the namespace with the longest prefix that slug
+// starts with, a whole segment at a time.
+func findNamespace(store repositories.Store, slug string) (models.Namespace, error) {
+	for prefix := slug; ; {
+		namespace, err := store.Namespaces().FindByPrefix(prefix)
+
+		if !errors.Is(err, repositories.ErrNotFound) {
+			return namespace, err
+		}
+
+		end := strings.LastIndex(prefix, "/")
+
+		if end < 0 {
+			return models.Namespace{}, repositories.ErrNotFound
+		}
+
+		prefix = prefix[:end]
+	}
+}
+
+// validHierarchy checks that s is made of at most maxSegments segments, none
+// of them empty, "." or "..".
+func validHierarchy(s string, maxSegments int) bool {
+	segments := strings.Split(s, "/")
+
+	if len(segments) > maxSegments {
+		return false
+	}
+
+	for _, segment := range segments {
+		if segment == "" || segment == "." || segment == ".." {
+			return false
+		}
+	}
+
+	return true
+}
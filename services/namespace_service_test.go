@@ -0,0 +1,61 @@
+package services
+
+import (
+	"testing"
+	"url-shortener/enums"
+	"url-shortener/models"
+	"url-shortener/repositories"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func createNamespace(t *testing.T, store repositories.Store, prefix string, ownerId string) {
+	namespace := models.Namespace{}
+	namespace.Prefix = prefix
+	namespace.OwnerId = ownerId
+
+	assert.Nil(t, store.Namespaces().Create(&namespace))
+}
+
+func TestChecksSlugsAgainstLongestNamespace(t *testing.T) {
+	store := repositories.NewMemoryStore()
+	createNamespace(t, store, "eng", "eng-team")
+	createNamespace(t, store, "eng/sre", "sre-team")
+
+	engTeam := &Principal{OwnerId: "eng-team"}
+	sreTeam := &Principal{OwnerId: "sre-team"}
+	admin := &Principal{OwnerId: "root", Admin: true}
+
+	tests := []struct {
+		principal *Principal
+		slug      string
+		expected  slugCheck
+	}{
+		{engTeam, "eng/oncall", slugAllowed},
+		{engTeam, "eng", slugAllowed},
+		{engTeam, "eng/sre/pager", slugForbidden},
+		{sreTeam, "eng/sre/pager", slugAllowed},
+		{sreTeam, "eng/oncall", slugForbidden},
+		{admin, "eng/sre/pager", slugAllowed},
+		{nil, "eng", slugForbidden},
+		{nil, "google", slugAllowed},
+		{nil, "ops/oncall", slugOutsideNamespace},
+	}
+
+	for _, test := range tests {
+		check, err := checkSlug(store, test.principal, test.slug)
+
+		assert.Nil(t, err)
+		assert.Equal(t, test.expected, check, test.slug)
+	}
+}
+
+func TestRejectsInvalidNamespacePrefixes(t *testing.T) {
+	subject := NamespaceService{Store: repositories.NewMemoryStore()}
+
+	for _, prefix := range []string{"", "eng/", "swagger", "a/b/c/d/e", "eng/./sre", "eng?"} {
+		result := subject.Create(models.NamespaceCreateFields{Prefix: prefix, OwnerId: "eng-team"})
+
+		assert.Equal(t, enums.NamespaceResultInvalidPrefix, result.Status, prefix)
+	}
+}
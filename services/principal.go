@@ -0,0 +1,64 @@
+package services
+
+import (
+	"strings"
+	"url-shortener/models"
+)
+
+// Principal is who a request was authenticated as.
+type Principal struct {
+	OwnerId string
+	Admin   bool
+	// Email and Groups are only known for principals authenticated with a
+	// JWT.
+	Email  string
+	Groups []string
+}
+
+// CanModify reports whether principal may update or delete shortUrl. A nil
+// principal is an anonymous request, which never may. Admins may modify any
+// short URL, and everyone else only the short URLs they own. Short URLs
+// created without an API key have no owner, so only admins may modify them.
+func (principal *Principal) CanModify(shortUrl models.ShortUrl) bool {
+	if principal == nil {
+		return false
+	}
+
+	if principal.Admin {
+		return true
+	}
+
+	return shortUrl.OwnerId != "" && shortUrl.OwnerId == principal.OwnerId
+}
+
+// CanUseNamespace reports whether principal may create short URLs in
+// namespace, or change its owner or delete it. Only its owner and admins may,
+// so anonymous requests never can.
+func (principal *Principal) CanUseNamespace(namespace models.Namespace) bool {
+	if principal == nil {
+		return false
+	}
+
+	return principal.Admin || namespace.OwnerId == principal.OwnerId
+}
+
+// Authenticator returns the principal a bearer token belongs to.
+type Authenticator interface {
+	Authenticate(token string) (*Principal, error)
+}
+
+// BearerAuthenticator accepts both API keys and, if Jwt is set, JWTs. API
+// keys are recognised by their prefix, so tokens are only ever checked by one
+// of them.
+type BearerAuthenticator struct {
+	ApiKeys *ApiKeyService
+	Jwt     *JwtAuthenticator
+}
+
+func (a *BearerAuthenticator) Authenticate(token string) (*Principal, error) {
+	if strings.HasPrefix(token, apiKeyPrefix) || a.Jwt == nil {
+		return a.ApiKeys.Authenticate(token)
+	}
+
+	return a.Jwt.Authenticate(token)
+}
@@ -0,0 +1,32 @@
+package services
+
+import (
+	"testing"
+	"url-shortener/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrincipalCanModify(t *testing.T) {
+	type test struct {
+		principal *Principal
+		ownerId   string
+		expected  bool
+	}
+
+	tests := []test{
+		{principal: nil, ownerId: "", expected: false},
+		{principal: nil, ownerId: "alice", expected: false},
+		{principal: &Principal{OwnerId: "alice"}, ownerId: "alice", expected: true},
+		{principal: &Principal{OwnerId: "bob"}, ownerId: "alice", expected: false},
+		{principal: &Principal{OwnerId: "bob"}, ownerId: "", expected: false},
+		{principal: &Principal{OwnerId: "bob", Admin: true}, ownerId: "alice", expected: true},
+		{principal: &Principal{OwnerId: "bob", Admin: true}, ownerId: "", expected: true},
+	}
+
+	for _, tc := range tests {
+		shortUrl := models.ShortUrl{OwnerId: tc.ownerId}
+
+		assert.Equal(t, tc.expected, tc.principal.CanModify(shortUrl))
+	}
+}
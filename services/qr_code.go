@@ -0,0 +1,130 @@
+package services
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"url-shortener/enums"
+
+	qrcode "github.com/skip2/go-qrcode"
+)
+
+// QrCodeOptions is how a QR code is drawn.
+type QrCodeOptions struct {
+	Format          enums.QrCodeFormat
+	ErrorCorrection enums.QrCodeErrorCorrection
+	// Size is the width and height of the image in pixels. PNG modules are
+	// a whole number of pixels wide, so a PNG is padded out to Size, or
+	// made larger than it when that's less than a pixel per module.
+	Size int
+	// Margin is the width of the quiet zone around the code, in modules.
+	// Scanners expect at least 4.
+	Margin int
+}
+
+// RenderQrCode draws a QR code of content as a PNG or SVG image.
+func RenderQrCode(content string, options QrCodeOptions) ([]byte, error) {
+	level := map[enums.QrCodeErrorCorrection]qrcode.RecoveryLevel{
+		enums.QrCodeErrorCorrectionLow:      qrcode.Low,
+		enums.QrCodeErrorCorrectionMedium:   qrcode.Medium,
+		enums.QrCodeErrorCorrectionQuartile: qrcode.High,
+		enums.QrCodeErrorCorrectionHigh:     qrcode.Highest,
+	}[options.ErrorCorrection]
+
+	code, err := qrcode.New(content, level)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// The library's border is always 4 modules wide, so the margin is drawn
+	// here instead.
+	code.DisableBorder = true
+	modules := code.Bitmap()
+
+	if options.Format == enums.QrCodeFormatSvg {
+		return renderQrCodeSvg(modules, options), nil
+	}
+
+	return renderQrCodePng(modules, options)
+}
+
+func renderQrCodePng(modules [][]bool, options QrCodeOptions) ([]byte, error) {
+	width := len(modules) + 2*options.Margin
+	scale := options.Size / width
+
+	if scale < 1 {
+		scale = 1
+	}
+
+	side := options.Size
+
+	if side < width*scale {
+		side = width * scale
+	}
+
+	// Whatever doesn't divide into modules is split around the code, so that
+	// it stays centered.
+	offset := (side-width*scale)/2 + options.Margin*scale
+
+	img := image.NewPaletted(image.Rect(0, 0, side, side), color.Palette{color.White, color.Black})
+
+	for y, row := range modules {
+		for x, dark := range row {
+			if !dark {
+				continue
+			}
+
+			for py := 0; py < scale; py++ {
+				for px := 0; px < scale; px++ {
+					img.SetColorIndex(offset+x*scale+px, offset+y*scale+py, 1)
+				}
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func renderQrCodeSvg(modules [][]bool, options QrCodeOptions) []byte {
+	width := len(modules) + 2*options.Margin
+
+	var buf bytes.Buffer
+
+	fmt.Fprintf(
+		&buf,
+		`<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" shape-rendering="crispEdges">`,
+		options.Size, options.Size, width, width,
+	)
+	fmt.Fprintf(&buf, `<rect width="%d" height="%d" fill="#fff"/><path fill="#000" d="`, width, width)
+
+	// Each run of dark modules in a row is one rectangle, which keeps the
+	// path short.
+	for y, row := range modules {
+		for x := 0; x < len(row); x++ {
+			if !row[x] {
+				continue
+			}
+
+			start := x
+
+			for x < len(row) && row[x] {
+				x++
+			}
+
+			fmt.Fprintf(&buf, "M%d %dh%dv1h-%dz", start+options.Margin, y+options.Margin, x-start, x-start)
+		}
+	}
+
+	buf.WriteString(`"/></svg>`)
+
+	return buf.Bytes()
+}
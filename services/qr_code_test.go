@@ -0,0 +1,90 @@
+package services
+
+import (
+	"bytes"
+	"fmt"
+	"image/png"
+	"strings"
+	"testing"
+	"url-shortener/enums"
+
+	qrcode "github.com/skip2/go-qrcode"
+	"github.com/stretchr/testify/assert"
+)
+
+func qrCodeModules(t *testing.T, content string) int {
+	code, err := qrcode.New(content, qrcode.Medium)
+	assert.Nil(t, err)
+
+	code.DisableBorder = true
+
+	return len(code.Bitmap())
+}
+
+func TestRendersQrCodeAsPng(t *testing.T) {
+	content := "http://localhost:8080/cloudflare"
+	modules := qrCodeModules(t, content)
+
+	b, err := RenderQrCode(content, QrCodeOptions{
+		Format:          enums.QrCodeFormatPng,
+		ErrorCorrection: enums.QrCodeErrorCorrectionMedium,
+		Size:            256,
+		Margin:          4,
+	})
+	assert.Nil(t, err)
+
+	img, err := png.Decode(bytes.NewReader(b))
+	assert.Nil(t, err)
+	assert.Equal(t, 256, img.Bounds().Dx())
+	assert.Equal(t, 256, img.Bounds().Dy())
+
+	// The top-left finder pattern starts right after the margin, with the
+	// left over pixels split on either side.
+	scale := 256 / (modules + 8)
+	offset := (256-(modules+8)*scale)/2 + 4*scale
+
+	dark := func(x, y int) bool {
+		r, _, _, _ := img.At(x, y).RGBA()
+		return r == 0
+	}
+
+	assert.False(t, dark(offset-1, offset-1))
+	assert.True(t, dark(offset, offset))
+	assert.True(t, dark(offset+scale-1, offset+scale-1))
+	assert.False(t, dark(0, 0))
+}
+
+func TestGrowsPngThatIsTooSmallForItsModules(t *testing.T) {
+	content := strings.Repeat("a", 500)
+	modules := qrCodeModules(t, content)
+
+	b, err := RenderQrCode(content, QrCodeOptions{ErrorCorrection: enums.QrCodeErrorCorrectionMedium, Size: 64, Margin: 2})
+	assert.Nil(t, err)
+
+	img, err := png.Decode(bytes.NewReader(b))
+	assert.Nil(t, err)
+	assert.Equal(t, modules+4, img.Bounds().Dx())
+}
+
+func TestRendersQrCodeAsSvg(t *testing.T) {
+	content := "http://localhost:8080/cloudflare"
+	modules := qrCodeModules(t, content)
+
+	b, err := RenderQrCode(content, QrCodeOptions{
+		Format:          enums.QrCodeFormatSvg,
+		ErrorCorrection: enums.QrCodeErrorCorrectionMedium,
+		Size:            300,
+		Margin:          0,
+	})
+	assert.Nil(t, err)
+
+	svg := string(b)
+
+	assert.True(t, strings.HasPrefix(svg, fmt.Sprintf(
+		`<svg xmlns="http://www.w3.org/2000/svg" width="300" height="300" viewBox="0 0 %d %d"`,
+		modules, modules,
+	)))
+	// The first row starts with the 7 modules of the top-left finder pattern.
+	assert.Contains(t, svg, `d="M0 0h7v1h-7z`)
+	assert.True(t, strings.HasSuffix(svg, `"/></svg>`))
+}
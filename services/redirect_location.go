@@ -0,0 +1,151 @@
+package services
+
+import (
+	"net/url"
+	"path"
+	"strings"
+	"url-shortener/models"
+)
+
+// RedirectLocation is where a request for shortUrl redirects to. rest is the
+// part of the request's path after the slug, which is only passed through
+// if the short URL allows it, and query is the request's query string.
+//
+// The long URL of a template short URL is filled in from rest and query
+// first (see fillTemplate), returning ErrTemplateMismatch if it can't be. The
+// short URL's UTM parameters replace any the long URL has, as if they were
+// part of it, so the request's query string can still override them when
+// passed through.
+func RedirectLocation(shortUrl models.ShortUrl, rest string, query url.Values) (string, error) {
+	longUrl := shortUrl.LongUrl
+
+	// Templates use up the path, so there's none left to pass through.
+	if IsTemplate(shortUrl.ShortUrlRedirectFields) {
+		var err error
+
+		if longUrl, query, err = fillTemplate(longUrl, rest, query); err != nil {
+			return "", err
+		}
+
+		rest = ""
+	}
+
+	passPath := rest != "" && shortUrl.PathPassthrough != nil && *shortUrl.PathPassthrough
+	passQuery := len(query) > 0 && shortUrl.QueryPassthrough != nil && *shortUrl.QueryPassthrough != "none"
+	tagged := shortUrl.ShortUrlUtmFields != models.ShortUrlUtmFields{}
+
+	if !passPath && !passQuery && !tagged {
+		return longUrl, nil
+	}
+
+	location, err := url.Parse(longUrl)
+
+	if err != nil {
+		return "", err
+	}
+
+	if passPath {
+		// Cleaning rest keeps ".." from climbing out of the long URL's path.
+		cleaned := path.Clean("/" + rest)
+
+		if strings.HasSuffix(rest, "/") && cleaned != "/" {
+			cleaned += "/"
+		}
+
+		location.Path = strings.TrimSuffix(location.Path, "/") + cleaned
+		location.RawPath = ""
+	}
+
+	if tagged {
+		location.RawQuery = tag(location.RawQuery, shortUrl.ShortUrlUtmFields)
+	}
+
+	if passQuery {
+		location.RawQuery = mergeQuery(location.Query(), query, *shortUrl.QueryPassthrough).Encode()
+	}
+
+	return location.String(), nil
+}
+
+// UtmCampaign is the campaign a redirect to location is counted towards.
+func UtmCampaign(location string) string {
+	parsed, err := url.Parse(location)
+
+	if err != nil {
+		return ""
+	}
+
+	return parsed.Query().Get("utm_campaign")
+}
+
+// UtmSource is the source a request with query that was redirected to
+// location is counted towards: the request's own utm_source, so that links
+// shared with one appended (like QR codes) can be told apart, or else the
+// location's.
+func UtmSource(query url.Values, location string) string {
+	if source := query.Get("utm_source"); source != "" {
+		return source
+	}
+
+	parsed, err := url.Parse(location)
+
+	if err != nil {
+		return ""
+	}
+
+	return parsed.Query().Get("utm_source")
+}
+
+// tag appends utm's parameters to rawQuery, dropping any that rawQuery
+// already has. The rest of rawQuery is left exactly as it was, including the
+// order of its parameters and any without a value.
+func tag(rawQuery string, utm models.ShortUrlUtmFields) string {
+	parameters := []struct{ key, value string }{
+		{"utm_campaign", utm.UtmCampaign},
+		{"utm_medium", utm.UtmMedium},
+		{"utm_source", utm.UtmSource},
+	}
+
+	replaced := map[string]bool{}
+	var tags []string
+
+	for _, parameter := range parameters {
+		if parameter.value != "" {
+			replaced[parameter.key] = true
+			tags = append(tags, parameter.key+"="+url.QueryEscape(parameter.value))
+		}
+	}
+
+	var pairs []string
+
+	for _, pair := range strings.Split(rawQuery, "&") {
+		key, _, _ := strings.Cut(pair, "=")
+
+		if unescaped, err := url.QueryUnescape(key); err == nil {
+			key = unescaped
+		}
+
+		if pair != "" && !replaced[key] {
+			pairs = append(pairs, pair)
+		}
+	}
+
+	return strings.Join(append(pairs, tags...), "&")
+}
+
+func mergeQuery(destination url.Values, incoming url.Values, policy string) url.Values {
+	for key, values := range incoming {
+		switch policy {
+		case "prefer_incoming":
+			destination[key] = values
+		case "prefer_destination":
+			if _, ok := destination[key]; !ok {
+				destination[key] = values
+			}
+		case "append":
+			destination[key] = append(destination[key], values...)
+		}
+	}
+
+	return destination
+}
@@ -0,0 +1,141 @@
+package services
+
+import (
+	"net/url"
+	"testing"
+	"url-shortener/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func shortUrlTo(longUrl string, queryPassthrough string, pathPassthrough bool) models.ShortUrl {
+	shortUrl := models.ShortUrl{}
+	shortUrl.LongUrl = longUrl
+	shortUrl.PathPassthrough = &pathPassthrough
+
+	if queryPassthrough != "" {
+		shortUrl.QueryPassthrough = &queryPassthrough
+	}
+
+	return shortUrl
+}
+
+func TestRedirectLocationIgnoresRequestByDefault(t *testing.T) {
+	shortUrl := models.ShortUrl{}
+	shortUrl.LongUrl = "https://docs.example.com/v2?lang=en"
+
+	location, err := RedirectLocation(shortUrl, "/guide", url.Values{"lang": {"fr"}})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "https://docs.example.com/v2?lang=en", location)
+}
+
+func TestRedirectLocationMergesQuery(t *testing.T) {
+	incoming := url.Values{"lang": {"fr"}, "utm_source": {"slack"}}
+
+	tests := map[string]string{
+		"none":               "https://docs.example.com/v2?lang=en",
+		"prefer_incoming":    "https://docs.example.com/v2?lang=fr&utm_source=slack",
+		"prefer_destination": "https://docs.example.com/v2?lang=en&utm_source=slack",
+		"append":             "https://docs.example.com/v2?lang=en&lang=fr&utm_source=slack",
+	}
+
+	for policy, expected := range tests {
+		location, err := RedirectLocation(shortUrlTo("https://docs.example.com/v2?lang=en", policy, false), "", incoming)
+
+		assert.Nil(t, err)
+		assert.Equal(t, expected, location, policy)
+	}
+}
+
+func TestRedirectLocationAppendsPath(t *testing.T) {
+	tests := []struct {
+		longUrl  string
+		rest     string
+		expected string
+	}{
+		{"https://docs.example.com/v2", "/guide/intro", "https://docs.example.com/v2/guide/intro"},
+		{"https://docs.example.com/v2/", "/guide/", "https://docs.example.com/v2/guide/"},
+		{"https://docs.example.com", "/guide", "https://docs.example.com/guide"},
+		{"https://docs.example.com/v2?lang=en#top", "/guide", "https://docs.example.com/v2/guide?lang=en#top"},
+		{"https://docs.example.com/v2", "/../../admin", "https://docs.example.com/v2/admin"},
+		{"https://docs.example.com/v2", "/a b", "https://docs.example.com/v2/a%20b"},
+	}
+
+	for _, test := range tests {
+		location, err := RedirectLocation(shortUrlTo(test.longUrl, "", true), test.rest, nil)
+
+		assert.Nil(t, err)
+		assert.Equal(t, test.expected, location)
+	}
+}
+
+func TestRedirectLocationTagsLongUrl(t *testing.T) {
+	shortUrl := shortUrlTo("https://www.example.com/pricing?utm_source=old&plan=pro", "prefer_incoming", false)
+	shortUrl.UtmSource = "newsletter"
+	shortUrl.UtmCampaign = "spring"
+
+	location, err := RedirectLocation(shortUrl, "", nil)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "https://www.example.com/pricing?plan=pro&utm_campaign=spring&utm_source=newsletter", location)
+	assert.Equal(t, "spring", UtmCampaign(location))
+
+	// Passing the query through can still override the tags.
+	location, err = RedirectLocation(shortUrl, "", url.Values{"utm_campaign": {"summer"}})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "https://www.example.com/pricing?plan=pro&utm_campaign=summer&utm_source=newsletter", location)
+	assert.Equal(t, "summer", UtmCampaign(location))
+}
+
+func TestRedirectLocationTagsLongUrlInPlace(t *testing.T) {
+	tests := []struct {
+		longUrl  string
+		expected string
+	}{
+		{"https://www.example.com/?b=2&a=1&flag", "https://www.example.com/?b=2&a=1&flag&utm_source=newsletter"},
+		{"https://www.example.com/?utm_source=old&b=2&utm_medium=email", "https://www.example.com/?b=2&utm_medium=email&utm_source=newsletter"},
+		{"https://www.example.com/?utm%5Fsource=old&q=a+b%20c", "https://www.example.com/?q=a+b%20c&utm_source=newsletter"},
+		{"https://www.example.com/#top", "https://www.example.com/?utm_source=newsletter#top"},
+	}
+
+	for _, test := range tests {
+		shortUrl := shortUrlTo(test.longUrl, "", false)
+		shortUrl.UtmSource = "newsletter"
+
+		location, err := RedirectLocation(shortUrl, "", nil)
+
+		assert.Nil(t, err)
+		assert.Equal(t, test.expected, location)
+	}
+}
+
+func TestRedirectLocationFillsTemplate(t *testing.T) {
+	linkType := "template"
+	shortUrl := shortUrlTo("https://jira.corp/browse/{ticket}", "prefer_incoming", true)
+	shortUrl.LinkType = &linkType
+
+	location, err := RedirectLocation(shortUrl, "/ABC-123", url.Values{"focus": {"comments"}})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "https://jira.corp/browse/ABC-123?focus=comments", location)
+
+	// The query parameters that fill placeholders aren't passed through.
+	location, err = RedirectLocation(shortUrl, "", url.Values{"ticket": {"ABC-123"}})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "https://jira.corp/browse/ABC-123", location)
+
+	_, err = RedirectLocation(shortUrl, "", nil)
+
+	assert.ErrorIs(t, err, ErrTemplateMismatch)
+}
+
+func TestUtmSourcePrefersRequest(t *testing.T) {
+	location := "https://www.cloudflare.com?utm_source=newsletter"
+
+	assert.Equal(t, "qr", UtmSource(url.Values{"utm_source": {"qr"}}, location))
+	assert.Equal(t, "newsletter", UtmSource(url.Values{}, location))
+	assert.Equal(t, "", UtmSource(url.Values{}, "https://www.cloudflare.com"))
+}
@@ -0,0 +1,76 @@
+package services
+
+import (
+	"errors"
+	"url-shortener/enums"
+	"url-shortener/models"
+	"url-shortener/repositories"
+)
+
+type RestoreShortUrlService struct {
+	Store repositories.Store
+}
+
+type RestoreResult struct {
+	Status enums.RestoreStatus
+	Record *models.ShortUrl
+	Error  error
+}
+
+// Restore takes the short URL with slug out of the trash on behalf of
+// principal, who must be allowed to modify it. It keeps the clicks it had
+// when it was deleted. The restoration is recorded in the audit log, along
+// with origin.
+func (s *RestoreShortUrlService) Restore(principal *Principal, origin Origin, slug string) RestoreResult {
+	shortUrl, err := s.Store.ShortUrls().FindDeletedBySlug(slug)
+
+	if errors.Is(err, repositories.ErrNotFound) {
+		return RestoreResult{
+			Status: enums.RestoreResultNotFound,
+		}
+	}
+
+	if err != nil {
+		return RestoreResult{
+			Status: enums.RestoreResultUnknownError,
+			Error:  err,
+		}
+	}
+
+	if !principal.CanModify(shortUrl) {
+		return RestoreResult{
+			Status: enums.RestoreResultForbidden,
+		}
+	}
+
+	before := shortUrl
+
+	err = s.Store.Transaction(func(tx repositories.Store) error {
+		if err := tx.ShortUrls().Restore(&shortUrl); err != nil {
+			return err
+		}
+
+		return recordAudit(tx, models.AuditActionRestore, principal, origin, &before, &shortUrl)
+	})
+
+	switch {
+	case err == nil:
+		return RestoreResult{
+			Status: enums.RestoreResultSuccessful,
+			Record: &shortUrl,
+		}
+	case errors.Is(err, repositories.ErrNotFound):
+		return RestoreResult{
+			Status: enums.RestoreResultNotFound,
+		}
+	case errors.Is(err, repositories.ErrDuplicateLongUrl):
+		return RestoreResult{
+			Status: enums.RestoreResultDuplicateLongUrl,
+		}
+	}
+
+	return RestoreResult{
+		Status: enums.RestoreResultUnknownError,
+		Error:  err,
+	}
+}
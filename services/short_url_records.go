@@ -0,0 +1,386 @@
+package services
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+	"url-shortener/enums"
+	"url-shortener/models"
+
+	"golang.org/x/exp/slices"
+	"gopkg.in/guregu/null.v4"
+)
+
+// ShortUrlRecord is a short URL as it appears in an export or an import.
+type ShortUrlRecord struct {
+	Slug      string    `json:"slug"`
+	LongUrl   string    `json:"long_url"`
+	ExpiresOn null.Time `json:"expires_on"`
+	CreatedAt time.Time `json:"created_at"`
+	OwnerId   string    `json:"owner_id"`
+	Clicks    int64     `json:"clicks"`
+
+	RedirectType *int    `json:"redirect_type,omitempty"`
+	CacheControl *string `json:"cache_control,omitempty"`
+	CacheMaxAge  *int    `json:"cache_max_age,omitempty"`
+
+	QueryPassthrough *string `json:"query_passthrough,omitempty"`
+	PathPassthrough  *bool   `json:"path_passthrough,omitempty"`
+
+	models.ShortUrlUtmFields
+
+	LinkType    *string `json:"link_type,omitempty"`
+	FallbackUrl *string `json:"fallback_url,omitempty"`
+}
+
+var shortUrlRecordCsvHeader = []string{
+	"slug", "long_url", "expires_on", "created_at", "owner_id", "clicks",
+	"redirect_type", "cache_control", "cache_max_age",
+	"query_passthrough", "path_passthrough",
+	"utm_source", "utm_medium", "utm_campaign",
+	"link_type", "fallback_url",
+}
+
+// earlierCsvColumns are how many columns exports had before short URLs had
+// redirect settings, passthrough, UTM fields, templates, and then fallback
+// URLs. Those exports can still be imported, as columns are only ever added
+// at the end.
+var earlierCsvColumns = []int{6, 9, 11, 14, 15}
+
+type ShortUrlRecordWriter interface {
+	Write(record ShortUrlRecord) error
+	// Flush sends everything written so far on to the underlying writer
+	// (and the client, if it's an http.ResponseWriter).
+	Flush() error
+}
+
+// ShortUrlRecordReader reads records one at a time, returning io.EOF once
+// there are none left.
+type ShortUrlRecordReader interface {
+	// Read returns the next record and the line it starts on.
+	Read() (ShortUrlRecord, int, error)
+}
+
+func NewShortUrlRecordWriter(w io.Writer, format enums.RecordFormat) ShortUrlRecordWriter {
+	if format == enums.RecordFormatJsonl {
+		buffered := bufio.NewWriter(w)
+
+		return &jsonlRecordWriter{
+			underlying: w,
+			buffered:   buffered,
+			encoder:    json.NewEncoder(buffered),
+		}
+	}
+
+	return &csvRecordWriter{
+		underlying: w,
+		writer:     csv.NewWriter(w),
+	}
+}
+
+func NewShortUrlRecordReader(r io.Reader, format enums.RecordFormat) ShortUrlRecordReader {
+	if format == enums.RecordFormatJsonl {
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+		return &jsonlRecordReader{scanner: scanner}
+	}
+
+	// Every record must have as many fields as the header does.
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = 0
+
+	return &csvRecordReader{reader: reader}
+}
+
+type csvRecordWriter struct {
+	underlying    io.Writer
+	writer        *csv.Writer
+	headerWritten bool
+}
+
+func (w *csvRecordWriter) Write(record ShortUrlRecord) error {
+	if !w.headerWritten {
+		w.headerWritten = true
+
+		if err := w.writer.Write(shortUrlRecordCsvHeader); err != nil {
+			return err
+		}
+	}
+
+	expiresOn := ""
+
+	if record.ExpiresOn.Valid {
+		expiresOn = record.ExpiresOn.Time.Format(time.RFC3339Nano)
+	}
+
+	return w.writer.Write([]string{
+		record.Slug,
+		record.LongUrl,
+		expiresOn,
+		record.CreatedAt.Format(time.RFC3339Nano),
+		record.OwnerId,
+		strconv.FormatInt(record.Clicks, 10),
+		formatOptionalInt(record.RedirectType),
+		formatOptionalString(record.CacheControl),
+		formatOptionalInt(record.CacheMaxAge),
+		formatOptionalString(record.QueryPassthrough),
+		formatOptionalBool(record.PathPassthrough),
+		record.UtmSource,
+		record.UtmMedium,
+		record.UtmCampaign,
+		formatOptionalString(record.LinkType),
+		formatOptionalString(record.FallbackUrl),
+	})
+}
+
+func (w *csvRecordWriter) Flush() error {
+	// An empty export still gets a header.
+	if !w.headerWritten {
+		w.headerWritten = true
+
+		if err := w.writer.Write(shortUrlRecordCsvHeader); err != nil {
+			return err
+		}
+	}
+
+	w.writer.Flush()
+
+	if err := w.writer.Error(); err != nil {
+		return err
+	}
+
+	flushHttp(w.underlying)
+
+	return nil
+}
+
+type jsonlRecordWriter struct {
+	underlying io.Writer
+	buffered   *bufio.Writer
+	encoder    *json.Encoder
+}
+
+func (w *jsonlRecordWriter) Write(record ShortUrlRecord) error {
+	// Encode terminates each record with a newline.
+	return w.encoder.Encode(record)
+}
+
+func (w *jsonlRecordWriter) Flush() error {
+	if err := w.buffered.Flush(); err != nil {
+		return err
+	}
+
+	flushHttp(w.underlying)
+
+	return nil
+}
+
+func flushHttp(w io.Writer) {
+	if flusher, ok := w.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+type csvRecordReader struct {
+	reader     *csv.Reader
+	readHeader bool
+}
+
+func (r *csvRecordReader) Read() (ShortUrlRecord, int, error) {
+	if !r.readHeader {
+		r.readHeader = true
+
+		header, err := r.reader.Read()
+
+		if err != nil {
+			return ShortUrlRecord{}, 1, err
+		}
+
+		if !validCsvHeader(header) {
+			return ShortUrlRecord{}, 1, fmt.Errorf("expected a header of %v", shortUrlRecordCsvHeader)
+		}
+	}
+
+	fields, err := r.reader.Read()
+
+	if err != nil {
+		var parseErr *csv.ParseError
+
+		if errors.As(err, &parseErr) {
+			return ShortUrlRecord{}, parseErr.StartLine, err
+		}
+
+		return ShortUrlRecord{}, 0, err
+	}
+
+	line, _ := r.reader.FieldPos(0)
+
+	record := ShortUrlRecord{
+		Slug:    fields[0],
+		LongUrl: fields[1],
+		OwnerId: fields[4],
+	}
+
+	if fields[2] != "" {
+		expiresOn, err := time.Parse(time.RFC3339Nano, fields[2])
+
+		if err != nil {
+			return record, line, fmt.Errorf("expires_on: %w", err)
+		}
+
+		record.ExpiresOn = null.TimeFrom(expiresOn)
+	}
+
+	if fields[3] != "" {
+		record.CreatedAt, err = time.Parse(time.RFC3339Nano, fields[3])
+
+		if err != nil {
+			return record, line, fmt.Errorf("created_at: %w", err)
+		}
+	}
+
+	if fields[5] != "" {
+		record.Clicks, err = strconv.ParseInt(fields[5], 10, 64)
+
+		if err != nil {
+			return record, line, fmt.Errorf("clicks: %w", err)
+		}
+	}
+
+	// Columns that an earlier export didn't have are left empty.
+	fields = append(fields, make([]string, len(shortUrlRecordCsvHeader)-len(fields))...)
+
+	if record.RedirectType, err = parseOptionalInt(fields[6]); err != nil {
+		return record, line, fmt.Errorf("redirect_type: %w", err)
+	}
+
+	record.CacheControl = parseOptionalString(fields[7])
+
+	if record.CacheMaxAge, err = parseOptionalInt(fields[8]); err != nil {
+		return record, line, fmt.Errorf("cache_max_age: %w", err)
+	}
+
+	record.QueryPassthrough = parseOptionalString(fields[9])
+
+	if record.PathPassthrough, err = parseOptionalBool(fields[10]); err != nil {
+		return record, line, fmt.Errorf("path_passthrough: %w", err)
+	}
+
+	record.UtmSource = fields[11]
+	record.UtmMedium = fields[12]
+	record.UtmCampaign = fields[13]
+	record.LinkType = parseOptionalString(fields[14])
+	record.FallbackUrl = parseOptionalString(fields[15])
+
+	return record, line, nil
+}
+
+func validCsvHeader(header []string) bool {
+	if len(header) != len(shortUrlRecordCsvHeader) && !slices.Contains(earlierCsvColumns, len(header)) {
+		return false
+	}
+
+	for i, column := range header {
+		if column != shortUrlRecordCsvHeader[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// formatOptionalInt writes a setting that a short URL doesn't have as an
+// empty CSV field, which parseOptionalInt reads back.
+func formatOptionalInt(value *int) string {
+	if value == nil {
+		return ""
+	}
+
+	return strconv.Itoa(*value)
+}
+
+func formatOptionalString(value *string) string {
+	if value == nil {
+		return ""
+	}
+
+	return *value
+}
+
+func formatOptionalBool(value *bool) string {
+	if value == nil {
+		return ""
+	}
+
+	return strconv.FormatBool(*value)
+}
+
+func parseOptionalInt(field string) (*int, error) {
+	if field == "" {
+		return nil, nil
+	}
+
+	value, err := strconv.Atoi(field)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &value, nil
+}
+
+func parseOptionalBool(field string) (*bool, error) {
+	if field == "" {
+		return nil, nil
+	}
+
+	value, err := strconv.ParseBool(field)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &value, nil
+}
+
+func parseOptionalString(field string) *string {
+	if field == "" {
+		return nil
+	}
+
+	return &field
+}
+
+type jsonlRecordReader struct {
+	scanner *bufio.Scanner
+	line    int
+}
+
+func (r *jsonlRecordReader) Read() (ShortUrlRecord, int, error) {
+	for r.scanner.Scan() {
+		r.line++
+
+		// Blank lines (such as a trailing one) aren't records.
+		if len(r.scanner.Bytes()) == 0 {
+			continue
+		}
+
+		var record ShortUrlRecord
+		err := json.Unmarshal(r.scanner.Bytes(), &record)
+
+		return record, r.line, err
+	}
+
+	if err := r.scanner.Err(); err != nil {
+		return ShortUrlRecord{}, r.line + 1, err
+	}
+
+	return ShortUrlRecord{}, r.line + 1, io.EOF
+}
@@ -0,0 +1,134 @@
+package services
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"time"
+	"url-shortener/enums"
+	"url-shortener/models"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/guregu/null.v4"
+)
+
+func TestShortUrlRecordsRoundTrip(t *testing.T) {
+	redirectType, cacheControl, cacheMaxAge := 307, "public", 0
+	queryPassthrough, pathPassthrough := "append", false
+	linkType, fallbackUrl := "template", "https://www.google.com/expired"
+
+	records := []ShortUrlRecord{
+		{
+			Slug:      "google",
+			LongUrl:   "https://www.google.com/search?q={query},b",
+			ExpiresOn: null.TimeFrom(time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)),
+			CreatedAt: time.Date(2022, 5, 11, 11, 30, 0, 123000000, time.UTC),
+			OwnerId:   "alice",
+			Clicks:    42,
+
+			RedirectType: &redirectType,
+			CacheControl: &cacheControl,
+			CacheMaxAge:  &cacheMaxAge,
+
+			QueryPassthrough: &queryPassthrough,
+			PathPassthrough:  &pathPassthrough,
+
+			ShortUrlUtmFields: models.ShortUrlUtmFields{
+				UtmSource:   "newsletter",
+				UtmCampaign: "spring, 2022",
+			},
+
+			LinkType:    &linkType,
+			FallbackUrl: &fallbackUrl,
+		},
+		{
+			Slug:      "cloudflare",
+			LongUrl:   "https://www.cloudflare.com",
+			CreatedAt: time.Date(2022, 5, 12, 0, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for _, format := range []enums.RecordFormat{enums.RecordFormatCsv, enums.RecordFormatJsonl} {
+		var buffer bytes.Buffer
+
+		writer := NewShortUrlRecordWriter(&buffer, format)
+
+		for _, record := range records {
+			assert.Nil(t, writer.Write(record))
+		}
+
+		assert.Nil(t, writer.Flush())
+
+		reader := NewShortUrlRecordReader(&buffer, format)
+
+		for i, expected := range records {
+			record, line, err := reader.Read()
+
+			assert.Nil(t, err)
+			assert.True(t, expected.CreatedAt.Equal(record.CreatedAt))
+			assert.Equal(t, expected.ExpiresOn.Valid, record.ExpiresOn.Valid)
+			assert.True(t, expected.ExpiresOn.Time.Equal(record.ExpiresOn.Time))
+
+			record.CreatedAt, record.ExpiresOn = expected.CreatedAt, expected.ExpiresOn
+			assert.Equal(t, expected, record)
+
+			if format == enums.RecordFormatCsv {
+				// The header is on line 1.
+				assert.Equal(t, i+2, line)
+			} else {
+				assert.Equal(t, i+1, line)
+			}
+		}
+
+		_, _, err := reader.Read()
+		assert.Equal(t, io.EOF, err)
+	}
+}
+
+func TestCsvRecordReaderRejectsUnexpectedHeader(t *testing.T) {
+	reader := NewShortUrlRecordReader(
+		strings.NewReader("slug,url,expires_on,created_at,owner_id,clicks\n"),
+		enums.RecordFormatCsv,
+	)
+
+	_, line, err := reader.Read()
+
+	assert.NotNil(t, err)
+	assert.Equal(t, 1, line)
+}
+
+func TestCsvRecordWriterWritesHeaderForEmptyExport(t *testing.T) {
+	var buffer bytes.Buffer
+
+	writer := NewShortUrlRecordWriter(&buffer, enums.RecordFormatCsv)
+
+	assert.Nil(t, writer.Flush())
+	assert.Equal(t, "slug,long_url,expires_on,created_at,owner_id,clicks,redirect_type,cache_control,cache_max_age,query_passthrough,path_passthrough,utm_source,utm_medium,utm_campaign,link_type,fallback_url\n", buffer.String())
+}
+
+func TestCsvRecordReaderReadsExportWithoutRedirectSettings(t *testing.T) {
+	reader := NewShortUrlRecordReader(
+		strings.NewReader("slug,long_url,expires_on,created_at,owner_id,clicks\ngoogle,https://www.google.com,,,,3\n"),
+		enums.RecordFormatCsv,
+	)
+
+	record, line, err := reader.Read()
+
+	assert.Nil(t, err)
+	assert.Equal(t, 2, line)
+	assert.Equal(t, ShortUrlRecord{Slug: "google", LongUrl: "https://www.google.com", Clicks: 3}, record)
+}
+
+func TestCsvRecordReaderReadsUntaggedExport(t *testing.T) {
+	reader := NewShortUrlRecordReader(
+		strings.NewReader("slug,long_url,expires_on,created_at,owner_id,clicks,redirect_type,cache_control,cache_max_age,query_passthrough,path_passthrough\ngoogle,https://www.google.com,,,,3,302,,,,\n"),
+		enums.RecordFormatCsv,
+	)
+
+	record, _, err := reader.Read()
+
+	assert.Nil(t, err)
+	assert.Equal(t, 302, *record.RedirectType)
+	assert.Equal(t, models.ShortUrlUtmFields{}, record.ShortUrlUtmFields)
+}
@@ -1,9 +1,172 @@
 package services
 
-import gonanoid "github.com/matoous/go-nanoid"
+import (
+	"strings"
+	"url-shortener/enums"
 
-const alphabet = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+	gonanoid "github.com/matoous/go-nanoid"
+)
 
-func GenerateSlug() (string, error) {
-	return gonanoid.Generate(alphabet, 8)
+const (
+	alphabet          = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+	lowercaseAlphabet = "0123456789abcdefghijklmnopqrstuvwxyz"
+	// crockfordAlphabet leaves out i, l, o and u, so that slugs can't be
+	// misread as 1, 0 or each other, or spell most words.
+	crockfordAlphabet = "0123456789abcdefghjkmnpqrstvwxyz"
+
+	consonants = "bdfghjklmnprstvz"
+	vowels     = "aeiou"
+
+	defaultGeneratedSlugLength = 8
+)
+
+// SlugGenerator generates the slugs of short URLs that aren't given one.
+// Generated slugs can collide with existing ones, so they're retried, and
+// lengthened if they keep colliding.
+type SlugGenerator interface {
+	// Generate returns a slug of length characters. id is the id of the
+	// short URL, which is only known to generators that NeedsId.
+	Generate(id int64, length int) (string, error)
+	// Length is how long slugs are before they've been lengthened.
+	Length() int
+	// NeedsId reports whether slugs are derived from ids, in which case
+	// short URLs are saved before their slugs are generated.
+	NeedsId() bool
+}
+
+// DefaultSlugGenerator generates 8 character nanoids, which are lowercase if
+// the policy lowercases slugs.
+func DefaultSlugGenerator(policy SlugPolicy) NanoidSlugGenerator {
+	if policy.Case == enums.SlugCaseLower {
+		return NanoidSlugGenerator{Alphabet: lowercaseAlphabet}
+	}
+
+	return NanoidSlugGenerator{}
+}
+
+// NanoidSlugGenerator generates random slugs from an alphabet, which
+// defaults to letters and digits.
+type NanoidSlugGenerator struct {
+	Alphabet string
+	// SlugLength defaults to 8.
+	SlugLength int
+}
+
+// NewCrockfordSlugGenerator generates random slugs from Crockford's base32
+// alphabet, which is easy to read out and type correctly.
+func NewCrockfordSlugGenerator(length int) NanoidSlugGenerator {
+	return NanoidSlugGenerator{
+		Alphabet:   crockfordAlphabet,
+		SlugLength: length,
+	}
+}
+
+func (g NanoidSlugGenerator) Generate(id int64, length int) (string, error) {
+	chars := g.Alphabet
+
+	if chars == "" {
+		chars = alphabet
+	}
+
+	return gonanoid.Generate(chars, length)
+}
+
+func (g NanoidSlugGenerator) Length() int {
+	return lengthOrDefault(g.SlugLength, defaultGeneratedSlugLength)
+}
+
+func (g NanoidSlugGenerator) NeedsId() bool {
+	return false
+}
+
+// PronounceableSlugGenerator generates random slugs of alternating
+// consonants and vowels, like "bakotifu", which are easy to say and
+// remember.
+type PronounceableSlugGenerator struct {
+	// SlugLength defaults to 8.
+	SlugLength int
+}
+
+func (g PronounceableSlugGenerator) Generate(id int64, length int) (string, error) {
+	consonantChars, err := gonanoid.Generate(consonants, (length+1)/2)
+
+	if err != nil {
+		return "", err
+	}
+
+	vowelChars, err := gonanoid.Generate(vowels, length/2)
+
+	if err != nil {
+		return "", err
+	}
+
+	var slug strings.Builder
+
+	for i := 0; i < length; i++ {
+		if i%2 == 0 {
+			slug.WriteByte(consonantChars[i/2])
+		} else {
+			slug.WriteByte(vowelChars[i/2])
+		}
+	}
+
+	return slug.String(), nil
+}
+
+func (g PronounceableSlugGenerator) Length() int {
+	return lengthOrDefault(g.SlugLength, defaultGeneratedSlugLength)
+}
+
+func (g PronounceableSlugGenerator) NeedsId() bool {
+	return false
+}
+
+// SequentialSlugGenerator generates the shortest slugs possible: the id of
+// each short URL in base62 (or base36, with the lowercase alphabet).
+// Sequential slugs are easy to guess, so they suit short URLs that aren't
+// secret.
+type SequentialSlugGenerator struct {
+	// Lowercase uses digits and lowercase letters only, for slug policies
+	// that lowercase slugs.
+	Lowercase bool
+	// SlugLength pads slugs with leading zeros. It defaults to 1, which
+	// doesn't pad them at all.
+	SlugLength int
+}
+
+func (g SequentialSlugGenerator) Generate(id int64, length int) (string, error) {
+	digitChars := alphabet
+
+	if g.Lowercase {
+		digitChars = lowercaseAlphabet
+	}
+
+	base := int64(len(digitChars))
+	var digits []byte
+
+	for n := id; n > 0; n /= base {
+		digits = append([]byte{digitChars[n%base]}, digits...)
+	}
+
+	for len(digits) < length {
+		digits = append([]byte{'0'}, digits...)
+	}
+
+	return string(digits), nil
+}
+
+func (g SequentialSlugGenerator) Length() int {
+	return lengthOrDefault(g.SlugLength, 1)
+}
+
+func (g SequentialSlugGenerator) NeedsId() bool {
+	return true
+}
+
+func lengthOrDefault(length int, defaultLength int) int {
+	if length <= 0 {
+		return defaultLength
+	}
+
+	return length
 }
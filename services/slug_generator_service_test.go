@@ -0,0 +1,60 @@
+package services
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGeneratorsUseTheirAlphabets(t *testing.T) {
+	tests := []struct {
+		generator SlugGenerator
+		pattern   string
+	}{
+		{NanoidSlugGenerator{}, `^[0-9A-Za-z]{8}$`},
+		{NanoidSlugGenerator{Alphabet: "ab", SlugLength: 4}, `^[ab]{4}$`},
+		{NewCrockfordSlugGenerator(6), `^[0-9abcdefghjkmnpqrstvwxyz]{6}$`},
+		{PronounceableSlugGenerator{}, `^([bdfghjklmnprstvz][aeiou]){4}$`},
+	}
+
+	for _, test := range tests {
+		slug, err := test.generator.Generate(0, test.generator.Length())
+
+		assert.Nil(t, err)
+		assert.Regexp(t, regexp.MustCompile(test.pattern), slug)
+		assert.False(t, test.generator.NeedsId())
+	}
+
+	slug, err := PronounceableSlugGenerator{}.Generate(0, 5)
+
+	assert.Nil(t, err)
+	assert.Regexp(t, `^[bdfghjklmnprstvz][aeiou][bdfghjklmnprstvz][aeiou][bdfghjklmnprstvz]$`, slug)
+}
+
+func TestSequentialGeneratorEncodesIds(t *testing.T) {
+	generator := SequentialSlugGenerator{}
+	tests := map[int64]string{
+		1:     "1",
+		61:    "z",
+		62:    "10",
+		3843:  "zz",
+		12345: "3D7",
+	}
+
+	assert.True(t, generator.NeedsId())
+	assert.Equal(t, 1, generator.Length())
+
+	for id, expected := range tests {
+		slug, err := generator.Generate(id, generator.Length())
+
+		assert.Nil(t, err)
+		assert.Equal(t, expected, slug)
+	}
+
+	slug, _ := generator.Generate(62, 4)
+	assert.Equal(t, "0010", slug)
+
+	slug, _ = SequentialSlugGenerator{Lowercase: true}.Generate(36, 1)
+	assert.Equal(t, "10", slug)
+}
@@ -0,0 +1,131 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"url-shortener/e"
+	"url-shortener/enums"
+
+	"golang.org/x/exp/slices"
+)
+
+// DefaultSlugMaxLength leaves room for a few namespaced segments.
+const DefaultSlugMaxLength = 64
+
+// DefaultReservedWords are slugs that could be mistaken for, or get in the
+// way of, pages the server might serve itself.
+var DefaultReservedWords = []string{"admin", "api", "health", "login", "logout", "static", "swagger"}
+
+// routeSegments are always reserved, whatever the policy says. The API and its
+// docs are served under "api" and "swagger", and the rest are routes under
+// /api/v1/shorturls/ that would shadow a short URL with the same slug there.
+var routeSegments = []string{"api", "swagger", "export", "import", "trash"}
+
+// SlugPolicy decides which slugs short URLs and namespaces may have. Slugs
+// are made of letters, digits, "-", "_" and ".", in up to MaxSlugSegments
+// segments separated by "/". Every short URL's slug has to follow the
+// policy, whether it's chosen or generated.
+type SlugPolicy struct {
+	// MinLength and MaxLength bound the length of the whole slug. Zero
+	// leaves either one unbounded.
+	MinLength int
+	MaxLength int
+	// Case SlugCaseLower lowercases slugs as they're saved, so that they can
+	// be matched without regard to case.
+	Case enums.SlugCase
+	// ReservedWords can't be used as a slug or the first segment of one.
+	// They're matched without regard to case.
+	ReservedWords []string
+	// BlockedWords, such as a profanity list, can't appear anywhere in a
+	// slug. They're matched without regard to case.
+	BlockedWords []string
+}
+
+// Normalize returns slug the way it's saved.
+func (p SlugPolicy) Normalize(slug string) string {
+	if p.Case == enums.SlugCaseLower {
+		return strings.ToLower(slug)
+	}
+
+	return slug
+}
+
+// Validate returns the reasons slug can't be used, or nil if it can.
+func (p SlugPolicy) Validate(slug string) []e.ValidationError {
+	return validationErrors("Slug", p.violations(slug, MaxSlugSegments))
+}
+
+// validatePrefix is Validate for namespace prefixes, which leave room for at
+// least one more segment in the slugs under them.
+func (p SlugPolicy) validatePrefix(prefix string) []e.ValidationError {
+	return validationErrors("Prefix", p.violations(prefix, MaxSlugSegments-1))
+}
+
+func (p SlugPolicy) violations(slug string, maxSegments int) []string {
+	var reasons []string
+
+	if strings.IndexFunc(slug, func(r rune) bool { return !isSlugRune(r) }) >= 0 {
+		reasons = append(reasons, "must only contain letters, digits, -, _, . and /")
+	}
+
+	if !validHierarchy(slug, maxSegments) {
+		reasons = append(reasons, fmt.Sprintf("must have at most %d segments, none of them empty, . or ..", maxSegments))
+	}
+
+	if len(slug) < p.MinLength {
+		reasons = append(reasons, fmt.Sprintf("must be at least %d characters", p.MinLength))
+	}
+
+	if p.MaxLength > 0 && len(slug) > p.MaxLength {
+		reasons = append(reasons, fmt.Sprintf("must be at most %d characters", p.MaxLength))
+	}
+
+	if p.reserved(slug) {
+		reasons = append(reasons, "is reserved")
+	}
+
+	if p.blocked(slug) {
+		reasons = append(reasons, "contains a blocked word")
+	}
+
+	return reasons
+}
+
+func (p SlugPolicy) reserved(slug string) bool {
+	first, _, _ := strings.Cut(strings.ToLower(slug), "/")
+
+	return slices.Contains(routeSegments, first) || slices.IndexFunc(p.ReservedWords, func(word string) bool {
+		return strings.ToLower(word) == first
+	}) >= 0
+}
+
+func (p SlugPolicy) blocked(slug string) bool {
+	slug = strings.ToLower(slug)
+
+	return slices.IndexFunc(p.BlockedWords, func(word string) bool {
+		return word != "" && strings.Contains(slug, strings.ToLower(word))
+	}) >= 0
+}
+
+func isSlugRune(r rune) bool {
+	switch {
+	case 'a' <= r && r <= 'z', 'A' <= r && r <= 'Z', '0' <= r && r <= '9':
+		return true
+	}
+
+	return strings.ContainsRune("-_./", r)
+}
+
+func validationErrors(field string, reasons []string) []e.ValidationError {
+	if len(reasons) == 0 {
+		return nil
+	}
+
+	errs := make([]e.ValidationError, len(reasons))
+
+	for i, reason := range reasons {
+		errs[i] = e.ValidationError{Field: field, Reason: reason}
+	}
+
+	return errs
+}
@@ -0,0 +1,110 @@
+package services
+
+import (
+	"strings"
+	"testing"
+	"url-shortener/e"
+	"url-shortener/enums"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidatesSlugsAgainstPolicy(t *testing.T) {
+	policy := SlugPolicy{
+		MinLength:     3,
+		MaxLength:     20,
+		ReservedWords: []string{"health", "Admin"},
+		BlockedWords:  []string{"darn"},
+	}
+
+	tests := map[string][]string{
+		"google":         nil,
+		"eng/oncall":     nil,
+		"v1.2_release-a": nil,
+		"go":             {"must be at least 3 characters"},
+		"a-very-long-slug-indeed": {
+			"must be at most 20 characters",
+		},
+		"my slug":       {"must only contain letters, digits, -, _, . and /"},
+		"café":          {"must only contain letters, digits, -, _, . and /"},
+		"eng/":          {"must have at most 5 segments, none of them empty, . or .."},
+		"/eng":          {"must have at most 5 segments, none of them empty, . or .."},
+		"eng//oncall":   {"must have at most 5 segments, none of them empty, . or .."},
+		"eng/../oncall": {"must have at most 5 segments, none of them empty, . or .."},
+		"a/b/c/d/e/f":   {"must have at most 5 segments, none of them empty, . or .."},
+		"API":           {"is reserved"},
+		"swagger/index": {"is reserved"},
+		"trash":         {"is reserved"},
+		"Export":        {"is reserved"},
+		"import/csv":    {"is reserved"},
+		"admin":         {"is reserved"},
+		"health/check":  {"is reserved"},
+		"healthy":       nil,
+		"DarnIt":        {"contains a blocked word"},
+		"s?":            {"must be at least 3 characters", "must only contain letters, digits, -, _, . and /"},
+	}
+
+	for slug, expected := range tests {
+		var reasons []string
+
+		for _, err := range policy.Validate(slug) {
+			assert.Equal(t, "Slug", err.Field)
+			reasons = append(reasons, err.Reason)
+		}
+
+		assert.ElementsMatch(t, expected, reasons, slug)
+	}
+}
+
+func TestValidatesNamespacePrefixesWithRoomForSlugs(t *testing.T) {
+	policy := SlugPolicy{}
+
+	assert.Nil(t, policy.validatePrefix("a/b/c/d"))
+	assert.Equal(
+		t,
+		[]e.ValidationError{{Field: "Prefix", Reason: "must have at most 4 segments, none of them empty, . or .."}},
+		policy.validatePrefix("a/b/c/d/e"),
+	)
+}
+
+func TestLowercasesSlugsWhenFoldingCase(t *testing.T) {
+	assert.Equal(t, "Eng/OnCall", SlugPolicy{}.Normalize("Eng/OnCall"))
+	assert.Equal(t, "eng/oncall", SlugPolicy{Case: enums.SlugCaseLower}.Normalize("Eng/OnCall"))
+}
+
+func TestGeneratesSlugsThatFollowPolicy(t *testing.T) {
+	generate := func(policy SlugPolicy) (string, error) {
+		var slug string
+
+		service := CreateShortUrlService{SlugPolicy: policy}
+		err := service.retryGeneratedSlug(DefaultSlugGenerator(policy), 0, func(generated string) error {
+			slug = generated
+			return nil
+		})
+
+		return slug, err
+	}
+
+	policy := SlugPolicy{
+		MinLength:    12,
+		Case:         enums.SlugCaseLower,
+		BlockedWords: []string{"a"},
+	}
+
+	for i := 0; i < 20; i++ {
+		slug, err := generate(policy)
+
+		assert.Nil(t, err)
+		assert.Len(t, slug, 12)
+		assert.NotContains(t, slug, "a")
+		assert.Equal(t, strings.ToLower(slug), slug)
+	}
+
+	slug, err := generate(SlugPolicy{MaxLength: 5})
+
+	assert.Nil(t, err)
+	assert.Len(t, slug, 5)
+
+	_, err = generate(SlugPolicy{BlockedWords: strings.Split(lowercaseAlphabet, "")})
+	assert.ErrorIs(t, err, errNoAvailableSlug)
+}
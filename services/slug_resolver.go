@@ -0,0 +1,128 @@
+package services
+
+import (
+	"errors"
+	"log"
+	"strings"
+	"time"
+	"url-shortener/cache"
+	"url-shortener/models"
+	"url-shortener/repositories"
+)
+
+type SlugCacheConfig struct {
+	// Ttl is how long a resolved slug is cached, unless its short URL expires
+	// sooner.
+	Ttl time.Duration
+	// MissingTtl is how long a slug that doesn't exist is cached.
+	MissingTtl time.Duration
+}
+
+// SlugResolver finds the short URL that a slug redirects to, only going to
+// the store when the cache doesn't already know. The cache is best effort:
+// if it can't be reached, slugs are resolved from the store alone.
+//
+// Filling the cache after a miss races with writes: a resolve that read the
+// store before a write committed can cache what it read after the write has
+// invalidated the slug. The stale entry then lasts until it expires, so
+// Config.Ttl (or Config.MissingTtl, for a slug that was just created) is the
+// longest a redirect can lag behind a write.
+type SlugResolver struct {
+	ShortUrls repositories.ShortUrlRepository
+	// Cache is optional. The store that short URLs are changed through must
+	// invalidate it (see cache.NewInvalidatingStore).
+	Cache  cache.SlugCache
+	Clock  Clock
+	Config SlugCacheConfig
+	// FoldCase also looks slugs up in lowercase, for when the slug policy
+	// lowercases new slugs.
+	FoldCase bool
+}
+
+func (r *SlugResolver) Resolve(slug string) (models.ShortUrl, error) {
+	if r.Cache == nil {
+		return r.ShortUrls.FindBySlug(slug)
+	}
+
+	entry, ok, err := r.Cache.Get(slug)
+
+	if err != nil {
+		log.Printf("encountered error reading cached slug %s: %v", slug, err)
+	}
+
+	if ok {
+		if entry.Missing {
+			return models.ShortUrl{}, repositories.ErrNotFound
+		}
+
+		return entry.ShortUrl, nil
+	}
+
+	shortUrl, err := r.ShortUrls.FindBySlug(slug)
+
+	switch {
+	case errors.Is(err, repositories.ErrNotFound):
+		r.cache(slug, cache.Entry{Missing: true}, r.Config.MissingTtl)
+	case err == nil:
+		r.cache(slug, cache.Entry{ShortUrl: shortUrl}, r.ttl(shortUrl))
+	}
+
+	return shortUrl, err
+}
+
+// ResolvePath finds the short URL of the longest slug that path starts with,
+// a whole segment at a time, so that /eng/oncall/runbook resolves to
+// eng/oncall if there's no eng/oncall/runbook. It returns the rest of path
+// after the slug, starting with a "/" unless it's empty.
+func (r *SlugResolver) ResolvePath(path string) (models.ShortUrl, string, error) {
+	segments := strings.Split(path, "/")
+
+	if len(segments) > MaxSlugSegments {
+		segments = segments[:MaxSlugSegments]
+	}
+
+	for n := len(segments); n > 0; n-- {
+		// A trailing slash isn't part of a slug.
+		if segments[n-1] == "" {
+			continue
+		}
+
+		slug := strings.Join(segments[:n], "/")
+		shortUrl, err := r.Resolve(slug)
+
+		if errors.Is(err, repositories.ErrNotFound) && r.FoldCase && strings.ToLower(slug) != slug {
+			shortUrl, err = r.Resolve(strings.ToLower(slug))
+		}
+
+		if !errors.Is(err, repositories.ErrNotFound) {
+			return shortUrl, path[len(slug):], err
+		}
+	}
+
+	return models.ShortUrl{}, "", repositories.ErrNotFound
+}
+
+// ttl caps Config.Ttl so that a short URL is never cached past its
+// expiration.
+func (r *SlugResolver) ttl(shortUrl models.ShortUrl) time.Duration {
+	ttl := r.Config.Ttl
+
+	if shortUrl.ExpiresOn.Valid {
+		if untilExpiry := shortUrl.ExpiresOn.Time.Sub(r.Clock.Now()); untilExpiry < ttl {
+			ttl = untilExpiry
+		}
+	}
+
+	return ttl
+}
+
+func (r *SlugResolver) cache(slug string, entry cache.Entry, ttl time.Duration) {
+	// Redis can't expire keys any sooner.
+	if ttl < time.Millisecond {
+		return
+	}
+
+	if err := r.Cache.Set(slug, entry, ttl); err != nil {
+		log.Printf("encountered error caching slug %s: %v", slug, err)
+	}
+}
@@ -0,0 +1,167 @@
+package services
+
+import (
+	"testing"
+	"time"
+	"url-shortener/cache"
+	"url-shortener/models"
+	"url-shortener/repositories"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/guregu/null.v4"
+)
+
+func newSlugResolver(store repositories.Store, slugCache cache.SlugCache) *SlugResolver {
+	return &SlugResolver{
+		ShortUrls: store.ShortUrls(),
+		Cache:     slugCache,
+		Clock:     TestClock{},
+		Config: SlugCacheConfig{
+			Ttl:        time.Hour,
+			MissingTtl: time.Minute,
+		},
+	}
+}
+
+func createShortUrl(t *testing.T, store repositories.Store, slug string, expiresOn null.Time) models.ShortUrl {
+	shortUrl := models.ShortUrl{}
+	shortUrl.Slug = slug
+	shortUrl.LongUrl = "https://" + slug + ".example.com"
+	shortUrl.ExpiresOn = expiresOn
+
+	assert.Nil(t, store.ShortUrls().Create(&shortUrl))
+
+	return shortUrl
+}
+
+func TestSlugResolverCachesResolvedSlugs(t *testing.T) {
+	store := repositories.NewMemoryStore()
+	slugCache := cache.NewLru(10)
+	subject := newSlugResolver(store, slugCache)
+
+	shortUrl := createShortUrl(t, store, "a", null.Time{})
+
+	resolved, err := subject.Resolve("a")
+
+	assert.Nil(t, err)
+	assert.Equal(t, shortUrl.Id, resolved.Id)
+
+	// Deleting the short URL without invalidating the cache shows that the
+	// second resolution came from the cache.
+	assert.Nil(t, store.ShortUrls().Delete(shortUrl))
+
+	resolved, err = subject.Resolve("a")
+
+	assert.Nil(t, err)
+	assert.Equal(t, shortUrl.LongUrl, resolved.LongUrl)
+}
+
+func TestSlugResolverCachesMissingSlugs(t *testing.T) {
+	store := repositories.NewMemoryStore()
+	slugCache := cache.NewLru(10)
+	subject := newSlugResolver(store, slugCache)
+
+	_, err := subject.Resolve("a")
+	assert.ErrorIs(t, err, repositories.ErrNotFound)
+
+	createShortUrl(t, store, "a", null.Time{})
+
+	_, err = subject.Resolve("a")
+	assert.ErrorIs(t, err, repositories.ErrNotFound)
+
+	// Creating it through an invalidating store makes it resolve.
+	assert.Nil(t, slugCache.Delete("a"))
+
+	_, err = subject.Resolve("a")
+	assert.Nil(t, err)
+}
+
+func TestSlugResolverCapsTtlAtExpiration(t *testing.T) {
+	store := repositories.NewMemoryStore()
+	slugCache := &recordingSlugCache{SlugCache: cache.NewLru(10), ttls: map[string]time.Duration{}}
+	subject := newSlugResolver(store, slugCache)
+
+	createShortUrl(t, store, "soon", null.TimeFrom(TestClock{}.Now().Add(10*time.Minute)))
+	createShortUrl(t, store, "later", null.TimeFrom(TestClock{}.Now().Add(48*time.Hour)))
+	createShortUrl(t, store, "expired", null.TimeFrom(TestClock{}.Now().Add(-time.Minute)))
+
+	for _, slug := range []string{"soon", "later", "expired", "missing"} {
+		subject.Resolve(slug)
+	}
+
+	assert.Equal(t, map[string]time.Duration{
+		"soon":    10 * time.Minute,
+		"later":   time.Hour,
+		"missing": time.Minute,
+	}, slugCache.ttls)
+}
+
+func TestSlugResolverWithoutCacheUsesStore(t *testing.T) {
+	store := repositories.NewMemoryStore()
+	subject := newSlugResolver(store, nil)
+
+	shortUrl := createShortUrl(t, store, "a", null.Time{})
+	assert.Nil(t, store.ShortUrls().Delete(shortUrl))
+
+	_, err := subject.Resolve("a")
+	assert.ErrorIs(t, err, repositories.ErrNotFound)
+}
+
+type recordingSlugCache struct {
+	cache.SlugCache
+	ttls map[string]time.Duration
+}
+
+func (c *recordingSlugCache) Set(slug string, entry cache.Entry, ttl time.Duration) error {
+	c.ttls[slug] = ttl
+
+	return c.SlugCache.Set(slug, entry, ttl)
+}
+
+func TestSlugResolverResolvesLongestSlugInPath(t *testing.T) {
+	store := repositories.NewMemoryStore()
+	subject := newSlugResolver(store, cache.NewLru(10))
+
+	createShortUrl(t, store, "eng", null.Time{})
+	oncall := createShortUrl(t, store, "eng/oncall", null.Time{})
+
+	tests := map[string]string{
+		"eng/oncall":             "",
+		"eng/oncall/":            "/",
+		"eng/oncall/runbook/new": "/runbook/new",
+	}
+
+	for path, expectedRest := range tests {
+		resolved, rest, err := subject.ResolvePath(path)
+
+		assert.Nil(t, err)
+		assert.Equal(t, oncall.Id, resolved.Id, path)
+		assert.Equal(t, expectedRest, rest, path)
+	}
+
+	resolved, rest, err := subject.ResolvePath("eng/docs")
+
+	assert.Nil(t, err)
+	assert.Equal(t, "eng", resolved.Slug)
+	assert.Equal(t, "/docs", rest)
+
+	_, _, err = subject.ResolvePath("ops/oncall")
+	assert.ErrorIs(t, err, repositories.ErrNotFound)
+}
+
+func TestSlugResolverFoldsCaseOnlyWhenAsked(t *testing.T) {
+	store := repositories.NewMemoryStore()
+	subject := newSlugResolver(store, nil)
+
+	oncall := createShortUrl(t, store, "eng/oncall", null.Time{})
+
+	_, _, err := subject.ResolvePath("Eng/OnCall")
+	assert.ErrorIs(t, err, repositories.ErrNotFound)
+
+	subject.FoldCase = true
+	resolved, rest, err := subject.ResolvePath("Eng/OnCall/Runbook")
+
+	assert.Nil(t, err)
+	assert.Equal(t, oncall.Id, resolved.Id)
+	assert.Equal(t, "/Runbook", rest)
+}
@@ -0,0 +1,56 @@
+package services
+
+import (
+	"time"
+	"url-shortener/enums"
+)
+
+// TimeRange is the half-open interval [Start, End). A zero Start or End
+// leaves that side of the range unbounded.
+type TimeRange struct {
+	Start time.Time
+	End   time.Time
+}
+
+// timePeriodRange resolves a named time period relative to now. Calendar
+// periods (days, weeks starting on Monday, and months) are evaluated in the
+// location of now, so callers should convert now to the time zone they're
+// reporting in. "Last N days" periods include today, so LAST_7_DAYS covers
+// today and the six full days before it.
+func timePeriodRange(timePeriod enums.GetClicksTimePeriod, now time.Time) TimeRange {
+	year, month, day := now.Date()
+	location := now.Location()
+
+	midnight := func(daysFromToday int) time.Time {
+		return time.Date(year, month, day+daysFromToday, 0, 0, 0, 0, location)
+	}
+
+	firstOfMonth := func(monthsFromThisMonth int) time.Time {
+		return time.Date(year, month+time.Month(monthsFromThisMonth), 1, 0, 0, 0, 0, location)
+	}
+
+	daysSinceMonday := (int(now.Weekday()) + 6) % 7
+
+	switch timePeriod {
+	case enums.GetClicksTimePeriod24Hours:
+		return TimeRange{Start: now.Add(-24 * time.Hour)}
+	case enums.GetClicksTimePeriodPastWeek:
+		return TimeRange{Start: now.AddDate(0, 0, -7)}
+	case enums.GetClicksTimePeriodToday:
+		return TimeRange{Start: midnight(0), End: midnight(1)}
+	case enums.GetClicksTimePeriodYesterday:
+		return TimeRange{Start: midnight(-1), End: midnight(0)}
+	case enums.GetClicksTimePeriodThisWeek:
+		return TimeRange{Start: midnight(-daysSinceMonday), End: midnight(7 - daysSinceMonday)}
+	case enums.GetClicksTimePeriodThisMonth:
+		return TimeRange{Start: firstOfMonth(0), End: firstOfMonth(1)}
+	case enums.GetClicksTimePeriodLastMonth:
+		return TimeRange{Start: firstOfMonth(-1), End: firstOfMonth(0)}
+	case enums.GetClicksTimePeriodLast7Days:
+		return TimeRange{Start: midnight(-6), End: midnight(1)}
+	case enums.GetClicksTimePeriodLast30Days:
+		return TimeRange{Start: midnight(-29), End: midnight(1)}
+	}
+
+	return TimeRange{}
+}
@@ -0,0 +1,95 @@
+package services
+
+import (
+	"testing"
+	"time"
+	"url-shortener/enums"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTimePeriodRange(t *testing.T) {
+	chicago, err := time.LoadLocation("America/Chicago")
+	assert.Nil(t, err)
+
+	// A Tuesday evening in Chicago, which is already Wednesday in UTC
+	now := time.Date(2022, 3, 15, 21, 30, 0, 0, chicago)
+
+	type test struct {
+		timePeriod enums.GetClicksTimePeriod
+		expected   TimeRange
+	}
+
+	tests := []test{
+		{
+			timePeriod: enums.GetClicksTimePeriodAllTime,
+			expected:   TimeRange{},
+		},
+		{
+			timePeriod: enums.GetClicksTimePeriod24Hours,
+			expected:   TimeRange{Start: time.Date(2022, 3, 14, 21, 30, 0, 0, chicago)},
+		},
+		{
+			// Daylight saving time started on 2022-03-13, so the past week
+			// is 167 hours long
+			timePeriod: enums.GetClicksTimePeriodPastWeek,
+			expected:   TimeRange{Start: time.Date(2022, 3, 8, 21, 30, 0, 0, chicago)},
+		},
+		{
+			timePeriod: enums.GetClicksTimePeriodToday,
+			expected: TimeRange{
+				Start: time.Date(2022, 3, 15, 0, 0, 0, 0, chicago),
+				End:   time.Date(2022, 3, 16, 0, 0, 0, 0, chicago),
+			},
+		},
+		{
+			timePeriod: enums.GetClicksTimePeriodYesterday,
+			expected: TimeRange{
+				Start: time.Date(2022, 3, 14, 0, 0, 0, 0, chicago),
+				End:   time.Date(2022, 3, 15, 0, 0, 0, 0, chicago),
+			},
+		},
+		{
+			timePeriod: enums.GetClicksTimePeriodThisWeek,
+			expected: TimeRange{
+				Start: time.Date(2022, 3, 14, 0, 0, 0, 0, chicago),
+				End:   time.Date(2022, 3, 21, 0, 0, 0, 0, chicago),
+			},
+		},
+		{
+			timePeriod: enums.GetClicksTimePeriodThisMonth,
+			expected: TimeRange{
+				Start: time.Date(2022, 3, 1, 0, 0, 0, 0, chicago),
+				End:   time.Date(2022, 4, 1, 0, 0, 0, 0, chicago),
+			},
+		},
+		{
+			timePeriod: enums.GetClicksTimePeriodLastMonth,
+			expected: TimeRange{
+				Start: time.Date(2022, 2, 1, 0, 0, 0, 0, chicago),
+				End:   time.Date(2022, 3, 1, 0, 0, 0, 0, chicago),
+			},
+		},
+		{
+			timePeriod: enums.GetClicksTimePeriodLast7Days,
+			expected: TimeRange{
+				Start: time.Date(2022, 3, 9, 0, 0, 0, 0, chicago),
+				End:   time.Date(2022, 3, 16, 0, 0, 0, 0, chicago),
+			},
+		},
+		{
+			timePeriod: enums.GetClicksTimePeriodLast30Days,
+			expected: TimeRange{
+				Start: time.Date(2022, 2, 14, 0, 0, 0, 0, chicago),
+				End:   time.Date(2022, 3, 16, 0, 0, 0, 0, chicago),
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		actual := timePeriodRange(tc.timePeriod, now)
+
+		assert.True(t, tc.expected.Start.Equal(actual.Start), "start of %v: %v", tc.timePeriod, actual.Start)
+		assert.True(t, tc.expected.End.Equal(actual.End), "end of %v: %v", tc.timePeriod, actual.End)
+	}
+}
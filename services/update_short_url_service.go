@@ -0,0 +1,197 @@
+package services
+
+import (
+	"errors"
+	"url-shortener/db"
+	"url-shortener/e"
+	"url-shortener/enums"
+	"url-shortener/models"
+	"url-shortener/repositories"
+
+	"gopkg.in/guregu/null.v4"
+)
+
+type UpdateShortUrlService struct {
+	Store      repositories.Store
+	SlugPolicy SlugPolicy
+	// Clock decides whether a superseded short URL is no longer expired.
+	Clock Clock
+}
+
+type UpdateResult struct {
+	Status enums.UpdateStatus
+	Record *models.ShortUrl
+	// ValidationErrors are why the slug is invalid.
+	ValidationErrors []e.ValidationError
+	Error            error
+}
+
+// Update changes the short URL with slug on behalf of principal, recording
+// the change in the audit log along with origin.
+func (s *UpdateShortUrlService) Update(principal *Principal, origin Origin, slug string, fields models.ShortUrlUpdateFields) UpdateResult {
+	shortUrl, err := s.Store.ShortUrls().FindBySlug(slug)
+
+	if errors.Is(err, repositories.ErrNotFound) {
+		return UpdateResult{
+			Status: enums.UpdateResultNotFound,
+		}
+	}
+
+	if err != nil {
+		return UpdateResult{
+			Status: enums.UpdateResultUnknownError,
+			Error:  err,
+		}
+	}
+
+	if !principal.CanModify(shortUrl) {
+		return UpdateResult{
+			Status: enums.UpdateResultForbidden,
+		}
+	}
+
+	before := shortUrl
+
+	if fields.LongUrl != nil {
+		shortUrl.LongUrl = *fields.LongUrl
+	}
+
+	if fields.LinkType != nil {
+		shortUrl.LinkType = fields.LinkType
+	}
+
+	// Changing either the long URL or the link type can make the other
+	// invalid.
+	if fields.LongUrl != nil || fields.LinkType != nil {
+		template := IsTemplate(shortUrl.ShortUrlRedirectFields)
+		validUrl, err := validateLongUrl(shortUrl.LongUrl, template)
+
+		if !validUrl {
+			status := enums.UpdateResultInvalidLongUrl
+
+			if template {
+				status = enums.UpdateResultInvalidTemplate
+			}
+
+			return UpdateResult{
+				Status: status,
+				Error:  err,
+			}
+		}
+	}
+
+	if fields.Slug != nil {
+		*fields.Slug = s.SlugPolicy.Normalize(*fields.Slug)
+	}
+
+	// Slugs that were allowed before the policy changed can be kept.
+	if fields.Slug != nil && *fields.Slug != shortUrl.Slug {
+		if errs := s.SlugPolicy.Validate(*fields.Slug); errs != nil {
+			return UpdateResult{
+				Status:           enums.UpdateResultInvalidSlug,
+				ValidationErrors: errs,
+			}
+		}
+
+		check, err := checkSlug(s.Store, principal, *fields.Slug)
+
+		if err != nil {
+			return UpdateResult{
+				Status: enums.UpdateResultUnknownError,
+				Error:  err,
+			}
+		}
+
+		switch check {
+		case slugOutsideNamespace:
+			return UpdateResult{Status: enums.UpdateResultSlugOutsideNamespace}
+		case slugForbidden:
+			return UpdateResult{Status: enums.UpdateResultForbiddenSlug}
+		}
+
+		shortUrl.Slug = *fields.Slug
+	}
+
+	if fields.ExpiresOn.Set {
+		shortUrl.ExpiresOn = fields.ExpiresOn.Time
+	}
+
+	if fields.UtmSource != nil {
+		shortUrl.UtmSource = *fields.UtmSource
+	}
+
+	if fields.UtmMedium != nil {
+		shortUrl.UtmMedium = *fields.UtmMedium
+	}
+
+	if fields.UtmCampaign != nil {
+		shortUrl.UtmCampaign = *fields.UtmCampaign
+	}
+
+	if fields.RedirectType != nil {
+		shortUrl.RedirectType = fields.RedirectType
+	}
+
+	if fields.CacheControl != nil {
+		shortUrl.CacheControl = fields.CacheControl
+	}
+
+	if fields.CacheMaxAge != nil {
+		shortUrl.CacheMaxAge = fields.CacheMaxAge
+	}
+
+	if fields.QueryPassthrough != nil {
+		shortUrl.QueryPassthrough = fields.QueryPassthrough
+	}
+
+	if fields.PathPassthrough != nil {
+		shortUrl.PathPassthrough = fields.PathPassthrough
+	}
+
+	if fields.FallbackUrl != nil {
+		if !validFallbackUrl(fields.FallbackUrl) {
+			return UpdateResult{
+				Status: enums.UpdateResultInvalidFallbackUrl,
+			}
+		}
+
+		shortUrl.FallbackUrl = fields.FallbackUrl
+	}
+
+	// A superseded short URL that's no longer expired wants its long URL
+	// back, which conflicts if the short URL that superseded it still has it.
+	if shortUrl.SupersededAt.Valid && !IsExpired(shortUrl, s.Clock.Now()) {
+		shortUrl.SupersededAt = null.Time{}
+	}
+
+	err = s.Store.Transaction(func(tx repositories.Store) error {
+		if err := tx.ShortUrls().Update(&shortUrl); err != nil {
+			return err
+		}
+
+		return recordAudit(tx, models.AuditActionUpdate, principal, origin, &before, &shortUrl)
+	})
+
+	if err == nil {
+		return UpdateResult{
+			Status: enums.UpdateResultSuccessful,
+			Record: &shortUrl,
+		}
+	}
+
+	switch violatedUniqueConstraint(err) {
+	case db.DuplicateSlug:
+		return UpdateResult{
+			Status: enums.UpdateResultDuplicateSlug,
+		}
+	case db.DuplicateLongUrl:
+		return UpdateResult{
+			Status: enums.UpdateResultDuplicateLongUrl,
+		}
+	}
+
+	return UpdateResult{
+		Status: enums.UpdateResultUnknownError,
+		Error:  err,
+	}
+}
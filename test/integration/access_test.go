@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"net/http"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/maxatome/go-testdeep/helpers/tdhttp"
@@ -57,3 +58,305 @@ func (suite *accessSuite) TestAccessWithInvalidSlugReturns404() {
 
 	testAPI.Get("/invalid").CmpStatus(http.StatusNotFound)
 }
+
+func (suite *accessSuite) TestAccessFollowsChangesToCachedSlugs() {
+	t := suite.T()
+
+	testServer := TestContext.server
+	testAPI := tdhttp.NewTestAPI(t, testServer)
+
+	admin := TestContext.CreateApiKey("admins", true)
+
+	testAPI.Get("/cf").CmpStatus(http.StatusNotFound)
+
+	testAPI.PostJSON("/api/v1/shorturls", gin.H{"long_url": "https://www.cloudflare.com", "slug": "cf"}).
+		CmpStatus(http.StatusCreated)
+
+	testAPI.Get("/cf").
+		CmpStatus(http.StatusMovedPermanently).
+		CmpHeader(td.SuperMapOf(http.Header{"Location": []string{"https://www.cloudflare.com"}}, nil))
+
+	testAPI.PatchJSON("/api/v1/shorturls/cf", gin.H{"long_url": "https://blog.cloudflare.com"}, "Authorization", admin).
+		CmpStatus(http.StatusOK)
+
+	testAPI.Get("/cf").
+		CmpStatus(http.StatusMovedPermanently).
+		CmpHeader(td.SuperMapOf(http.Header{"Location": []string{"https://blog.cloudflare.com"}}, nil))
+
+	testAPI.PatchJSON("/api/v1/shorturls/cf", gin.H{"slug": "cloudflare"}, "Authorization", admin).
+		CmpStatus(http.StatusOK)
+
+	testAPI.Get("/cf").CmpStatus(http.StatusNotFound)
+	testAPI.Get("/cloudflare").CmpStatus(http.StatusMovedPermanently)
+
+	testAPI.Delete("/api/v1/shorturls/cloudflare", nil, "Authorization", admin).
+		CmpStatus(http.StatusNoContent)
+
+	testAPI.Get("/cloudflare").CmpStatus(http.StatusNotFound)
+}
+
+func (suite *accessSuite) TestAccessUsesRedirectSettingsOfShortUrl() {
+	t := suite.T()
+
+	testServer := TestContext.server
+	testAPI := tdhttp.NewTestAPI(t, testServer)
+
+	admin := TestContext.CreateApiKey("admins", true)
+
+	testAPI.PostJSON("/api/v1/shorturls", gin.H{
+		"long_url":      "https://api.example.com/v1/things",
+		"slug":          "things",
+		"redirect_type": 307,
+		"cache_control": "public",
+		"cache_max_age": 3600,
+	}).
+		CmpStatus(http.StatusCreated).
+		CmpJSONBody(td.SuperJSONOf(`{"redirect_type": 307, "cache_control": "public", "cache_max_age": 3600}`))
+
+	testAPI.Get("/things").
+		CmpStatus(http.StatusTemporaryRedirect).
+		CmpHeader(td.SuperMapOf(http.Header{
+			"Location":      []string{"https://api.example.com/v1/things"},
+			"Cache-Control": []string{"public, max-age=3600"},
+		}, nil))
+
+	testAPI.PatchJSON("/api/v1/shorturls/things", gin.H{"redirect_type": 302}, "Authorization", admin).
+		CmpStatus(http.StatusOK).
+		CmpJSONBody(td.SuperJSONOf(`{"redirect_type": 302, "cache_control": "public", "cache_max_age": 3600}`))
+
+	testAPI.Get("/things").
+		CmpStatus(http.StatusFound).
+		CmpHeader(td.SuperMapOf(http.Header{"Cache-Control": []string{"public, max-age=3600"}}, nil))
+}
+
+func (suite *accessSuite) TestCreateWithInvalidRedirectTypeReturns400() {
+	t := suite.T()
+
+	testServer := TestContext.server
+	testAPI := tdhttp.NewTestAPI(t, testServer)
+
+	testAPI.PostJSON("/api/v1/shorturls", gin.H{"long_url": "https://www.cloudflare.com", "redirect_type": 303}).
+		CmpStatus(http.StatusBadRequest).
+		CmpJSONBody(td.JSON(`{"errors": [{"field": "RedirectType", "reason": "oneof=301 302 307 308"}]}`))
+}
+
+func (suite *accessSuite) TestAccessPassesQueryAndPathThrough() {
+	t := suite.T()
+
+	testServer := TestContext.server
+	testAPI := tdhttp.NewTestAPI(t, testServer)
+
+	testAPI.PostJSON("/api/v1/shorturls", gin.H{
+		"long_url":          "https://docs.example.com/v2?lang=en",
+		"slug":              "docs",
+		"query_passthrough": "prefer_destination",
+		"path_passthrough":  true,
+	}).
+		CmpStatus(http.StatusCreated)
+
+	testAPI.Get("/docs?utm_source=slack&lang=fr").
+		CmpStatus(http.StatusMovedPermanently).
+		CmpHeader(td.SuperMapOf(http.Header{"Location": []string{"https://docs.example.com/v2?lang=en&utm_source=slack"}}, nil))
+
+	testAPI.Get("/docs/guide/intro?utm_source=slack").
+		CmpStatus(http.StatusMovedPermanently).
+		CmpHeader(td.SuperMapOf(http.Header{"Location": []string{"https://docs.example.com/v2/guide/intro?lang=en&utm_source=slack"}}, nil))
+
+	testAPI.Get("/docs/").
+		CmpStatus(http.StatusMovedPermanently).
+		CmpHeader(td.SuperMapOf(http.Header{"Location": []string{"https://docs.example.com/v2?lang=en"}}, nil))
+}
+
+func (suite *accessSuite) TestAccessTagsLongUrlWithUtmParameters() {
+	t := suite.T()
+
+	testServer := TestContext.server
+	testAPI := tdhttp.NewTestAPI(t, testServer)
+
+	admin := TestContext.CreateApiKey("admins", true)
+
+	testAPI.PostJSON("/api/v1/shorturls", gin.H{
+		"long_url":     "https://www.example.com/pricing?plan=pro",
+		"slug":         "pricing",
+		"utm_source":   "newsletter",
+		"utm_campaign": "spring",
+	}).
+		CmpStatus(http.StatusCreated)
+
+	testAPI.Get("/pricing").
+		CmpStatus(http.StatusMovedPermanently).
+		CmpHeader(td.SuperMapOf(http.Header{"Location": []string{"https://www.example.com/pricing?plan=pro&utm_campaign=spring&utm_source=newsletter"}}, nil))
+
+	testAPI.PatchJSON("/api/v1/shorturls/pricing", gin.H{"utm_source": ""}, "Authorization", admin).
+		CmpStatus(http.StatusOK)
+
+	testAPI.Get("/pricing").
+		CmpStatus(http.StatusMovedPermanently).
+		CmpHeader(td.SuperMapOf(http.Header{"Location": []string{"https://www.example.com/pricing?plan=pro&utm_campaign=spring"}}, nil))
+}
+
+func (suite *accessSuite) TestAccessFillsTemplateAndRecordsClick() {
+	t := suite.T()
+
+	testServer := TestContext.server
+	testAPI := tdhttp.NewTestAPI(t, testServer)
+
+	testAPI.PostJSON("/api/v1/shorturls", gin.H{
+		"long_url":  "https://github.com/org/{repo}/issues/{issue}",
+		"slug":      "gh",
+		"link_type": "template",
+	}).
+		CmpStatus(http.StatusCreated).
+		CmpJSONBody(td.SuperJSONOf(`{"link_type": "template"}`))
+
+	testAPI.Get("/gh/url-shortener/42").
+		CmpStatus(http.StatusMovedPermanently).
+		CmpHeader(td.SuperMapOf(http.Header{"Location": []string{"https://github.com/org/url-shortener/issues/42"}}, nil))
+
+	testAPI.Get("/gh?repo=a/b&issue=1%3F2").
+		CmpStatus(http.StatusMovedPermanently).
+		CmpHeader(td.SuperMapOf(http.Header{"Location": []string{"https://github.com/org/a%2Fb/issues/1%3F2"}}, nil))
+
+	testAPI.Get("/gh/url-shortener").
+		CmpStatus(http.StatusNotFound)
+
+	testAPI.Get("/gh/url-shortener/42/extra").
+		CmpStatus(http.StatusNotFound)
+
+	testAPI.Get("/api/v1/shorturls/gh/clicks?time_period=ALL_TIME").
+		CmpStatus(http.StatusOK).
+		CmpJSONBody(td.SuperJSONOf(`{"count": 2}`))
+}
+
+func (suite *accessSuite) TestCreateTemplateWithoutPlaceholdersReturns400() {
+	t := suite.T()
+
+	testServer := TestContext.server
+	testAPI := tdhttp.NewTestAPI(t, testServer)
+
+	admin := TestContext.CreateApiKey("admins", true)
+
+	testAPI.PostJSON("/api/v1/shorturls", gin.H{
+		"long_url":  "https://jira.corp/browse",
+		"link_type": "template",
+	}).
+		CmpStatus(http.StatusBadRequest).
+		CmpJSONBody(
+			td.JSON(`{
+				"errors": [
+					{
+						"field": "LongUrl",
+						"reason": "must be an http or https url with placeholders only in its path, query string, or fragment"
+					}
+				]
+			}`),
+		)
+
+	testAPI.PostJSON("/api/v1/shorturls", gin.H{"long_url": "https://jira.corp/browse/{ticket}", "slug": "jira"}).
+		CmpStatus(http.StatusCreated)
+
+	// Turning a short URL into a template checks its long URL again.
+	testAPI.PatchJSON("/api/v1/shorturls/jira", gin.H{"link_type": "template", "long_url": "https://jira.corp/browse"}, "Authorization", admin).
+		CmpStatus(http.StatusBadRequest)
+
+	testAPI.PatchJSON("/api/v1/shorturls/jira", gin.H{"link_type": "template"}, "Authorization", admin).
+		CmpStatus(http.StatusOK)
+
+	testAPI.Get("/jira/ABC-1").
+		CmpStatus(http.StatusMovedPermanently).
+		CmpHeader(td.SuperMapOf(http.Header{"Location": []string{"https://jira.corp/browse/ABC-1"}}, nil))
+}
+
+func (suite *accessSuite) TestAccessWithPathOfShortUrlWithoutPassthroughReturns404() {
+	t := suite.T()
+
+	testServer := TestContext.server
+	testAPI := tdhttp.NewTestAPI(t, testServer)
+
+	testAPI.PostJSON("/api/v1/shorturls", gin.H{"long_url": "https://www.cloudflare.com?a=1", "slug": "cf"}).
+		CmpStatus(http.StatusCreated)
+
+	testAPI.Get("/cf/extra").CmpStatus(http.StatusNotFound)
+
+	testAPI.Get("/cf?a=2").
+		CmpStatus(http.StatusMovedPermanently).
+		CmpHeader(td.SuperMapOf(http.Header{"Location": []string{"https://www.cloudflare.com?a=1"}}, nil))
+
+	testAPI.Get("/swagger/index.html").CmpStatus(http.StatusOK)
+	testAPI.Get("/api/v1/shorturls/cf").CmpStatus(http.StatusOK)
+}
+
+func (suite *accessSuite) TestAccessWithExpiredSlugReturns410() {
+	t := suite.T()
+
+	testServer := TestContext.server
+	testAPI := tdhttp.NewTestAPI(t, testServer)
+
+	admin := TestContext.CreateApiKey("admins", true)
+
+	expired := time.Now().Add(-time.Hour).UTC().Format(time.RFC3339)
+
+	testAPI.PostJSON("/api/v1/shorturls", gin.H{"long_url": "https://www.cloudflare.com", "slug": "cf", "expires_on": expired}).
+		CmpStatus(http.StatusCreated).
+		CmpJSONBody(td.SuperJSONOf(`{"status": "expired"}`))
+
+	testAPI.Get("/cf").
+		CmpStatus(http.StatusGone).
+		CmpHeader(td.SuperMapOf(http.Header{"Cache-Control": []string{"no-cache"}}, nil))
+
+	testAPI.Get("/api/v1/shorturls/cf").
+		CmpStatus(http.StatusOK).
+		CmpJSONBody(td.SuperJSONOf(`{"status": "expired"}`))
+
+	// Expired slugs stay taken until they're cleaned up.
+	testAPI.PostJSON("/api/v1/shorturls", gin.H{"long_url": "https://blog.cloudflare.com", "slug": "cf"}).
+		CmpStatus(http.StatusConflict)
+
+	testAPI.PatchJSON("/api/v1/shorturls/cf", gin.H{"expires_on": nil}, "Authorization", admin).
+		CmpStatus(http.StatusOK).
+		CmpJSONBody(td.SuperJSONOf(`{"status": "active"}`))
+
+	testAPI.Get("/cf").
+		CmpStatus(http.StatusMovedPermanently).
+		CmpHeader(td.SuperMapOf(http.Header{"Location": []string{"https://www.cloudflare.com"}}, nil))
+}
+
+func (suite *accessSuite) TestAccessWithExpiredSlugPointsToFallbackUrl() {
+	t := suite.T()
+
+	testServer := TestContext.server
+	testAPI := tdhttp.NewTestAPI(t, testServer)
+
+	admin := TestContext.CreateApiKey("admins", true)
+
+	expired := time.Now().Add(-time.Hour).UTC().Format(time.RFC3339)
+
+	testAPI.PostJSON("/api/v1/shorturls", gin.H{
+		"long_url":     "https://www.cloudflare.com/sale",
+		"slug":         "sale",
+		"expires_on":   expired,
+		"fallback_url": "https://www.cloudflare.com/?from=<sale>",
+	}).
+		CmpStatus(http.StatusCreated).
+		CmpJSONBody(td.SuperJSONOf(`{"fallback_url": "https://www.cloudflare.com/?from=<sale>"}`))
+
+	testAPI.Get("/sale").
+		CmpStatus(http.StatusGone).
+		CmpHeader(td.SuperMapOf(http.Header{"Location": []string{"https://www.cloudflare.com/?from=<sale>"}}, nil)).
+		CmpBody(td.Contains(`url=https://www.cloudflare.com/?from=&lt;sale&gt;"`))
+
+	testAPI.PatchJSON("/api/v1/shorturls/sale", gin.H{"fallback_url": "ftp://www.cloudflare.com"}, "Authorization", admin).
+		CmpStatus(http.StatusBadRequest).
+		CmpJSONBody(td.JSON(`{"errors": [{"field": "FallbackUrl", "reason": "only http and https are supported"}]}`))
+}
+
+func (suite *accessSuite) TestCreateWithInvalidFallbackUrlReturns400() {
+	t := suite.T()
+
+	testServer := TestContext.server
+	testAPI := tdhttp.NewTestAPI(t, testServer)
+
+	testAPI.PostJSON("/api/v1/shorturls", gin.H{"long_url": "https://www.cloudflare.com", "fallback_url": "ftp://www.cloudflare.com"}).
+		CmpStatus(http.StatusBadRequest).
+		CmpJSONBody(td.JSON(`{"errors": [{"field": "FallbackUrl", "reason": "only http and https are supported"}]}`))
+}
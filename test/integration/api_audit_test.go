@@ -0,0 +1,136 @@
+package integration
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/maxatome/go-testdeep/helpers/tdhttp"
+	"github.com/maxatome/go-testdeep/td"
+	"github.com/stretchr/testify/suite"
+)
+
+type auditSuite struct {
+	suite.Suite
+}
+
+func TestAudit(t *testing.T) {
+	suite.Run(t, new(auditSuite))
+}
+
+func (suite *auditSuite) BeforeTest(suiteName, testName string) {
+	TestContext.BeforeTest()
+}
+
+func (suite *auditSuite) TestHistoryRecordsEveryChange() {
+	t := suite.T()
+	testAPI := tdhttp.NewTestAPI(t, TestContext.server)
+
+	alice := TestContext.CreateApiKey("alice", false)
+	bob := TestContext.CreateApiKey("bob", false)
+
+	testAPI.PostJSON(
+		"/api/v1/shorturls",
+		gin.H{"long_url": "https://www.google.com", "slug": "audited"},
+		"Authorization", alice,
+		"X-Request-Id", "create-audited",
+	).
+		CmpStatus(http.StatusCreated).
+		CmpHeader(td.SuperMapOf(http.Header{"X-Request-Id": {"create-audited"}}, nil))
+
+	testAPI.PatchJSON("/api/v1/shorturls/audited", gin.H{"slug": "renamed"}, "Authorization", alice).
+		CmpStatus(http.StatusOK)
+
+	testAPI.Delete("/api/v1/shorturls/renamed", nil, "Authorization", alice).
+		CmpStatus(http.StatusNoContent)
+
+	testAPI.Get("/api/v1/shorturls/renamed/history").
+		CmpStatus(http.StatusUnauthorized)
+
+	testAPI.Get("/api/v1/shorturls/renamed/history", "Authorization", bob).
+		CmpStatus(http.StatusForbidden)
+
+	testAPI.Get("/api/v1/shorturls/renamed/history", "Authorization", alice).
+		CmpStatus(http.StatusOK).
+		CmpJSONBody(
+			td.JSON(
+				`{
+				   "events": [
+					   SuperMapOf({"action": "delete", "slug": "renamed", "before": SuperMapOf({"slug": "renamed"}), "after": null}),
+					   SuperMapOf({
+						   "action": "update",
+							 "before": SuperMapOf({"slug": "audited"}),
+							 "after": SuperMapOf({"slug": "renamed", "owner_id": "alice"})
+						 }),
+					   {
+						   "id": NotZero(),
+							 "created_at": NotEmpty(),
+							 "actor_type": "user",
+							 "actor": "alice",
+							 "action": "create",
+							 "short_url_id": NotZero(),
+							 "slug": "audited",
+							 "before": null,
+							 "after": SuperMapOf({"slug": "audited", "long_url": "https://www.google.com"}),
+							 "request_id": "create-audited",
+							 "ip_address": "192.0.2.1"
+						 }
+					 ],
+					 "next_cursor": null
+				 }`,
+			),
+		)
+
+	testAPI.Get("/api/v1/shorturls/unknown/history", "Authorization", alice).
+		CmpStatus(http.StatusNotFound)
+}
+
+func (suite *auditSuite) TestOnlyAdminsCanListAuditLog() {
+	t := suite.T()
+	testAPI := tdhttp.NewTestAPI(t, TestContext.server)
+
+	admin := TestContext.CreateApiKey("root", true)
+	alice := TestContext.CreateApiKey("alice", false)
+
+	for _, longUrl := range []string{"https://www.google.com", "https://www.github.com"} {
+		testAPI.PostJSON("/api/v1/shorturls", gin.H{"long_url": longUrl}, "Authorization", alice).
+			CmpStatus(http.StatusCreated)
+	}
+
+	testAPI.Get("/api/v1/audit").
+		CmpStatus(http.StatusUnauthorized)
+
+	testAPI.Get("/api/v1/audit", "Authorization", alice).
+		CmpStatus(http.StatusForbidden).
+		CmpJSONBody(
+			td.JSON(`{"errors": [{"field": "Authorization", "reason": "not allowed to read this audit log"}]}`),
+		)
+
+	var nextCursor string
+
+	testAPI.Get("/api/v1/audit?actor=alice&action=create&limit=1", "Authorization", admin).
+		CmpStatus(http.StatusOK).
+		CmpJSONBody(
+			td.JSON(
+				`{
+				   "events": [SuperMapOf({"actor": "alice", "after": SuperMapOf({"long_url": "https://www.github.com"})})],
+					 "next_cursor": $nextCursor
+				 }`,
+				td.Tag("nextCursor", td.Catch(&nextCursor, td.NotEmpty())),
+			),
+		)
+
+	testAPI.Get("/api/v1/audit?actor=alice&action=create&limit=1&cursor="+nextCursor, "Authorization", admin).
+		CmpStatus(http.StatusOK).
+		CmpJSONBody(
+			td.JSON(
+				`{
+				   "events": [SuperMapOf({"actor": "alice", "after": SuperMapOf({"long_url": "https://www.google.com"})})],
+					 "next_cursor": null
+				 }`,
+			),
+		)
+
+	testAPI.Get("/api/v1/audit?action=rename", "Authorization", admin).
+		CmpStatus(http.StatusBadRequest)
+}
@@ -0,0 +1,161 @@
+package integration
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/maxatome/go-testdeep/helpers/tdhttp"
+	"github.com/maxatome/go-testdeep/td"
+	"github.com/stretchr/testify/suite"
+)
+
+type authSuite struct {
+	suite.Suite
+}
+
+func TestAuth(t *testing.T) {
+	suite.Run(t, new(authSuite))
+}
+
+func (suite *authSuite) BeforeTest(suiteName, testName string) {
+	TestContext.BeforeTest()
+}
+
+func (suite *authSuite) createOwnedShortUrl(testAPI *tdhttp.TestAPI, authorization string) string {
+	var slug string
+
+	testAPI.PostJSON(
+		"/api/v1/shorturls",
+		gin.H{"long_url": "https://www.google.com"},
+		"Authorization", authorization,
+	).
+		CmpStatus(http.StatusCreated).
+		CmpJSONBody(
+			td.SuperJSONOf(
+				`{"slug": "$slug"}`,
+				td.Tag("slug", td.Catch(&slug, td.Ignore())),
+			),
+		)
+
+	return slug
+}
+
+func (suite *authSuite) TestInvalidApiKeyReturns401() {
+	t := suite.T()
+	testAPI := tdhttp.NewTestAPI(t, TestContext.server)
+
+	testAPI.Get("/api/v1/shorturls", "Authorization", "Bearer usk_invalid").
+		CmpStatus(http.StatusUnauthorized).
+		CmpHeader(td.SuperMapOf(http.Header{"Www-Authenticate": {"Bearer"}}, nil)).
+		CmpJSONBody(
+			td.JSON(`{"errors": [{"field": "Authorization", "reason": "invalid api key"}]}`),
+		)
+}
+
+func (suite *authSuite) TestOnlyOwnerCanDeleteShortUrl() {
+	t := suite.T()
+	testAPI := tdhttp.NewTestAPI(t, TestContext.server)
+
+	alice := TestContext.CreateApiKey("alice", false)
+	bob := TestContext.CreateApiKey("bob", false)
+
+	slug := suite.createOwnedShortUrl(testAPI, alice)
+	path := fmt.Sprintf("/api/v1/shorturls/%s", slug)
+
+	testAPI.Delete(path, nil).
+		CmpStatus(http.StatusForbidden).
+		CmpJSONBody(
+			td.JSON(`{"errors": [{"field": "Authorization", "reason": "not allowed to modify this short url"}]}`),
+		)
+
+	testAPI.Delete(path, nil, "Authorization", bob).
+		CmpStatus(http.StatusForbidden)
+
+	testAPI.Delete(path, nil, "Authorization", alice).
+		CmpStatus(http.StatusNoContent)
+}
+
+func (suite *authSuite) TestOnlyOwnerCanUpdateShortUrl() {
+	t := suite.T()
+	testAPI := tdhttp.NewTestAPI(t, TestContext.server)
+
+	alice := TestContext.CreateApiKey("alice", false)
+	bob := TestContext.CreateApiKey("bob", false)
+
+	slug := suite.createOwnedShortUrl(testAPI, alice)
+	path := fmt.Sprintf("/api/v1/shorturls/%s", slug)
+
+	testAPI.PatchJSON(path, gin.H{"slug": "bobs-slug"}, "Authorization", bob).
+		CmpStatus(http.StatusForbidden)
+
+	testAPI.PatchJSON(path, gin.H{"slug": "alices-slug"}, "Authorization", alice).
+		CmpStatus(http.StatusOK).
+		CmpJSONBody(td.SuperJSONOf(`{"slug": "alices-slug"}`))
+}
+
+func (suite *authSuite) TestAdminCanDeleteAnyShortUrl() {
+	t := suite.T()
+	testAPI := tdhttp.NewTestAPI(t, TestContext.server)
+
+	alice := TestContext.CreateApiKey("alice", false)
+	admin := TestContext.CreateApiKey("admins", true)
+
+	slug := suite.createOwnedShortUrl(testAPI, alice)
+
+	testAPI.Delete(fmt.Sprintf("/api/v1/shorturls/%s", slug), nil, "Authorization", admin).
+		CmpStatus(http.StatusNoContent)
+}
+
+func (suite *authSuite) TestListOwnedByMe() {
+	t := suite.T()
+	testAPI := tdhttp.NewTestAPI(t, TestContext.server)
+
+	alice := TestContext.CreateApiKey("alice", false)
+	bob := TestContext.CreateApiKey("bob", false)
+
+	slug := suite.createOwnedShortUrl(testAPI, alice)
+
+	testAPI.PostJSON("/api/v1/shorturls", gin.H{"long_url": "https://www.cloudflare.com"}, "Authorization", bob).
+		CmpStatus(http.StatusCreated)
+
+	testAPI.Get("/api/v1/shorturls?owner=me", "Authorization", alice).
+		CmpStatus(http.StatusOK).
+		CmpJSONBody(
+			td.JSON(`{"short_urls": [SuperMapOf({"slug": $1})], "next_cursor": null}`, slug),
+		)
+
+	testAPI.Get("/api/v1/shorturls?owner=me").
+		CmpStatus(http.StatusUnauthorized)
+}
+
+func (suite *authSuite) TestCreateApiKeyRequiresAdmin() {
+	t := suite.T()
+	testAPI := tdhttp.NewTestAPI(t, TestContext.server)
+
+	alice := TestContext.CreateApiKey("alice", false)
+	admin := TestContext.CreateApiKey("admins", true)
+
+	body := gin.H{"name": "ci", "owner_id": "carol"}
+
+	testAPI.PostJSON("/api/v1/apikeys", body).
+		CmpStatus(http.StatusUnauthorized)
+
+	testAPI.PostJSON("/api/v1/apikeys", body, "Authorization", alice).
+		CmpStatus(http.StatusForbidden)
+
+	var key string
+
+	testAPI.PostJSON("/api/v1/apikeys", body, "Authorization", admin).
+		CmpStatus(http.StatusCreated).
+		CmpJSONBody(
+			td.SuperJSONOf(
+				`{"key": "$key", "name": "ci", "owner_id": "carol", "admin": false}`,
+				td.Tag("key", td.Catch(&key, td.HasPrefix("usk_"))),
+			),
+		)
+
+	testAPI.Get("/api/v1/shorturls?owner=me", "Authorization", "Bearer "+key).
+		CmpStatus(http.StatusOK)
+}
@@ -0,0 +1,154 @@
+package integration
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/maxatome/go-testdeep/helpers/tdhttp"
+	"github.com/maxatome/go-testdeep/td"
+	"github.com/stretchr/testify/suite"
+)
+
+type batchSuite struct {
+	suite.Suite
+}
+
+func TestBatch(t *testing.T) {
+	suite.Run(t, new(batchSuite))
+}
+
+func (suite *batchSuite) BeforeTest(suiteName, testName string) {
+	TestContext.BeforeTest()
+}
+
+func (suite *batchSuite) TestBestEffortBatchCreateReturnsResultPerItem() {
+	t := suite.T()
+	testAPI := tdhttp.NewTestAPI(t, TestContext.server)
+
+	testAPI.PostJSON("/api/v1/shorturls", gin.H{"long_url": "https://www.google.com", "slug": "google"}).
+		CmpStatus(http.StatusCreated)
+
+	testAPI.PostJSON("/api/v1/shorturls:batch", gin.H{
+		"mode": "best_effort",
+		"short_urls": []gin.H{
+			{"long_url": "https://www.cloudflare.com", "slug": "cloudflare"},
+			{"long_url": "https://www.google.com"},
+			{"long_url": "https://www.github.com", "slug": "google"},
+			{"long_url": "ftp://files.example.com"},
+			{"slug": "missing-long-url"},
+		},
+	}).
+		CmpStatus(http.StatusOK).
+		CmpJSONBody(
+			td.JSON(
+				`{
+				   "committed": true,
+				   "results": [
+					   {"status": "created", "short_url": SuperMapOf({"slug": "cloudflare"})},
+					   {"status": "already_exists", "short_url": SuperMapOf({"slug": "google"})},
+					   {"status": "duplicate_slug", "errors": [{"field": "Slug", "reason": "must be unique"}]},
+					   {"status": "invalid", "errors": [{"field": "LongUrl", "reason": "only http and https are supported"}]},
+					   {"status": "invalid", "errors": [{"field": "LongUrl", "reason": "required"}]}
+				   ]
+				 }`,
+			),
+		)
+
+	testAPI.Get("/api/v1/shorturls/cloudflare").CmpStatus(http.StatusOK)
+}
+
+func (suite *batchSuite) TestAtomicBatchCreateRollsBackOnFailure() {
+	t := suite.T()
+	testAPI := tdhttp.NewTestAPI(t, TestContext.server)
+
+	testAPI.PostJSON("/api/v1/shorturls:batch", gin.H{
+		"short_urls": []gin.H{
+			{"long_url": "https://www.cloudflare.com", "slug": "taken"},
+			{"long_url": "https://www.github.com", "slug": "taken"},
+		},
+	}).
+		CmpStatus(http.StatusUnprocessableEntity).
+		CmpJSONBody(
+			td.JSON(
+				`{
+				   "committed": false,
+				   "results": [
+					   {"status": "aborted"},
+					   {"status": "duplicate_slug", "errors": [{"field": "Slug", "reason": "must be unique"}]}
+				   ]
+				 }`,
+			),
+		)
+
+	testAPI.Get("/api/v1/shorturls/taken").CmpStatus(http.StatusNotFound)
+}
+
+func (suite *batchSuite) TestAtomicBatchCreateCommitsWhenEveryItemSucceeds() {
+	t := suite.T()
+	testAPI := tdhttp.NewTestAPI(t, TestContext.server)
+
+	testAPI.PostJSON("/api/v1/shorturls:batch", gin.H{
+		"mode": "atomic",
+		"short_urls": []gin.H{
+			{"long_url": "https://www.cloudflare.com", "slug": "cloudflare"},
+			{"long_url": "https://www.github.com", "slug": "github"},
+		},
+	}).
+		CmpStatus(http.StatusOK).
+		CmpJSONBody(td.SuperJSONOf(`{"committed": true}`))
+
+	testAPI.Get("/api/v1/shorturls/cloudflare").CmpStatus(http.StatusOK)
+	testAPI.Get("/api/v1/shorturls/github").CmpStatus(http.StatusOK)
+}
+
+func (suite *batchSuite) TestBatchDelete() {
+	t := suite.T()
+	testAPI := tdhttp.NewTestAPI(t, TestContext.server)
+
+	admin := TestContext.CreateApiKey("admins", true)
+
+	testAPI.PostJSON("/api/v1/shorturls:batch", gin.H{
+		"short_urls": []gin.H{
+			{"long_url": "https://www.cloudflare.com", "slug": "cloudflare"},
+			{"long_url": "https://www.github.com", "slug": "github"},
+		},
+	}).
+		CmpStatus(http.StatusOK)
+
+	testAPI.DeleteJSON("/api/v1/shorturls:batch", gin.H{
+		"slugs": []string{"cloudflare", "missing"},
+	}, "Authorization", admin).
+		CmpStatus(http.StatusUnprocessableEntity).
+		CmpJSONBody(
+			td.JSON(
+				`{
+				   "committed": false,
+				   "results": [
+					   {"slug": "cloudflare", "status": "aborted"},
+					   {"slug": "missing", "status": "not_found"}
+				   ]
+				 }`,
+			),
+		)
+
+	testAPI.Get("/api/v1/shorturls/cloudflare").CmpStatus(http.StatusOK)
+
+	testAPI.DeleteJSON("/api/v1/shorturls:batch", gin.H{
+		"slugs": []string{"cloudflare", "missing", "github"},
+		"mode":  "best_effort",
+	}, "Authorization", admin).
+		CmpStatus(http.StatusOK).
+		CmpJSONBody(
+			td.JSON(
+				`{
+				   "committed": true,
+				   "results": [
+					   {"slug": "cloudflare", "status": "deleted"},
+					   {"slug": "missing", "status": "not_found"},
+					   {"slug": "github", "status": "deleted"}
+				   ]
+				 }`,
+			),
+		)
+}
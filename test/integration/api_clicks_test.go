@@ -3,7 +3,9 @@ package integration
 import (
 	"fmt"
 	"net/http"
+	"net/url"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/maxatome/go-testdeep/helpers/tdhttp"
@@ -75,3 +77,300 @@ func (suite *clicksSuite) TestGetClicksWithInvalidSlugReturns404() {
 			),
 		)
 }
+
+func (suite *clicksSuite) TestGetReferrersWithValidSlugReturns200() {
+	t := suite.T()
+
+	testServer := TestContext.server
+	testAPI := tdhttp.NewTestAPI(t, testServer)
+
+	var slug string
+
+	testAPI.PostJSON("/api/v1/shorturls", gin.H{"long_url": "https://www.cloudflare.com"}).
+		CmpStatus(http.StatusCreated).
+		CmpJSONBody(
+			td.SuperJSONOf(`{"slug": "$slug"}`, td.Tag("slug", td.Catch(&slug, td.Ignore()))),
+		)
+
+	referrers := []string{"https://slack.com", "https://slack.com", "https://mail.google.com", ""}
+
+	for _, referrer := range referrers {
+		testAPI.Get(fmt.Sprintf("/%s", slug), "Referer", referrer).
+			CmpStatus(http.StatusMovedPermanently)
+	}
+
+	testAPI.Get(fmt.Sprintf("/api/v1/shorturls/%s/clicks/referrers?time_period=ALL_TIME", slug)).
+		CmpStatus(http.StatusOK).
+		CmpJSONBody(
+			td.JSON(
+				`{
+				   "time_period": "ALL_TIME",
+				   "breakdown": [
+					   {"value": "https://slack.com", "count": 2},
+					   {"value": "", "count": 1},
+					   {"value": "https://mail.google.com", "count": 1}
+				   ]
+				 }`,
+			),
+		)
+}
+
+func (suite *clicksSuite) TestGetCampaignsWithValidSlugReturns200() {
+	t := suite.T()
+
+	testServer := TestContext.server
+	testAPI := tdhttp.NewTestAPI(t, testServer)
+
+	admin := TestContext.CreateApiKey("admins", true)
+
+	testAPI.PostJSON("/api/v1/shorturls", gin.H{
+		"long_url":          "https://www.cloudflare.com",
+		"slug":              "cloudflare",
+		"utm_campaign":      "spring",
+		"query_passthrough": "prefer_incoming",
+	}).
+		CmpStatus(http.StatusCreated)
+
+	for _, path := range []string{"/cloudflare", "/cloudflare", "/cloudflare?utm_campaign=slack"} {
+		testAPI.Get(path).
+			CmpStatus(http.StatusMovedPermanently)
+	}
+
+	testAPI.PatchJSON("/api/v1/shorturls/cloudflare", gin.H{"utm_campaign": ""}, "Authorization", admin).
+		CmpStatus(http.StatusOK)
+
+	testAPI.Get("/cloudflare").
+		CmpStatus(http.StatusMovedPermanently)
+
+	testAPI.Get("/api/v1/shorturls/cloudflare/clicks/campaigns?time_period=ALL_TIME").
+		CmpStatus(http.StatusOK).
+		CmpJSONBody(
+			td.JSON(
+				`{
+				   "time_period": "ALL_TIME",
+				   "breakdown": [
+					   {"value": "spring", "count": 2},
+					   {"value": "", "count": 1},
+					   {"value": "slack", "count": 1}
+				   ]
+				 }`,
+			),
+		)
+}
+
+func (suite *clicksSuite) TestGetUserAgentsWithoutClicksReturnsEmptyBreakdown() {
+	t := suite.T()
+
+	testServer := TestContext.server
+	testAPI := tdhttp.NewTestAPI(t, testServer)
+
+	var slug string
+
+	testAPI.PostJSON("/api/v1/shorturls", gin.H{"long_url": "https://www.cloudflare.com"}).
+		CmpStatus(http.StatusCreated).
+		CmpJSONBody(
+			td.SuperJSONOf(`{"slug": "$slug"}`, td.Tag("slug", td.Catch(&slug, td.Ignore()))),
+		)
+
+	testAPI.Get(fmt.Sprintf("/api/v1/shorturls/%s/clicks/user-agents?time_period=24_HOURS", slug)).
+		CmpStatus(http.StatusOK).
+		CmpJSONBody(td.JSON(`{"time_period": "24_HOURS", "breakdown": []}`))
+}
+
+func (suite *clicksSuite) TestGetLanguagesWithInvalidSlugReturns404() {
+	t := suite.T()
+
+	testServer := TestContext.server
+	testAPI := tdhttp.NewTestAPI(t, testServer)
+
+	testAPI.Get("/api/v1/shorturls/invalid/clicks/languages?time_period=ALL_TIME").
+		CmpStatus(http.StatusNotFound).
+		CmpJSONBody(
+			td.JSON(
+				`{
+				   "errors": [
+					   {
+						   "field": "Slug",
+							 "reason": "not found"
+						 }
+					 ]
+				 }`,
+			),
+		)
+}
+
+func (suite *clicksSuite) TestGetSeriesWithValidSlugReturns200() {
+	t := suite.T()
+
+	testServer := TestContext.server
+	testAPI := tdhttp.NewTestAPI(t, testServer)
+
+	var slug string
+
+	testAPI.PostJSON("/api/v1/shorturls", gin.H{"long_url": "https://www.cloudflare.com"}).
+		CmpStatus(http.StatusCreated).
+		CmpJSONBody(
+			td.SuperJSONOf(`{"slug": "$slug"}`, td.Tag("slug", td.Catch(&slug, td.Ignore()))),
+		)
+
+	for i := 0; i < 3; i++ {
+		testAPI.Get(fmt.Sprintf("/%s", slug)).
+			CmpStatus(http.StatusMovedPermanently)
+	}
+
+	now := time.Now()
+
+	query := url.Values{
+		"from":     []string{now.Add(-time.Hour * 72).Format(time.RFC3339)},
+		"to":       []string{now.Add(time.Hour).Format(time.RFC3339)},
+		"interval": []string{"day"},
+		"tz":       []string{"America/Chicago"},
+	}
+
+	sumCounts := func(buckets []interface{}) float64 {
+		var total float64
+
+		for _, bucket := range buckets {
+			total += bucket.(map[string]interface{})["count"].(float64)
+		}
+
+		return total
+	}
+
+	testAPI.Get(fmt.Sprintf("/api/v1/shorturls/%s/clicks/series?%s", slug, query.Encode())).
+		CmpStatus(http.StatusOK).
+		CmpJSONBody(
+			td.SuperJSONOf(
+				`{
+				   "interval": "day",
+				   "tz": "America/Chicago",
+				   "buckets": $buckets
+				 }`,
+				td.Tag("buckets", td.All(
+					td.Len(td.Between(4, 5)),
+					td.Smuggle(sumCounts, float64(3)),
+				)),
+			),
+		)
+}
+
+func (suite *clicksSuite) TestGetSeriesWithTooManyBucketsReturns400() {
+	t := suite.T()
+
+	testServer := TestContext.server
+	testAPI := tdhttp.NewTestAPI(t, testServer)
+
+	testAPI.PostJSON("/api/v1/shorturls", gin.H{"long_url": "https://www.cloudflare.com", "slug": "cf"}).
+		CmpStatus(http.StatusCreated)
+
+	testAPI.Get("/api/v1/shorturls/cf/clicks/series?from=2020-01-01T00:00:00Z&to=2022-01-01T00:00:00Z&interval=hour").
+		CmpStatus(http.StatusBadRequest).
+		CmpJSONBody(
+			td.JSON(
+				`{
+				   "errors": [
+					   {
+						   "field": "Interval",
+						   "reason": "range must contain at most 1000 intervals"
+					   }
+				   ]
+				 }`,
+			),
+		)
+}
+
+func (suite *clicksSuite) TestGetSeriesWithInvalidTimeZoneReturns400() {
+	t := suite.T()
+
+	testServer := TestContext.server
+	testAPI := tdhttp.NewTestAPI(t, testServer)
+
+	testAPI.Get("/api/v1/shorturls/cf/clicks/series?from=2022-01-01T00:00:00Z&to=2022-01-02T00:00:00Z&interval=day&tz=Mars/Olympus").
+		CmpStatus(http.StatusBadRequest).
+		CmpJSONBody(
+			td.JSON(`{"errors": [{"field": "TimeZone", "reason": "timezone"}]}`),
+		)
+}
+
+func (suite *clicksSuite) TestGetClicksWithExplicitRangeReturns200() {
+	t := suite.T()
+
+	testServer := TestContext.server
+	testAPI := tdhttp.NewTestAPI(t, testServer)
+
+	testAPI.PostJSON("/api/v1/shorturls", gin.H{"long_url": "https://www.cloudflare.com", "slug": "cf"}).
+		CmpStatus(http.StatusCreated)
+
+	for i := 0; i < 2; i++ {
+		testAPI.Get("/cf").CmpStatus(http.StatusMovedPermanently)
+	}
+
+	from := time.Now().Add(-time.Hour).UTC().Truncate(time.Second)
+	to := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+
+	query := url.Values{
+		"from": []string{from.Format(time.RFC3339)},
+		"to":   []string{to.Format(time.RFC3339)},
+	}
+
+	testAPI.Get(fmt.Sprintf("/api/v1/shorturls/cf/clicks?%s", query.Encode())).
+		CmpStatus(http.StatusOK).
+		CmpJSONBody(
+			td.JSON(
+				`{"count": 2, "from": "$from", "to": "$to"}`,
+				td.Tag("from", td.Smuggle(parseDateTime, from)),
+				td.Tag("to", td.Smuggle(parseDateTime, to)),
+			),
+		)
+
+	testAPI.Get("/api/v1/shorturls/cf/clicks?to=2022-01-01T00:00:00Z").
+		CmpStatus(http.StatusOK).
+		CmpJSONBody(td.JSON(`{"count": 0, "to": "2022-01-01T00:00:00Z"}`))
+}
+
+func (suite *clicksSuite) TestGetClicksWithCalendarTimePeriodReturns200() {
+	t := suite.T()
+
+	testServer := TestContext.server
+	testAPI := tdhttp.NewTestAPI(t, testServer)
+
+	testAPI.PostJSON("/api/v1/shorturls", gin.H{"long_url": "https://www.cloudflare.com", "slug": "cf"}).
+		CmpStatus(http.StatusCreated)
+
+	testAPI.Get("/cf").CmpStatus(http.StatusMovedPermanently)
+
+	chicago, err := time.LoadLocation("America/Chicago")
+	td.Require(t).CmpNoError(err)
+
+	year, month, day := time.Now().In(chicago).Date()
+	startOfToday := time.Date(year, month, day, 0, 0, 0, 0, chicago)
+
+	testAPI.Get("/api/v1/shorturls/cf/clicks?time_period=TODAY&tz=America/Chicago").
+		CmpStatus(http.StatusOK).
+		CmpJSONBody(
+			td.JSON(
+				`{
+				   "count": 1,
+				   "time_period": "TODAY",
+				   "tz": "America/Chicago",
+				   "from": "$from",
+				   "to": "$to"
+				 }`,
+				td.Tag("from", td.Smuggle(parseDateTime, td.TruncTime(startOfToday))),
+				td.Tag("to", td.Smuggle(parseDateTime, td.TruncTime(startOfToday.AddDate(0, 0, 1)))),
+			),
+		)
+}
+
+func (suite *clicksSuite) TestGetClicksWithTimePeriodAndRangeReturns400() {
+	t := suite.T()
+
+	testServer := TestContext.server
+	testAPI := tdhttp.NewTestAPI(t, testServer)
+
+	testAPI.Get("/api/v1/shorturls/cf/clicks?time_period=TODAY&from=2022-01-01T00:00:00Z").
+		CmpStatus(http.StatusBadRequest).
+		CmpJSONBody(
+			td.JSON(`{"errors": [{"field": "TimePeriod", "reason": "excluded_with=From To"}]}`),
+		)
+}
@@ -35,6 +35,7 @@ func (suite *createSuite) TestCreateWithNewShortUrlReturns201() {
 			td.JSON(
 				`{
 				   "short_url": "$shortUrl",
+				   "status": "active",
 					 "slug": "$slug",
 					 "long_url": "$longUrl",
 					 "expires_on": "$expiresOn",
@@ -113,6 +114,7 @@ func (suite *createSuite) TestCreateWithExpirationDateReturns201() {
 			td.JSON(
 				`{
 				   "short_url": "$shortUrl",
+				   "status": "expired",
 					 "slug": "$slug",
 					 "long_url": "$longUrl",
 					 "expires_on": "$expiresOn",
@@ -145,6 +147,7 @@ func (suite *createSuite) TestCreateWithExistingLongUrlReturns200() {
 			td.JSON(
 				`{
 				   "short_url": "$shortUrl",
+				   "status": "active",
 					 "slug": "$slug",
 					 "long_url": "$longUrl",
 					 "expires_on": "$expiresOn",
@@ -165,6 +168,7 @@ func (suite *createSuite) TestCreateWithExistingLongUrlReturns200() {
 			td.JSON(
 				`{
 				   "short_url": "$shortUrl",
+				   "status": "active",
 					 "slug": "$slug",
 					 "long_url": "$longUrl",
 					 "expires_on": "$expiresOn",
@@ -179,6 +183,74 @@ func (suite *createSuite) TestCreateWithExistingLongUrlReturns200() {
 		)
 }
 
+func (suite *createSuite) TestCreateWithExistingLongUrlAndNewCampaignReturns201() {
+	t := suite.T()
+	testServer := TestContext.server
+
+	testAPI := tdhttp.NewTestAPI(t, testServer)
+
+	var slug string
+
+	testAPI.PostJSON("/api/v1/shorturls", gin.H{"long_url": "https://www.cloudflare.com"}).
+		CmpStatus(http.StatusCreated)
+
+	tagged := gin.H{
+		"long_url":     "https://www.cloudflare.com",
+		"utm_source":   "newsletter",
+		"utm_medium":   "email",
+		"utm_campaign": "spring",
+	}
+
+	testAPI.PostJSON("/api/v1/shorturls", tagged).
+		CmpStatus(http.StatusCreated).
+		CmpJSONBody(
+			td.SuperJSONOf(
+				`{
+				   "slug": "$slug",
+				   "long_url": "https://www.cloudflare.com",
+				   "utm_source": "newsletter",
+				   "utm_medium": "email",
+				   "utm_campaign": "spring"
+				 }`,
+				td.Tag("slug", td.Catch(&slug, td.Ignore())),
+			),
+		)
+
+	// Only the same long URL with the same tags already exists.
+	testAPI.PostJSON("/api/v1/shorturls", tagged).
+		CmpStatus(http.StatusOK).
+		CmpJSONBody(td.SuperJSONOf(`{"slug": $1}`, slug))
+}
+
+func (suite *createSuite) TestCreateWithExpiredLongUrlReturns201() {
+	t := suite.T()
+	testServer := TestContext.server
+
+	testAPI := tdhttp.NewTestAPI(t, testServer)
+
+	admin := TestContext.CreateApiKey("admins", true)
+
+	expired := time.Now().Add(-time.Hour).UTC().Format(time.RFC3339)
+
+	testAPI.PostJSON("/api/v1/shorturls", gin.H{"long_url": "https://www.google.com", "slug": "old", "expires_on": expired}).
+		CmpStatus(http.StatusCreated)
+
+	testAPI.PostJSON("/api/v1/shorturls", gin.H{"long_url": "https://www.google.com", "slug": "new"}).
+		CmpStatus(http.StatusCreated).
+		CmpJSONBody(td.SuperJSONOf(`{"slug": "new", "status": "active"}`))
+
+	testAPI.PostJSON("/api/v1/shorturls", gin.H{"long_url": "https://www.google.com"}).
+		CmpStatus(http.StatusOK).
+		CmpJSONBody(td.SuperJSONOf(`{"slug": "new"}`))
+
+	// The expired short URL still answers for its slug.
+	testAPI.Get("/old").
+		CmpStatus(http.StatusGone)
+
+	testAPI.PatchJSON("/api/v1/shorturls/old", gin.H{"expires_on": nil}, "Authorization", admin).
+		CmpStatus(http.StatusConflict)
+}
+
 func (suite *createSuite) TestCreateWithExistingSlugReturns409() {
 	t := suite.T()
 
@@ -244,3 +316,47 @@ func (suite *createSuite) TestCreateWithInvalidJSONReturns400() {
 			),
 		)
 }
+
+func (suite *createSuite) TestCreateWithReservedSlugReturns400() {
+	t := suite.T()
+	testServer := TestContext.server
+
+	testAPI := tdhttp.NewTestAPI(t, testServer)
+
+	testAPI.PostJSON("/api/v1/shorturls", gin.H{"slug": "Swagger", "long_url": "https://www.google.com"}).
+		CmpStatus(http.StatusBadRequest).
+		CmpJSONBody(
+			td.JSON(
+				`{
+				   "errors": [
+					   {
+						   "field": "Slug",
+						   "reason": "is reserved"
+						 }
+					 ],
+				 }`,
+			),
+		)
+}
+
+func (suite *createSuite) TestCreateWithUnroutableSlugReturns400() {
+	t := suite.T()
+	testServer := TestContext.server
+
+	testAPI := tdhttp.NewTestAPI(t, testServer)
+
+	testAPI.PostJSON("/api/v1/shorturls", gin.H{"slug": "what?", "long_url": "https://www.google.com"}).
+		CmpStatus(http.StatusBadRequest).
+		CmpJSONBody(
+			td.JSON(
+				`{
+				   "errors": [
+					   {
+						   "field": "Slug",
+						   "reason": "must only contain letters, digits, -, _, . and /"
+						 }
+					 ],
+				 }`,
+			),
+		)
+}
@@ -29,6 +29,8 @@ func (suite *deleteSuite) TestDeleteWithValidSlugReturns204() {
 	testServer := TestContext.server
 	testAPI := tdhttp.NewTestAPI(t, testServer)
 
+	admin := TestContext.CreateApiKey("admins", true)
+
 	var slug string
 
 	testAPI.PostJSON("/api/v1/shorturls", gin.H{"long_url": "https://www.google.com"}).
@@ -41,7 +43,7 @@ func (suite *deleteSuite) TestDeleteWithValidSlugReturns204() {
 			),
 		)
 
-	testAPI.Delete(fmt.Sprintf("/api/v1/shorturls/%s", slug), nil).
+	testAPI.Delete(fmt.Sprintf("/api/v1/shorturls/%s", slug), nil, "Authorization", admin).
 		CmpStatus(http.StatusNoContent)
 
 	testAPI.Get(fmt.Sprintf("/%s", slug)).CmpStatus(http.StatusNotFound)
@@ -41,6 +41,7 @@ func (suite *getSuite) TestGetWithValidShortUrlReturns200() {
 			td.JSON(
 				`{
 				   "short_url": "$shortUrl",
+				   "status": "active",
 					 "slug": "$slug",
 					 "long_url": "$longUrl",
 					 "expires_on": "$expiresOn",
@@ -60,6 +61,7 @@ func (suite *getSuite) TestGetWithValidShortUrlReturns200() {
 			td.JSON(
 				`{
 				   "short_url": "$shortUrl",
+				   "status": "active",
 					 "slug": "$slug",
 					 "long_url": "$longUrl",
 					 "expires_on": "$expiresOn",
@@ -0,0 +1,197 @@
+package integration
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/maxatome/go-testdeep/helpers/tdhttp"
+	"github.com/maxatome/go-testdeep/td"
+	"github.com/stretchr/testify/suite"
+)
+
+type importExportSuite struct {
+	suite.Suite
+}
+
+func TestImportExport(t *testing.T) {
+	suite.Run(t, new(importExportSuite))
+}
+
+func (suite *importExportSuite) BeforeTest(suiteName, testName string) {
+	TestContext.BeforeTest()
+}
+
+const importCsv = `slug,long_url,expires_on,created_at,owner_id,clicks,redirect_type,cache_control,cache_max_age,query_passthrough,path_passthrough,utm_source,utm_medium,utm_campaign,link_type,fallback_url
+google,https://www.google.com,,2022-05-11T11:30:00Z,,3,,,,prefer_incoming,true,,,,,
+cloudflare,https://www.cloudflare.com,2030-01-01T00:00:00Z,2022-05-12T08:00:00Z,,0,307,public,3600,,,newsletter,,spring,,https://www.cloudflare.com/expired
+jira,https://jira.example.com/browse/{ticket},,2022-05-13T09:00:00Z,,0,,,,,,,,,template,
+`
+
+func (suite *importExportSuite) TestExportRoundTripsThroughImport() {
+	t := suite.T()
+	testAPI := tdhttp.NewTestAPI(t, TestContext.server)
+
+	admin := TestContext.CreateApiKey("admins", true)
+
+	testAPI.Post("/api/v1/shorturls/import", strings.NewReader(importCsv)).
+		CmpStatus(http.StatusOK).
+		CmpJSONBody(
+			td.JSON(`{"dry_run": false, "committed": true, "created": 3, "updated": 0, "skipped": 0, "errors": []}`),
+		)
+
+	testAPI.Get("/api/v1/shorturls/export", "Authorization", admin).
+		CmpStatus(http.StatusOK).
+		CmpHeader(td.SuperMapOf(http.Header{"Content-Type": []string{"text/csv"}}, nil)).
+		CmpBody(importCsv)
+
+	testAPI.Get("/api/v1/shorturls/export?format=jsonl", "Authorization", admin).
+		CmpStatus(http.StatusOK).
+		CmpBody(
+			`{"slug":"google","long_url":"https://www.google.com","expires_on":null,"created_at":"2022-05-11T11:30:00Z","owner_id":"","clicks":3,"query_passthrough":"prefer_incoming","path_passthrough":true}` + "\n" +
+				`{"slug":"cloudflare","long_url":"https://www.cloudflare.com","expires_on":"2030-01-01T00:00:00Z","created_at":"2022-05-12T08:00:00Z","owner_id":"","clicks":0,"redirect_type":307,"cache_control":"public","cache_max_age":3600,"utm_source":"newsletter","utm_campaign":"spring","fallback_url":"https://www.cloudflare.com/expired"}` + "\n" +
+				`{"slug":"jira","long_url":"https://jira.example.com/browse/{ticket}","expires_on":null,"created_at":"2022-05-13T09:00:00Z","owner_id":"","clicks":0,"link_type":"template"}` + "\n",
+		)
+}
+
+func (suite *importExportSuite) TestOnlyAdminsExportAndImportOtherOwners() {
+	t := suite.T()
+	testAPI := tdhttp.NewTestAPI(t, TestContext.server)
+
+	admin := TestContext.CreateApiKey("admins", true)
+	alice := TestContext.CreateApiKey("alice", false)
+
+	body := func(slug string) *strings.Reader {
+		return strings.NewReader(`{"slug":"` + slug + `","long_url":"https://www.example.com/` + slug + `","owner_id":"bob"}` + "\n")
+	}
+
+	testAPI.Post("/api/v1/shorturls/import?format=jsonl", body("bobs"), "Authorization", admin).
+		CmpStatus(http.StatusOK)
+
+	testAPI.Post("/api/v1/shorturls/import?format=jsonl", body("alices"), "Authorization", alice).
+		CmpStatus(http.StatusOK)
+
+	testAPI.Post("/api/v1/shorturls/import?format=jsonl", body("anonymous")).
+		CmpStatus(http.StatusOK)
+
+	testAPI.Get("/api/v1/shorturls/export?format=jsonl").
+		CmpStatus(http.StatusUnauthorized)
+
+	testAPI.Get("/api/v1/shorturls/export?format=jsonl", "Authorization", alice).
+		CmpStatus(http.StatusOK).
+		CmpBody(td.Re(`^\{"slug":"alices",[^\n]*"owner_id":"alice",[^\n]*\}\n$`))
+
+	testAPI.Get("/api/v1/shorturls/export?format=jsonl", "Authorization", admin).
+		CmpStatus(http.StatusOK).
+		CmpBody(td.Re(`^\{"slug":"bobs",[^\n]*"owner_id":"bob",[^\n]*\}\n` +
+			`\{"slug":"alices",[^\n]*"owner_id":"alice",[^\n]*\}\n` +
+			`\{"slug":"anonymous",[^\n]*"owner_id":"",[^\n]*\}\n$`))
+}
+
+func (suite *importExportSuite) TestDryRunImportKeepsNothing() {
+	t := suite.T()
+	testAPI := tdhttp.NewTestAPI(t, TestContext.server)
+
+	testAPI.Post("/api/v1/shorturls/import?dry_run=true", strings.NewReader(importCsv)).
+		CmpStatus(http.StatusOK).
+		CmpJSONBody(td.SuperJSONOf(`{"dry_run": true, "committed": false, "created": 3}`))
+
+	testAPI.Get("/api/v1/shorturls/google").CmpStatus(http.StatusNotFound)
+}
+
+func (suite *importExportSuite) TestImportConflictStrategies() {
+	t := suite.T()
+	testAPI := tdhttp.NewTestAPI(t, TestContext.server)
+
+	admin := TestContext.CreateApiKey("admins", true)
+
+	testAPI.PostJSON("/api/v1/shorturls", gin.H{"long_url": "https://www.github.com", "slug": "google"}).
+		CmpStatus(http.StatusCreated)
+
+	testAPI.Post("/api/v1/shorturls/import", strings.NewReader(importCsv)).
+		CmpStatus(http.StatusConflict).
+		CmpJSONBody(
+			td.SuperJSONOf(`{
+			   "committed": false,
+			   "errors": [{"line": 2, "slug": "google", "reason": "slug is already used by another short url"}]
+			 }`),
+		)
+
+	testAPI.Get("/api/v1/shorturls/cloudflare").CmpStatus(http.StatusNotFound)
+
+	testAPI.Post("/api/v1/shorturls/import?on_conflict=skip", strings.NewReader(importCsv)).
+		CmpStatus(http.StatusOK).
+		CmpJSONBody(td.SuperJSONOf(`{"committed": true, "created": 2, "skipped": 1}`))
+
+	testAPI.Get("/api/v1/shorturls/google").
+		CmpStatus(http.StatusOK).
+		CmpJSONBody(td.SuperJSONOf(`{"long_url": "https://www.github.com"}`))
+
+	testAPI.Post("/api/v1/shorturls/import?on_conflict=overwrite", strings.NewReader(importCsv), "Authorization", admin).
+		CmpStatus(http.StatusOK).
+		CmpJSONBody(td.SuperJSONOf(`{"committed": true, "updated": 3}`))
+
+	testAPI.Get("/api/v1/shorturls/google").
+		CmpStatus(http.StatusOK).
+		CmpJSONBody(td.SuperJSONOf(`{"long_url": "https://www.google.com"}`))
+}
+
+func (suite *importExportSuite) TestImportOverTrashedSlugReportsRow() {
+	t := suite.T()
+	testAPI := tdhttp.NewTestAPI(t, TestContext.server)
+
+	admin := TestContext.CreateApiKey("admins", true)
+
+	testAPI.PostJSON("/api/v1/shorturls", gin.H{"long_url": "https://www.github.com", "slug": "google"}).
+		CmpStatus(http.StatusCreated)
+
+	testAPI.Delete("/api/v1/shorturls/google", nil, "Authorization", admin).
+		CmpStatus(http.StatusNoContent)
+
+	testAPI.Post("/api/v1/shorturls/import?on_conflict=overwrite", strings.NewReader(importCsv), "Authorization", admin).
+		CmpStatus(http.StatusUnprocessableEntity).
+		CmpJSONBody(
+			td.SuperJSONOf(`{
+			   "committed": false,
+			   "errors": [{"line": 2, "slug": "google", "reason": "slug is used by a short url in the trash"}]
+			 }`),
+		)
+
+	testAPI.Post("/api/v1/shorturls/google/restore", nil, "Authorization", admin).
+		CmpStatus(http.StatusOK).
+		CmpJSONBody(td.SuperJSONOf(`{"long_url": "https://www.github.com"}`))
+}
+
+func (suite *importExportSuite) TestImportReportsInvalidRows() {
+	t := suite.T()
+	testAPI := tdhttp.NewTestAPI(t, TestContext.server)
+
+	body := `{"slug":"google","long_url":"https://www.google.com"}
+
+{"slug":"ftp","long_url":"ftp://files.example.com"}
+{"slug":"moved","long_url":"https://www.example.com","redirect_type":200}
+{"slug":"jira","long_url":"https://{host}/browse/{ticket}","link_type":"template"}
+{"slug":"gone","long_url":"https://www.example.com","fallback_url":"mailto:admin@example.com"}
+`
+
+	testAPI.Post("/api/v1/shorturls/import?format=jsonl", strings.NewReader(body)).
+		CmpStatus(http.StatusUnprocessableEntity).
+		CmpJSONBody(
+			td.JSON(`{
+			   "dry_run": false,
+			   "committed": false,
+			   "created": 1,
+			   "updated": 0,
+			   "skipped": 0,
+			   "errors": [
+			     {"line": 3, "slug": "ftp", "reason": "long_url must be an http or https url"},
+			     {"line": 4, "slug": "moved", "reason": "redirect_type must be one of 301, 302, 307 or 308"},
+			     {"line": 5, "slug": "jira", "reason": "long_url must be an http or https url with placeholders only in its path, query string, or fragment"},
+			     {"line": 6, "slug": "gone", "reason": "fallback_url must be an http or https url"}
+			   ]
+			 }`),
+		)
+
+	testAPI.Get("/api/v1/shorturls/google").CmpStatus(http.StatusNotFound)
+}
@@ -40,6 +40,7 @@ func (suite *listSuite) TestListReturns200() {
 			td.JSON(
 				`{
 				   "short_url": "$shortUrl",
+				   "status": "active",
 					 "slug": "$slug",
 					 "long_url": "$longUrl",
 					 "expires_on": "$expiresOn",
@@ -57,15 +58,19 @@ func (suite *listSuite) TestListReturns200() {
 		CmpStatus(http.StatusOK).
 		CmpJSONBody(
 			td.JSON(
-				`[
-				   {
-						 "short_url": "$shortUrl",
-						 "slug": "$slug",
-						 "long_url": "$longUrl",
-						 "expires_on": "$expiresOn",
-						 "created_at": "$createdAt"
-					 }
-				 ]`,
+				`{
+				   "short_urls": [
+					   {
+						   "short_url": "$shortUrl",
+						   "status": "active",
+						   "slug": "$slug",
+						   "long_url": "$longUrl",
+						   "expires_on": "$expiresOn",
+						   "created_at": "$createdAt"
+					   }
+				   ],
+				   "next_cursor": null
+				 }`,
 				td.Tag("shortUrl", shortUrl),
 				td.Tag("slug", slug),
 				td.Tag("longUrl", longUrl),
@@ -74,3 +79,128 @@ func (suite *listSuite) TestListReturns200() {
 			),
 		)
 }
+
+func (suite *listSuite) createShortUrls(testAPI *tdhttp.TestAPI, shortUrls ...gin.H) {
+	for _, shortUrl := range shortUrls {
+		testAPI.PostJSON("/api/v1/shorturls", shortUrl).
+			CmpStatus(http.StatusCreated)
+	}
+}
+
+func (suite *listSuite) TestListPaginatesWithCursor() {
+	t := suite.T()
+	testAPI := tdhttp.NewTestAPI(t, TestContext.server)
+
+	suite.createShortUrls(
+		testAPI,
+		gin.H{"long_url": "https://www.google.com", "slug": "a"},
+		gin.H{"long_url": "https://www.cloudflare.com", "slug": "b"},
+		gin.H{"long_url": "https://www.github.com", "slug": "c"},
+	)
+
+	var nextCursor string
+
+	testAPI.Get("/api/v1/shorturls?limit=2").
+		CmpStatus(http.StatusOK).
+		CmpJSONBody(
+			td.JSON(
+				`{
+				   "short_urls": [
+					   SuperMapOf({"slug": "a"}),
+					   SuperMapOf({"slug": "b"})
+				   ],
+				   "next_cursor": "$nextCursor"
+				 }`,
+				td.Tag("nextCursor", td.Catch(&nextCursor, td.NotEmpty())),
+			),
+		)
+
+	testAPI.Get("/api/v1/shorturls?limit=2&cursor=" + nextCursor).
+		CmpStatus(http.StatusOK).
+		CmpJSONBody(
+			td.JSON(`{"short_urls": [SuperMapOf({"slug": "c"})], "next_cursor": null}`),
+		)
+
+	testAPI.Get("/api/v1/shorturls?limit=2&sort=slug&cursor=" + nextCursor).
+		CmpStatus(http.StatusBadRequest).
+		CmpJSONBody(
+			td.JSON(`{"errors": [{"field": "Cursor", "reason": "invalid"}]}`),
+		)
+}
+
+func (suite *listSuite) TestListFilters() {
+	t := suite.T()
+	testAPI := tdhttp.NewTestAPI(t, TestContext.server)
+
+	suite.createShortUrls(
+		testAPI,
+		gin.H{"long_url": "https://www.google.com", "slug": "team_search"},
+		gin.H{"long_url": "https://www.cloudflare.com", "slug": "teamcdn", "expires_on": "2099-01-01T00:00:00Z"},
+		gin.H{"long_url": "https://docs.google.com", "slug": "docs"},
+	)
+
+	slugs := func(expected ...string) td.TestDeep {
+		items := []interface{}{}
+
+		for _, slug := range expected {
+			items = append(items, td.SuperJSONOf(`{"slug": $1}`, slug))
+		}
+
+		return td.SuperJSONOf(`{"short_urls": $1}`, td.Bag(items...))
+	}
+
+	testAPI.Get("/api/v1/shorturls?slug_prefix=team_").
+		CmpStatus(http.StatusOK).
+		CmpJSONBody(slugs("team_search"))
+
+	testAPI.Get("/api/v1/shorturls?long_url_contains=GOOGLE").
+		CmpStatus(http.StatusOK).
+		CmpJSONBody(slugs("team_search", "docs"))
+
+	testAPI.Get("/api/v1/shorturls?has_expiry=true").
+		CmpStatus(http.StatusOK).
+		CmpJSONBody(slugs("teamcdn"))
+
+	testAPI.Get("/api/v1/shorturls?has_expiry=false").
+		CmpStatus(http.StatusOK).
+		CmpJSONBody(slugs("team_search", "docs"))
+
+	testAPI.Get("/api/v1/shorturls?expires_before=2100-01-01T00:00:00Z").
+		CmpStatus(http.StatusOK).
+		CmpJSONBody(slugs("teamcdn"))
+
+	testAPI.Get("/api/v1/shorturls?created_after=2000-01-01T00:00:00Z&created_before=2001-01-01T00:00:00Z").
+		CmpStatus(http.StatusOK).
+		CmpJSONBody(slugs())
+}
+
+func (suite *listSuite) TestListSortsByClicks() {
+	t := suite.T()
+	testAPI := tdhttp.NewTestAPI(t, TestContext.server)
+
+	suite.createShortUrls(
+		testAPI,
+		gin.H{"long_url": "https://www.google.com", "slug": "once"},
+		gin.H{"long_url": "https://www.cloudflare.com", "slug": "twice"},
+		gin.H{"long_url": "https://www.github.com", "slug": "never"},
+	)
+
+	testAPI.Get("/once").CmpStatus(http.StatusMovedPermanently)
+	testAPI.Get("/twice").CmpStatus(http.StatusMovedPermanently)
+	testAPI.Get("/twice").CmpStatus(http.StatusMovedPermanently)
+
+	testAPI.Get("/api/v1/shorturls?sort=clicks&order=desc").
+		CmpStatus(http.StatusOK).
+		CmpJSONBody(
+			td.JSON(
+				`{
+				   "short_urls": [
+					   SuperMapOf({"slug": "twice"}),
+					   SuperMapOf({"slug": "once"}),
+					   SuperMapOf({"slug": "never"})
+				   ],
+				   "next_cursor": null
+				 }`,
+			),
+		)
+}
@@ -0,0 +1,216 @@
+package integration
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/maxatome/go-testdeep/helpers/tdhttp"
+	"github.com/maxatome/go-testdeep/td"
+	"github.com/stretchr/testify/suite"
+)
+
+type namespacesSuite struct {
+	suite.Suite
+}
+
+func TestNamespaces(t *testing.T) {
+	suite.Run(t, new(namespacesSuite))
+}
+
+func (suite *namespacesSuite) BeforeTest(suiteName, testName string) {
+	TestContext.BeforeTest()
+}
+
+func (suite *namespacesSuite) createNamespace(testAPI *tdhttp.TestAPI, prefix string, ownerId string) {
+	testAPI.PostJSON(
+		"/api/v1/namespaces",
+		gin.H{"prefix": prefix, "owner_id": ownerId},
+		"Authorization", TestContext.CreateApiKey("admin", true),
+	).
+		CmpStatus(http.StatusCreated).
+		CmpJSONBody(
+			td.JSON(
+				`{"prefix": $1, "owner_id": $2, "created_at": $3}`,
+				prefix,
+				ownerId,
+				td.Ignore(),
+			),
+		)
+}
+
+func (suite *namespacesSuite) TestOnlyAdminsCanCreateNamespaces() {
+	t := suite.T()
+	testAPI := tdhttp.NewTestAPI(t, TestContext.server)
+
+	testAPI.PostJSON(
+		"/api/v1/namespaces",
+		gin.H{"prefix": "eng", "owner_id": "eng-team"},
+		"Authorization", TestContext.CreateApiKey("eng-team", false),
+	).
+		CmpStatus(http.StatusForbidden).
+		CmpJSONBody(
+			td.JSON(`{"errors": [{"field": "Authorization", "reason": "must be an admin api key"}]}`),
+		)
+
+	suite.createNamespace(testAPI, "eng", "eng-team")
+
+	testAPI.PostJSON(
+		"/api/v1/namespaces",
+		gin.H{"prefix": "eng", "owner_id": "someone-else"},
+		"Authorization", TestContext.CreateApiKey("admin", true),
+	).
+		CmpStatus(http.StatusConflict).
+		CmpJSONBody(
+			td.JSON(`{"errors": [{"field": "Prefix", "reason": "must be unique"}]}`),
+		)
+}
+
+func (suite *namespacesSuite) TestReservedPrefixReturns400() {
+	t := suite.T()
+	testAPI := tdhttp.NewTestAPI(t, TestContext.server)
+
+	testAPI.PostJSON(
+		"/api/v1/namespaces",
+		gin.H{"prefix": "api/v2", "owner_id": "eng-team"},
+		"Authorization", TestContext.CreateApiKey("admin", true),
+	).
+		CmpStatus(http.StatusBadRequest).
+		CmpJSONBody(
+			td.JSON(`{"errors": [{"field": "Prefix", "reason": "is reserved"}]}`),
+		)
+}
+
+func (suite *namespacesSuite) TestOnlyOwnerCanCreateShortUrlsInNamespace() {
+	t := suite.T()
+	testAPI := tdhttp.NewTestAPI(t, TestContext.server)
+
+	suite.createNamespace(testAPI, "eng", "eng-team")
+
+	testAPI.PostJSON(
+		"/api/v1/shorturls",
+		gin.H{"slug": "eng/oncall", "long_url": "https://oncall.example.com"},
+		"Authorization", TestContext.CreateApiKey("sales-team", false),
+	).
+		CmpStatus(http.StatusForbidden).
+		CmpJSONBody(
+			td.JSON(`{"errors": [{"field": "Authorization", "reason": "not allowed to use this namespace"}]}`),
+		)
+
+	// The namespace's prefix is its own too.
+	testAPI.PostJSON(
+		"/api/v1/shorturls",
+		gin.H{"slug": "eng", "long_url": "https://eng.example.com"},
+	).
+		CmpStatus(http.StatusForbidden)
+
+	testAPI.PostJSON(
+		"/api/v1/shorturls",
+		gin.H{"slug": "eng/oncall", "long_url": "https://oncall.example.com"},
+		"Authorization", TestContext.CreateApiKey("eng-team", false),
+	).
+		CmpStatus(http.StatusCreated).
+		CmpJSONBody(
+			td.SuperJSONOf(`{"short_url": "http://example.com/eng/oncall", "slug": "eng/oncall"}`),
+		)
+}
+
+func (suite *namespacesSuite) TestSlugOutsideNamespaceReturns400() {
+	t := suite.T()
+	testAPI := tdhttp.NewTestAPI(t, TestContext.server)
+
+	testAPI.PostJSON(
+		"/api/v1/shorturls",
+		gin.H{"slug": "eng/oncall", "long_url": "https://oncall.example.com"},
+	).
+		CmpStatus(http.StatusBadRequest).
+		CmpJSONBody(
+			td.JSON(`{"errors": [{"field": "Slug", "reason": "must be in a namespace to contain a slash"}]}`),
+		)
+
+	testAPI.PostJSON(
+		"/api/v1/shorturls",
+		gin.H{"slug": "eng/../oncall", "long_url": "https://oncall.example.com"},
+	).
+		CmpStatus(http.StatusBadRequest).
+		CmpJSONBody(
+			td.JSON(`{"errors": [{"field": "Slug", "reason": "must have at most 5 segments, none of them empty, . or .."}]}`),
+		)
+}
+
+func (suite *namespacesSuite) TestHierarchicalSlugsResolveAndUseEscapedApiPaths() {
+	t := suite.T()
+	testAPI := tdhttp.NewTestAPI(t, TestContext.server)
+
+	suite.createNamespace(testAPI, "eng", "eng-team")
+	engTeam := TestContext.CreateApiKey("eng-team", false)
+
+	testAPI.PostJSON(
+		"/api/v1/shorturls",
+		gin.H{"slug": "eng/oncall", "long_url": "https://oncall.example.com"},
+		"Authorization", engTeam,
+	).
+		CmpStatus(http.StatusCreated)
+
+	testAPI.Get("/eng/oncall").
+		CmpStatus(http.StatusMovedPermanently).
+		CmpHeader(td.SuperMapOf(http.Header{"Location": {"https://oncall.example.com"}}, nil))
+
+	testAPI.Get("/eng/oncall/runbook").
+		CmpStatus(http.StatusNotFound)
+
+	testAPI.PatchJSON(
+		"/api/v1/shorturls/eng%2Foncall",
+		gin.H{"long_url": "https://pager.example.com"},
+		"Authorization", engTeam,
+	).
+		CmpStatus(http.StatusOK).
+		CmpJSONBody(
+			td.SuperJSONOf(`{"slug": "eng/oncall", "long_url": "https://pager.example.com"}`),
+		)
+
+	testAPI.Get("/api/v1/shorturls?namespace=eng").
+		CmpStatus(http.StatusOK).
+		CmpJSONBody(
+			td.SuperJSONOf(`{"short_urls": [SuperMapOf({"slug": "eng/oncall"})]}`),
+		)
+}
+
+func (suite *namespacesSuite) TestOwnerCanHandOverAndDeleteNamespace() {
+	t := suite.T()
+	testAPI := tdhttp.NewTestAPI(t, TestContext.server)
+
+	suite.createNamespace(testAPI, "eng/sre", "sre-team")
+
+	testAPI.PatchJSON(
+		"/api/v1/namespaces/eng%2Fsre",
+		gin.H{"owner_id": "platform-team"},
+		"Authorization", TestContext.CreateApiKey("platform-team", false),
+	).
+		CmpStatus(http.StatusForbidden).
+		CmpJSONBody(
+			td.JSON(`{"errors": [{"field": "Authorization", "reason": "not allowed to modify this namespace"}]}`),
+		)
+
+	testAPI.PatchJSON(
+		"/api/v1/namespaces/eng%2Fsre",
+		gin.H{"owner_id": "platform-team"},
+		"Authorization", TestContext.CreateApiKey("sre-team", false),
+	).
+		CmpStatus(http.StatusOK).
+		CmpJSONBody(
+			td.SuperJSONOf(`{"prefix": "eng/sre", "owner_id": "platform-team"}`),
+		)
+
+	testAPI.Get("/api/v1/namespaces").
+		CmpStatus(http.StatusOK).
+		CmpJSONBody(
+			td.JSON(`{"namespaces": [{"prefix": "eng/sre", "owner_id": "platform-team", "created_at": $1}]}`, td.Ignore()),
+		)
+
+	testAPI.Delete("/api/v1/namespaces/eng%2Fsre", nil, "Authorization", TestContext.CreateApiKey("platform-team", false)).
+		CmpStatus(http.StatusNoContent)
+
+	testAPI.Delete("/api/v1/namespaces/eng%2Fsre", nil, "Authorization", TestContext.CreateApiKey("platform-team", false)).
+		CmpStatus(http.StatusNotFound)
+}
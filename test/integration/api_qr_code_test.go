@@ -0,0 +1,102 @@
+package integration
+
+import (
+	"bytes"
+	"image/png"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/maxatome/go-testdeep/helpers/tdhttp"
+	"github.com/maxatome/go-testdeep/td"
+	"github.com/stretchr/testify/suite"
+)
+
+type qrCodeSuite struct {
+	suite.Suite
+}
+
+func TestQrCode(t *testing.T) {
+	suite.Run(t, new(qrCodeSuite))
+}
+
+func (suite *qrCodeSuite) BeforeTest(suiteName, testName string) {
+	TestContext.BeforeTest()
+}
+
+func (suite *qrCodeSuite) TestGetQrCodeWithValidSlugReturns200() {
+	t := suite.T()
+	testAPI := tdhttp.NewTestAPI(t, TestContext.server)
+
+	testAPI.PostJSON("/api/v1/shorturls", gin.H{"long_url": "https://www.cloudflare.com", "slug": "cloudflare"}).
+		CmpStatus(http.StatusCreated)
+
+	testAPI.Get("/api/v1/shorturls/cloudflare/qr?size=300").
+		CmpStatus(http.StatusOK).
+		CmpHeader(td.SuperMapOf(http.Header{"Content-Type": {"image/png"}}, nil)).
+		CmpBody(td.Smuggle(pngWidth, 300))
+
+	testAPI.Get("/api/v1/shorturls/cloudflare/qr?format=svg&ecc=H&margin=0&utm_source=qr").
+		CmpStatus(http.StatusOK).
+		CmpHeader(td.SuperMapOf(http.Header{"Content-Type": {"image/svg+xml"}}, nil)).
+		CmpBody(td.Re(`^<svg xmlns="http://www.w3.org/2000/svg" width="256" height="256" `))
+
+	testAPI.Get("/api/v1/shorturls/unknown/qr").
+		CmpStatus(http.StatusNotFound)
+}
+
+func pngWidth(body []byte) (int, error) {
+	img, err := png.Decode(bytes.NewReader(body))
+
+	if err != nil {
+		return 0, err
+	}
+
+	return img.Bounds().Dx(), nil
+}
+
+func (suite *qrCodeSuite) TestGetQrCodeWithInvalidOptionsReturns400() {
+	t := suite.T()
+	testAPI := tdhttp.NewTestAPI(t, TestContext.server)
+
+	testAPI.PostJSON("/api/v1/shorturls", gin.H{"long_url": "https://www.cloudflare.com", "slug": "cloudflare"}).
+		CmpStatus(http.StatusCreated)
+
+	for _, query := range []string{"format=gif", "ecc=X", "size=10", "size=4096", "margin=-1", "margin=20"} {
+		testAPI.Get("/api/v1/shorturls/cloudflare/qr?" + query).
+			CmpStatus(http.StatusBadRequest)
+	}
+}
+
+func (suite *qrCodeSuite) TestScansAreCountedBySource() {
+	t := suite.T()
+	testAPI := tdhttp.NewTestAPI(t, TestContext.server)
+
+	testAPI.PostJSON("/api/v1/shorturls", gin.H{
+		"long_url":   "https://www.cloudflare.com",
+		"slug":       "cloudflare",
+		"utm_source": "newsletter",
+	}).
+		CmpStatus(http.StatusCreated)
+
+	// What the QR code encodes with utm_source=qr.
+	for _, path := range []string{"/cloudflare?utm_source=qr", "/cloudflare?utm_source=qr", "/cloudflare"} {
+		testAPI.Get(path).
+			CmpStatus(http.StatusMovedPermanently).
+			CmpHeader(td.SuperMapOf(http.Header{"Location": {"https://www.cloudflare.com?utm_source=newsletter"}}, nil))
+	}
+
+	testAPI.Get("/api/v1/shorturls/cloudflare/clicks/sources?time_period=ALL_TIME").
+		CmpStatus(http.StatusOK).
+		CmpJSONBody(
+			td.JSON(
+				`{
+				   "time_period": "ALL_TIME",
+				   "breakdown": [
+					   {"value": "qr", "count": 2},
+					   {"value": "newsletter", "count": 1}
+				   ]
+				 }`,
+			),
+		)
+}
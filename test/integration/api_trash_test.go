@@ -0,0 +1,124 @@
+package integration
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/maxatome/go-testdeep/helpers/tdhttp"
+	"github.com/maxatome/go-testdeep/td"
+	"github.com/stretchr/testify/suite"
+)
+
+type trashSuite struct {
+	suite.Suite
+}
+
+func TestTrash(t *testing.T) {
+	suite.Run(t, new(trashSuite))
+}
+
+func (suite *trashSuite) BeforeTest(suiteName, testName string) {
+	TestContext.BeforeTest()
+}
+
+func (suite *trashSuite) TestDeletedShortUrlCanBeRestored() {
+	t := suite.T()
+	testAPI := tdhttp.NewTestAPI(t, TestContext.server)
+
+	admin := TestContext.CreateApiKey("admins", true)
+
+	testAPI.PostJSON("/api/v1/shorturls", gin.H{"long_url": "https://www.google.com", "slug": "trashed"}).
+		CmpStatus(http.StatusCreated)
+
+	testAPI.Get("/trashed").
+		CmpStatus(http.StatusMovedPermanently)
+
+	testAPI.Delete("/api/v1/shorturls/trashed", nil, "Authorization", admin).
+		CmpStatus(http.StatusNoContent)
+
+	testAPI.Get("/trashed").
+		CmpStatus(http.StatusNotFound)
+
+	testAPI.Get("/api/v1/shorturls/trashed").
+		CmpStatus(http.StatusNotFound)
+
+	testAPI.Get("/api/v1/shorturls").
+		CmpStatus(http.StatusOK).
+		CmpJSONBody(td.JSON(`{"short_urls": [], "next_cursor": null}`))
+
+	testAPI.Get("/api/v1/shorturls/trash").
+		CmpStatus(http.StatusOK).
+		CmpJSONBody(
+			td.JSON(
+				`{
+				   "short_urls": [
+					   SuperMapOf({"slug": "trashed", "status": "deleted", "deleted_at": NotEmpty()})
+					 ],
+					 "next_cursor": null
+				 }`,
+			),
+		)
+
+	testAPI.PostJSON("/api/v1/shorturls", gin.H{"long_url": "https://www.bing.com", "slug": "trashed"}).
+		CmpStatus(http.StatusConflict)
+
+	testAPI.Post("/api/v1/shorturls/trashed/restore", nil, "Authorization", admin).
+		CmpStatus(http.StatusOK).
+		CmpJSONBody(td.SuperJSONOf(`{"slug": "trashed", "status": "active"}`))
+
+	testAPI.Get("/trashed").
+		CmpStatus(http.StatusMovedPermanently)
+
+	testAPI.Get("/api/v1/shorturls/trashed/clicks?time_period=ALL_TIME").
+		CmpStatus(http.StatusOK).
+		CmpJSONBody(td.JSON(`{"count": 2, "time_period": "ALL_TIME"}`))
+
+	testAPI.Post("/api/v1/shorturls/trashed/restore", nil, "Authorization", admin).
+		CmpStatus(http.StatusNotFound).
+		CmpJSONBody(
+			td.JSON(`{"errors": [{"field": "Slug", "reason": "not found in the trash"}]}`),
+		)
+}
+
+func (suite *trashSuite) TestRestoreAfterLongUrlIsShortenedAgainReturns409() {
+	t := suite.T()
+	testAPI := tdhttp.NewTestAPI(t, TestContext.server)
+
+	admin := TestContext.CreateApiKey("admins", true)
+
+	testAPI.PostJSON("/api/v1/shorturls", gin.H{"long_url": "https://www.google.com", "slug": "first"}).
+		CmpStatus(http.StatusCreated)
+
+	testAPI.Delete("/api/v1/shorturls/first", nil, "Authorization", admin).
+		CmpStatus(http.StatusNoContent)
+
+	testAPI.PostJSON("/api/v1/shorturls", gin.H{"long_url": "https://www.google.com", "slug": "second"}).
+		CmpStatus(http.StatusCreated)
+
+	testAPI.Post("/api/v1/shorturls/first/restore", nil, "Authorization", admin).
+		CmpStatus(http.StatusConflict).
+		CmpJSONBody(
+			td.JSON(`{"errors": [{"field": "LongUrl", "reason": "has been shortened again since this short url was deleted"}]}`),
+		)
+}
+
+func (suite *trashSuite) TestOnlyOwnerCanRestoreShortUrl() {
+	t := suite.T()
+	testAPI := tdhttp.NewTestAPI(t, TestContext.server)
+
+	alice := TestContext.CreateApiKey("alice", false)
+	bob := TestContext.CreateApiKey("bob", false)
+
+	testAPI.PostJSON("/api/v1/shorturls", gin.H{"long_url": "https://www.google.com", "slug": "owned"}, "Authorization", alice).
+		CmpStatus(http.StatusCreated)
+
+	testAPI.Delete("/api/v1/shorturls/owned", nil, "Authorization", alice).
+		CmpStatus(http.StatusNoContent)
+
+	testAPI.Post("/api/v1/shorturls/owned/restore", nil, "Authorization", bob).
+		CmpStatus(http.StatusForbidden)
+
+	testAPI.Post("/api/v1/shorturls/owned/restore", nil, "Authorization", alice).
+		CmpStatus(http.StatusOK)
+}
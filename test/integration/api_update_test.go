@@ -0,0 +1,214 @@
+package integration
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/maxatome/go-testdeep/helpers/tdhttp"
+	"github.com/maxatome/go-testdeep/td"
+	"github.com/stretchr/testify/suite"
+)
+
+type updateSuite struct {
+	suite.Suite
+}
+
+func TestUpdate(t *testing.T) {
+	suite.Run(t, new(updateSuite))
+}
+
+func (suite *updateSuite) BeforeTest(suiteName, testName string) {
+	TestContext.BeforeTest()
+}
+
+func (suite *updateSuite) TestUpdateLongUrlReturns200() {
+	t := suite.T()
+
+	testServer := TestContext.server
+	testAPI := tdhttp.NewTestAPI(t, testServer)
+
+	admin := TestContext.CreateApiKey("admins", true)
+
+	testAPI.PostJSON("/api/v1/shorturls", gin.H{"long_url": "https://www.cloudflare.com", "slug": "cf"}).
+		CmpStatus(http.StatusCreated)
+
+	testAPI.PatchJSON("/api/v1/shorturls/cf", gin.H{"long_url": "https://blog.cloudflare.com"}, "Authorization", admin).
+		CmpStatus(http.StatusOK).
+		CmpJSONBody(
+			td.SuperJSONOf(
+				`{"slug": "cf", "long_url": "https://blog.cloudflare.com", "expires_on": null}`,
+			),
+		)
+
+	testAPI.Get("/cf").
+		CmpStatus(http.StatusMovedPermanently).
+		CmpHeader(td.SuperMapOf(http.Header{
+			"Location": []string{"https://blog.cloudflare.com"},
+		}, nil))
+}
+
+func (suite *updateSuite) TestUpdateSlugKeepsClicks() {
+	t := suite.T()
+
+	testServer := TestContext.server
+	testAPI := tdhttp.NewTestAPI(t, testServer)
+
+	admin := TestContext.CreateApiKey("admins", true)
+
+	testAPI.PostJSON("/api/v1/shorturls", gin.H{"long_url": "https://www.cloudflare.com", "slug": "cf"}).
+		CmpStatus(http.StatusCreated)
+
+	testAPI.Get("/cf").CmpStatus(http.StatusMovedPermanently)
+
+	testAPI.PatchJSON("/api/v1/shorturls/cf", gin.H{"slug": "cloudflare"}, "Authorization", admin).
+		CmpStatus(http.StatusOK).
+		CmpJSONBody(
+			td.SuperJSONOf(`{"slug": "cloudflare", "short_url": "http://example.com/cloudflare"}`),
+		)
+
+	testAPI.Get("/cf").CmpStatus(http.StatusNotFound)
+	testAPI.Get("/cloudflare").CmpStatus(http.StatusMovedPermanently)
+
+	testAPI.Get("/api/v1/shorturls/cloudflare/clicks?time_period=ALL_TIME").
+		CmpStatus(http.StatusOK).
+		CmpJSONBody(td.JSON(`{"count": 2, "time_period": "ALL_TIME"}`))
+}
+
+func (suite *updateSuite) TestUpdateExpiresOnReturns200() {
+	t := suite.T()
+
+	testServer := TestContext.server
+	testAPI := tdhttp.NewTestAPI(t, testServer)
+
+	admin := TestContext.CreateApiKey("admins", true)
+
+	expirationDateTime := time.Now().Add(time.Hour * 24 * 365).UTC().Truncate(time.Second)
+
+	testAPI.PostJSON("/api/v1/shorturls", gin.H{"long_url": "https://www.cloudflare.com", "slug": "cf"}).
+		CmpStatus(http.StatusCreated)
+
+	testAPI.PatchJSON("/api/v1/shorturls/cf", gin.H{"expires_on": expirationDateTime.Format(time.RFC3339)}, "Authorization", admin).
+		CmpStatus(http.StatusOK).
+		CmpJSONBody(
+			td.SuperJSONOf(
+				`{"long_url": "https://www.cloudflare.com", "expires_on": "$expiresOn"}`,
+				td.Tag("expiresOn", td.Smuggle(parseDateTime, expirationDateTime)),
+			),
+		)
+
+	testAPI.PatchJSON("/api/v1/shorturls/cf", gin.H{"expires_on": nil}, "Authorization", admin).
+		CmpStatus(http.StatusOK).
+		CmpJSONBody(td.SuperJSONOf(`{"expires_on": null}`))
+}
+
+func (suite *updateSuite) TestUpdateWithExistingSlugReturns409() {
+	t := suite.T()
+
+	testServer := TestContext.server
+	testAPI := tdhttp.NewTestAPI(t, testServer)
+
+	admin := TestContext.CreateApiKey("admins", true)
+
+	testAPI.PostJSON("/api/v1/shorturls", gin.H{"long_url": "https://www.cloudflare.com", "slug": "cf"}).
+		CmpStatus(http.StatusCreated)
+
+	testAPI.PostJSON("/api/v1/shorturls", gin.H{"long_url": "https://www.stackoverflow.com", "slug": "so"}).
+		CmpStatus(http.StatusCreated)
+
+	testAPI.PatchJSON("/api/v1/shorturls/so", gin.H{"slug": "cf"}, "Authorization", admin).
+		CmpStatus(http.StatusConflict).
+		CmpJSONBody(
+			td.JSON(
+				`{
+				   "errors": [
+					   {
+						   "field": "Slug",
+							 "reason": "must be unique"
+						 }
+					 ]
+				 }`,
+			),
+		)
+}
+
+func (suite *updateSuite) TestUpdateWithExistingLongUrlReturns409() {
+	t := suite.T()
+
+	testServer := TestContext.server
+	testAPI := tdhttp.NewTestAPI(t, testServer)
+
+	admin := TestContext.CreateApiKey("admins", true)
+
+	testAPI.PostJSON("/api/v1/shorturls", gin.H{"long_url": "https://www.cloudflare.com", "slug": "cf"}).
+		CmpStatus(http.StatusCreated)
+
+	testAPI.PostJSON("/api/v1/shorturls", gin.H{"long_url": "https://www.stackoverflow.com", "slug": "so"}).
+		CmpStatus(http.StatusCreated)
+
+	testAPI.PatchJSON("/api/v1/shorturls/so", gin.H{"long_url": "https://www.cloudflare.com"}, "Authorization", admin).
+		CmpStatus(http.StatusConflict).
+		CmpJSONBody(
+			td.JSON(
+				`{
+				   "errors": [
+					   {
+						   "field": "LongUrl",
+							 "reason": "must be unique"
+						 }
+					 ]
+				 }`,
+			),
+		)
+}
+
+func (suite *updateSuite) TestUpdateWithInvalidSchemeReturns400() {
+	t := suite.T()
+
+	testServer := TestContext.server
+	testAPI := tdhttp.NewTestAPI(t, testServer)
+
+	admin := TestContext.CreateApiKey("admins", true)
+
+	testAPI.PostJSON("/api/v1/shorturls", gin.H{"long_url": "https://www.cloudflare.com", "slug": "cf"}).
+		CmpStatus(http.StatusCreated)
+
+	testAPI.PatchJSON("/api/v1/shorturls/cf", gin.H{"long_url": "javascript:alert('hi')"}, "Authorization", admin).
+		CmpStatus(http.StatusBadRequest).
+		CmpJSONBody(
+			td.JSON(
+				`{
+				   "errors": [
+					   {
+						   "field": "LongUrl",
+							 "reason": "only http and https are supported"
+						 }
+					 ]
+				 }`,
+			),
+		)
+}
+
+func (suite *updateSuite) TestUpdateWithInvalidSlugReturns404() {
+	t := suite.T()
+
+	testServer := TestContext.server
+	testAPI := tdhttp.NewTestAPI(t, testServer)
+
+	testAPI.PatchJSON(fmt.Sprintf("/api/v1/shorturls/%s", "invalid"), gin.H{"slug": "valid"}).
+		CmpStatus(http.StatusNotFound).
+		CmpJSONBody(
+			td.JSON(
+				`{
+				   "errors": [
+					   {
+						   "field": "Slug",
+							 "reason": "not found"
+						 }
+					 ]
+				 }`,
+			),
+		)
+}
@@ -8,8 +8,13 @@ import (
 	"os"
 	"testing"
 	"time"
+	"url-shortener/cache"
 	"url-shortener/db"
+	"url-shortener/env"
+	"url-shortener/models"
+	"url-shortener/repositories"
 	"url-shortener/server"
+	"url-shortener/services"
 	"url-shortener/test/helpers"
 
 	"github.com/gin-gonic/gin"
@@ -20,11 +25,19 @@ var TestContext *ApiTestContext
 func TestMain(m *testing.M) {
 	flag.Parse()
 
-	if testing.Short() {
+	memory := os.Getenv(env.Storage) == "memory"
+
+	// Without Docker, the tests can still run against the in-memory store.
+	if testing.Short() && !memory {
 		return
 	}
 
-	TestContext = BuildTestContext()
+	if memory {
+		TestContext = BuildMemoryTestContext()
+	} else {
+		TestContext = BuildTestContext()
+	}
+
 	defer TestContext.cleanup()
 
 	os.Exit(m.Run())
@@ -45,13 +58,11 @@ func BuildTestContext() *ApiTestContext {
 		log.Fatal(err)
 	}
 
-	server := server.SetupServer(
-		&server.ServerConfig{DB: gormDB},
-	)
+	testContext := &ApiTestContext{}
+	testContext.setStore(repositories.NewPostgresStore(gormDB))
 
-	testContext := &ApiTestContext{
-		db:     sqlDB,
-		server: server,
+	testContext.reset = func() {
+		truncateTables(sqlDB)
 	}
 
 	testContext.cleanup = func() {
@@ -62,21 +73,72 @@ func BuildTestContext() *ApiTestContext {
 	return testContext
 }
 
+// BuildMemoryTestContext runs the tests against the in-memory store, which is
+// replaced by an empty one before each test.
+func BuildMemoryTestContext() *ApiTestContext {
+	testContext := &ApiTestContext{}
+	testContext.setStore(repositories.NewMemoryStore())
+
+	testContext.reset = func() {
+		testContext.setStore(repositories.NewMemoryStore())
+	}
+
+	testContext.cleanup = func() {}
+
+	return testContext
+}
+
 type ApiTestContext struct {
-	db      *sql.DB
+	store   repositories.Store
 	server  *gin.Engine
+	reset   func()
 	cleanup func()
 }
 
+// setStore serves store with a slug cache, which the tests must never notice.
+func (ctx *ApiTestContext) setStore(store repositories.Store) {
+	ctx.store = store
+	ctx.server = server.SetupServer(&server.ServerConfig{
+		Store:     store,
+		SlugCache: cache.NewLru(100),
+		SlugCacheConfig: services.SlugCacheConfig{
+			Ttl:        time.Hour,
+			MissingTtl: time.Hour,
+		},
+	})
+}
+
 func (ctx *ApiTestContext) BeforeTest() {
-	db := ctx.db
+	ctx.reset()
+}
 
+func truncateTables(db *sql.DB) {
 	_, err := db.Exec("TRUNCATE TABLE short_urls CASCADE")
 
 	if err != nil {
 		log.Fatal("Failed to truncate short_urls table:", err)
 	}
 
+	_, err = db.Exec("TRUNCATE TABLE api_keys")
+
+	if err != nil {
+		log.Fatal("Failed to truncate api_keys table:", err)
+	}
+
+	_, err = db.Exec("TRUNCATE TABLE namespaces")
+
+	if err != nil {
+		log.Fatal("Failed to truncate namespaces table:", err)
+	}
+
+	// Audit events outlive short URLs, so they aren't truncated along with
+	// them.
+	_, err = db.Exec("TRUNCATE TABLE audit_events RESTART IDENTITY")
+
+	if err != nil {
+		log.Fatal("Failed to truncate audit_events table:", err)
+	}
+
 	_, err = db.Exec("ALTER SEQUENCE short_urls_id_seq RESTART")
 
 	if err != nil {
@@ -89,3 +151,21 @@ func parseDateTime(date string) (time.Time, error) {
 
 	return t, err
 }
+
+// CreateApiKey creates an API key directly in the store and returns the
+// Authorization header value for it.
+func (ctx *ApiTestContext) CreateApiKey(ownerId string, admin bool) string {
+	apiKeyService := services.ApiKeyService{Store: ctx.store}
+
+	result := apiKeyService.Create(models.ApiKeyCreateFields{
+		Name:    "test",
+		OwnerId: ownerId,
+		Admin:   admin,
+	})
+
+	if result.Error != nil {
+		log.Fatal("Failed to create api key:", result.Error)
+	}
+
+	return "Bearer " + result.Key
+}